@@ -0,0 +1,369 @@
+// Package dkg implements a distributed key generation for threshold BBS+ keys, so that no party ever learns the
+// secret key sk.
+//
+// The protocol is Feldman's verifiable secret sharing run by every party in parallel, with complaints:
+//  1. Every party i samples a polynomial f_i of degree t-1, broadcasts the commitments g_2^(a_ik) to its
+//     coefficients and sends the share f_i(x_j) to every party j.
+//  2. Every party checks its shares against the commitments and broadcasts complaints about dealers whose share is
+//     missing or invalid.
+//  3. Dealers answer every complaint by broadcasting the disputed share. Dealers without valid commitments or with a
+//     missing or invalid answer are disqualified.
+//
+// The secret key is sk = sum_i f_i(0) over the qualified dealers QUAL. Party j holds the Shamir share
+// sk_j = sum_i f_i(x_j) and everyone learns W = g_2^sk as well as the public shares g_2^(sk_j). The positions
+// x_j = helper.ShamirPositionFr(j) are those of helper.ShamirSharedSecretKey, so that the shares can be checked with
+// PartySecretKey.VerifyShare against the commitments in the Result.
+// As with every Feldman-based DKG, a rushing adversary can bias the distribution of the public key, which does not
+// affect the unforgeability of BBS+ signatures.
+//
+// Party.Refresh runs the same protocol with sharings of zero to proactively re-randomize the shares of an existing key.
+package dkg
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+
+	bls12381 "github.com/kilic/bls12-381"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
+)
+
+// Party runs the DKG for one participant.
+type Party struct {
+	index     int // 1-based index of the party, the position its share is evaluated at.
+	threshold int // Number of shares required to reconstruct sk.
+	n         int // Number of parties.
+	transport Transport
+}
+
+// Result is the output of the DKG for one party.
+type Result struct {
+	SecretKey    *fhks_bbs_plus.PartySecretKey  // Share sk_i of the party along with W = g_2^sk.
+	Commitments  fhks_bbs_plus.ShareCommitments // Feldman commitments to the polynomial sharing sk.
+	PublicShares []*bls12381.PointG2            // PublicShares[j-1] = g_2^(sk_j) for every party j.
+	Qualified    []int                          // Indices of the dealers whose polynomials make up sk, ascending.
+}
+
+// NewParty returns the party index of a threshold-out-of-n DKG communicating via transport.
+func NewParty(index, threshold, n int, transport Transport) (*Party, error) {
+	if threshold < 1 || threshold > n {
+		return nil, fmt.Errorf("invalid threshold %d for %d parties", threshold, n)
+	}
+	if index < 1 || index > n {
+		return nil, fmt.Errorf("invalid index %d for %d parties", index, n)
+	}
+	return &Party{index: index, threshold: threshold, n: n, transport: transport}, nil
+}
+
+// Run executes the three rounds of the DKG.
+func (p *Party) Run() (*Result, error) {
+	out, err := p.share(false, 0)
+	if err != nil {
+		return nil, err
+	}
+	return p.result(out.share, out.commitments, 0, out.qualified)
+}
+
+// sharing is the outcome of the joint verifiable secret sharing.
+type sharing struct {
+	share       *bls12381.Fr        // Sum of the shares of the qualified dealers for this party.
+	commitments []*bls12381.PointG2 // Sum of the commitments of the qualified dealers.
+	qualified   []int
+}
+
+// share runs the three rounds of the joint verifiable secret sharing, in which every party deals a random secret or,
+// if zeroSecret is set, a sharing of zero. The commitments are tagged with the epoch of the resulting key, dealers of
+// other epochs are disqualified.
+func (p *Party) share(zeroSecret bool, epoch uint64) (*sharing, error) {
+	g2 := bls12381.NewG2()
+
+	// Round 1: deal shares of a random polynomial.
+	coefficients := make([]*bls12381.Fr, p.threshold)
+	for k := range coefficients {
+		coefficients[k] = bls12381.NewFr()
+		if k == 0 && zeroSecret {
+			coefficients[k].Zero()
+		} else if _, err := coefficients[k].Rand(rand.Reader); err != nil {
+			return nil, fmt.Errorf("sample coefficient: %w", err)
+		}
+	}
+	ownCommitments := make([]*bls12381.PointG2, p.threshold)
+	for k, coefficient := range coefficients {
+		ownCommitments[k] = g2.New()
+		g2.MulScalar(ownCommitments[k], g2.One(), coefficient)
+	}
+	for j := 1; j <= p.n; j++ {
+		if j != p.index {
+			if err := p.transport.Send(j, RoundDeal, evaluatePolynomial(coefficients, j).ToBytes()); err != nil {
+				return nil, fmt.Errorf("send share to party %d: %w", j, err)
+			}
+		}
+	}
+	if err := p.transport.Broadcast(RoundDeal, encodeCommitments(epoch, ownCommitments)); err != nil {
+		return nil, fmt.Errorf("broadcast commitments: %w", err)
+	}
+
+	messages, err := p.transport.Receive(RoundDeal)
+	if err != nil {
+		return nil, fmt.Errorf("receive shares: %w", err)
+	}
+	commitments := map[int][]*bls12381.PointG2{p.index: ownCommitments}
+	shares := map[int]*bls12381.Fr{p.index: evaluatePolynomial(coefficients, p.index)}
+	for _, msg := range messages {
+		if msg.Broadcast {
+			if c, err := decodeCommitments(msg.Payload, epoch, p.threshold); err == nil && (!zeroSecret || g2.IsZero(c[0])) {
+				commitments[msg.From] = c
+			}
+		} else if share, err := fhks_bbs_plus.ScalarFromBytes(msg.Payload); err == nil {
+			shares[msg.From] = share
+		}
+	}
+
+	disqualified := make(map[int]bool)
+	for i := 1; i <= p.n; i++ {
+		if _, ok := commitments[i]; !ok {
+			disqualified[i] = true
+		}
+	}
+
+	// Round 2: complain about missing or invalid shares.
+	var ownComplaints []int
+	for i := 1; i <= p.n; i++ {
+		if disqualified[i] || i == p.index {
+			continue
+		}
+		if share, ok := shares[i]; !ok || !verifyShare(commitments[i], p.index, share) {
+			ownComplaints = append(ownComplaints, i)
+			delete(shares, i)
+		}
+	}
+	if err := p.transport.Broadcast(RoundComplaint, encodeIndices(ownComplaints)); err != nil {
+		return nil, fmt.Errorf("broadcast complaints: %w", err)
+	}
+
+	messages, err = p.transport.Receive(RoundComplaint)
+	if err != nil {
+		return nil, fmt.Errorf("receive complaints: %w", err)
+	}
+	complaints := map[int][]int{p.index: ownComplaints} // complaints[j] are the dealers party j complains about.
+	for _, msg := range messages {
+		if !msg.Broadcast {
+			continue
+		}
+		if indices, err := decodeIndices(msg.Payload); err == nil {
+			complaints[msg.From] = indices
+		}
+	}
+
+	// Round 3: answer complaints by revealing the disputed shares.
+	ownJustification := make(map[int]*bls12381.Fr)
+	for j, dealers := range complaints {
+		for _, dealer := range dealers {
+			if dealer == p.index {
+				ownJustification[j] = evaluatePolynomial(coefficients, j)
+			}
+		}
+	}
+	if err := p.transport.Broadcast(RoundJustification, encodeJustification(ownJustification)); err != nil {
+		return nil, fmt.Errorf("broadcast justification: %w", err)
+	}
+
+	messages, err = p.transport.Receive(RoundJustification)
+	if err != nil {
+		return nil, fmt.Errorf("receive justifications: %w", err)
+	}
+	justifications := map[int]map[int]*bls12381.Fr{p.index: ownJustification}
+	for _, msg := range messages {
+		if !msg.Broadcast {
+			continue
+		}
+		if justification, err := decodeJustification(msg.Payload); err == nil {
+			justifications[msg.From] = justification
+		}
+	}
+
+	resolveComplaints(p.index, complaints, justifications, commitments, shares, disqualified)
+
+	// Combine the contributions of the qualified dealers.
+	var qualified []int
+	for i := 1; i <= p.n; i++ {
+		if !disqualified[i] {
+			qualified = append(qualified, i)
+		}
+	}
+	if len(qualified) == 0 {
+		return nil, errors.New("all dealers are disqualified")
+	}
+
+	skShare := bls12381.NewFr().Zero()
+	combined := make([]*bls12381.PointG2, p.threshold) // Commitments to the coefficients of sum_i f_i.
+	for k := range combined {
+		combined[k] = g2.Zero()
+	}
+	for _, i := range qualified {
+		skShare.Add(skShare, shares[i])
+		for k, c := range commitments[i] {
+			g2.Add(combined[k], combined[k], c)
+		}
+	}
+
+	return &sharing{share: skShare, commitments: combined, qualified: qualified}, nil
+}
+
+// result returns the Result for the secret key share skShare of the sharing committed to by commitments.
+func (p *Party) result(skShare *bls12381.Fr, commitments []*bls12381.PointG2, epoch uint64, qualified []int) (*Result, error) {
+	g2 := bls12381.NewG2()
+
+	publicShares := make([]*bls12381.PointG2, p.n)
+	for j := 1; j <= p.n; j++ {
+		publicShares[j-1] = helper.EvaluateFeldmanCommitments(commitments, j)
+	}
+
+	ownPublicShare := g2.New()
+	g2.MulScalar(ownPublicShare, g2.One(), skShare)
+	if !g2.Equal(ownPublicShare, publicShares[p.index-1]) {
+		return nil, errors.New("secret key share does not match the commitments")
+	}
+
+	return &Result{
+		SecretKey: &fhks_bbs_plus.PartySecretKey{
+			SKeyShare: fhks_bbs_plus.SecretKey{Fr: skShare},
+			PublicKey: commitments[0],
+			Index:     p.index,
+			Epoch:     epoch,
+		},
+		Commitments:  commitments,
+		PublicShares: publicShares,
+		Qualified:    qualified,
+	}, nil
+}
+
+// evaluatePolynomial returns sum_k coefficients[k] * x^k at the Shamir position x of party index.
+func evaluatePolynomial(coefficients []*bls12381.Fr, index int) *bls12381.Fr {
+	xFr := helper.ShamirPositionFr(index)
+	result := bls12381.NewFr().Zero()
+	for k := len(coefficients) - 1; k >= 0; k-- {
+		result.Mul(result, xFr)
+		result.Add(result, coefficients[k])
+	}
+	return result
+}
+
+// resolveComplaints disqualifies every dealer that did not answer a complaint of party j, complaints[j], with a share
+// justifications[dealer][j] matching its commitments. Justified shares for party index replace the ones in shares.
+func resolveComplaints(
+	index int,
+	complaints map[int][]int,
+	justifications map[int]map[int]*bls12381.Fr,
+	commitments map[int][]*bls12381.PointG2,
+	shares map[int]*bls12381.Fr,
+	disqualified map[int]bool) {
+	for j, dealers := range complaints {
+		for _, dealer := range dealers {
+			if _, ok := commitments[dealer]; !ok || disqualified[dealer] {
+				continue
+			}
+			share, ok := justifications[dealer][j]
+			if !ok || !verifyShare(commitments[dealer], j, share) {
+				disqualified[dealer] = true
+				continue
+			}
+			if j == index {
+				shares[dealer] = share
+			}
+		}
+	}
+}
+
+// verifyShare checks that share is the evaluation for party index of the polynomial committed to by commitments.
+func verifyShare(commitments []*bls12381.PointG2, index int, share *bls12381.Fr) bool {
+	g2 := bls12381.NewG2()
+	expected := g2.New()
+	g2.MulScalar(expected, g2.One(), share)
+	return g2.Equal(expected, helper.EvaluateFeldmanCommitments(commitments, index))
+}
+
+func encodeCommitments(epoch uint64, commitments []*bls12381.PointG2) []byte {
+	g2 := bls12381.NewG2()
+	data := binary.LittleEndian.AppendUint64(nil, epoch)
+	for _, c := range commitments {
+		data = append(data, g2.ToCompressed(c)...)
+	}
+	return data
+}
+
+func decodeCommitments(data []byte, epoch uint64, threshold int) ([]*bls12381.PointG2, error) {
+	if len(data) != 8+threshold*helper.LenBytesG2Compressed {
+		return nil, fmt.Errorf("invalid commitments length: expected %d, got %d", 8+threshold*helper.LenBytesG2Compressed, len(data))
+	}
+	if dataEpoch := binary.LittleEndian.Uint64(data); dataEpoch != epoch {
+		return nil, fmt.Errorf("commitments of epoch %d, expected %d", dataEpoch, epoch)
+	}
+	data = data[8:]
+	g2 := bls12381.NewG2()
+	commitments := make([]*bls12381.PointG2, threshold)
+	for k := range commitments {
+		c, err := g2.FromCompressed(data[k*helper.LenBytesG2Compressed : (k+1)*helper.LenBytesG2Compressed])
+		if err != nil {
+			return nil, fmt.Errorf("decode commitment %d: %w", k, err)
+		}
+		commitments[k] = c
+	}
+	return commitments, nil
+}
+
+func encodeIndices(indices []int) []byte {
+	data := make([]byte, 4*len(indices))
+	for i, index := range indices {
+		binary.LittleEndian.PutUint32(data[4*i:], uint32(index))
+	}
+	return data
+}
+
+func decodeIndices(data []byte) ([]int, error) {
+	if len(data)%4 != 0 {
+		return nil, errors.New("invalid indices length")
+	}
+	indices := make([]int, len(data)/4)
+	for i := range indices {
+		indices[i] = int(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return indices, nil
+}
+
+// encodeJustification encodes the revealed shares as (index, share) pairs in ascending order of the index.
+func encodeJustification(justification map[int]*bls12381.Fr) []byte {
+	indices := make([]int, 0, len(justification))
+	for j := range justification {
+		indices = append(indices, j)
+	}
+	sort.Ints(indices)
+
+	var data []byte
+	for _, j := range indices {
+		data = append(data, encodeIndices([]int{j})...)
+		data = append(data, justification[j].ToBytes()...)
+	}
+	return data
+}
+
+func decodeJustification(data []byte) (map[int]*bls12381.Fr, error) {
+	const entryLength = 4 + helper.LenBytesFr
+	if len(data)%entryLength != 0 {
+		return nil, errors.New("invalid justification length")
+	}
+	justification := make(map[int]*bls12381.Fr)
+	for offset := 0; offset < len(data); offset += entryLength {
+		j := int(binary.LittleEndian.Uint32(data[offset:]))
+		share, err := fhks_bbs_plus.ScalarFromBytes(data[offset+4 : offset+entryLength])
+		if err != nil {
+			return nil, fmt.Errorf("decode share for party %d: %w", j, err)
+		}
+		justification[j] = share
+	}
+	return justification, nil
+}
@@ -0,0 +1,181 @@
+package dkg_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/dkg"
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
+)
+
+// corruptingTransport flips a bit in the payloads party sends in the given rounds, directly or by broadcast.
+type corruptingTransport struct {
+	dkg.Transport
+	direct     map[dkg.Round]int // Receiver of the corrupted direct message per round.
+	broadcasts map[dkg.Round]bool
+}
+
+func (ct *corruptingTransport) Broadcast(round dkg.Round, payload []byte) error {
+	if ct.broadcasts[round] && len(payload) > 0 {
+		payload = corrupt(payload)
+	}
+	return ct.Transport.Broadcast(round, payload)
+}
+
+func (ct *corruptingTransport) Send(to int, round dkg.Round, payload []byte) error {
+	if receiver, ok := ct.direct[round]; ok && receiver == to {
+		payload = corrupt(payload)
+	}
+	return ct.Transport.Send(to, round, payload)
+}
+
+func corrupt(payload []byte) []byte {
+	corrupted := append([]byte(nil), payload...)
+	corrupted[len(corrupted)-1] ^= 1
+	return corrupted
+}
+
+// runDKG runs the DKG for all parties in participating and returns their results indexed by party.
+func runDKG(t *testing.T, threshold, n int, timeout time.Duration, participating []int,
+	wrap func(index int, transport dkg.Transport) dkg.Transport) map[int]*dkg.Result {
+	net := dkg.NewMemoryNetwork(n, timeout)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	results := make(map[int]*dkg.Result)
+	for _, index := range participating {
+		transport := net.Transport(index)
+		if wrap != nil {
+			transport = wrap(index, transport)
+		}
+		party, err := dkg.NewParty(index, threshold, n, transport)
+		require.NoError(t, err)
+
+		wg.Add(1)
+		go func(index int, party *dkg.Party) {
+			defer wg.Done()
+			result, err := party.Run()
+			assert.NoError(t, err, "party %d", index)
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[index] = result
+		}(index, party)
+	}
+	wg.Wait()
+	return results
+}
+
+// assertConsistent checks that all parties agree on the output and that the shares of every threshold-subset of
+// them reconstruct the secret key of W.
+func assertConsistent(t *testing.T, threshold int, results map[int]*dkg.Result, qualified []int) {
+	g2 := bls12381.NewG2()
+
+	var reference *dkg.Result
+	var indices []int
+	for index, result := range results {
+		require.NotNil(t, result)
+		assert.Equal(t, qualified, result.Qualified, "party %d", index)
+		if reference == nil {
+			reference = result
+		} else {
+			assert.True(t, g2.Equal(reference.SecretKey.PublicKey, result.SecretKey.PublicKey), "party %d", index)
+			for j, share := range result.PublicShares {
+				assert.True(t, g2.Equal(reference.PublicShares[j], share), "party %d, share %d", index, j+1)
+			}
+		}
+		assert.NoError(t, result.SecretKey.VerifyShare(result.Commitments), "party %d", index)
+		indices = append(indices, index)
+	}
+
+	for start := 0; start+threshold <= len(indices); start++ {
+		subset := indices[start : start+threshold]
+		lagrangeCoefficients := helper.Get0LagrangeCoefficientSetFr(subset)
+		sk := bls12381.NewFr().Zero()
+		tmp := bls12381.NewFr()
+		for k, index := range subset {
+			tmp.Mul(lagrangeCoefficients[k], results[index].SecretKey.SKeyShare.Fr)
+			sk.Add(sk, tmp)
+		}
+		w := g2.New()
+		g2.MulScalar(w, g2.One(), sk)
+		assert.True(t, g2.Equal(w, reference.SecretKey.PublicKey), "subset %v", subset)
+	}
+}
+
+func TestDKGHonest(t *testing.T) {
+	results := runDKG(t, 3, 5, time.Second, []int{1, 2, 3, 4, 5}, nil)
+	require.Len(t, results, 5)
+	assertConsistent(t, 3, results, []int{1, 2, 3, 4, 5})
+}
+
+func TestDKGJustifiedComplaint(t *testing.T) {
+	// Party 2 sends a wrong share to party 4 but reveals the correct one when party 4 complains.
+	results := runDKG(t, 3, 5, time.Second, []int{1, 2, 3, 4, 5},
+		func(index int, transport dkg.Transport) dkg.Transport {
+			if index != 2 {
+				return transport
+			}
+			return &corruptingTransport{Transport: transport, direct: map[dkg.Round]int{dkg.RoundDeal: 4}}
+		})
+	require.Len(t, results, 5)
+	assertConsistent(t, 3, results, []int{1, 2, 3, 4, 5})
+}
+
+func TestDKGDisqualifiesBadDealer(t *testing.T) {
+	// Party 2 sends a wrong share to party 4 and also reveals a wrong share when party 4 complains.
+	results := runDKG(t, 3, 5, time.Second, []int{1, 2, 3, 4, 5},
+		func(index int, transport dkg.Transport) dkg.Transport {
+			if index != 2 {
+				return transport
+			}
+			return &corruptingTransport{
+				Transport:  transport,
+				direct:     map[dkg.Round]int{dkg.RoundDeal: 4},
+				broadcasts: map[dkg.Round]bool{dkg.RoundJustification: true},
+			}
+		})
+	require.Len(t, results, 5)
+
+	// The cheating dealer does not see its own justification corrupted, so only the honest parties agree.
+	delete(results, 2)
+	assertConsistent(t, 3, results, []int{1, 3, 4, 5})
+}
+
+func TestDKGDisqualifiesAbsentParty(t *testing.T) {
+	results := runDKG(t, 2, 4, 200*time.Millisecond, []int{1, 2, 4}, nil)
+	require.Len(t, results, 3)
+	assertConsistent(t, 2, results, []int{1, 2, 4})
+}
+
+func TestNewPartyInvalidParameters(t *testing.T) {
+	net := dkg.NewMemoryNetwork(3, time.Second)
+	_, err := dkg.NewParty(1, 0, 3, net.Transport(1))
+	assert.Error(t, err)
+	_, err = dkg.NewParty(1, 4, 3, net.Transport(1))
+	assert.Error(t, err)
+	_, err = dkg.NewParty(4, 2, 3, net.Transport(4))
+	assert.Error(t, err)
+}
+
+func TestDirectTransport(t *testing.T) {
+	net := dkg.NewMemoryNetwork(3, 100*time.Millisecond)
+	require.NoError(t, net.Direct(1).Send(3, []byte("first")))
+	require.NoError(t, net.Direct(1).Send(3, []byte("second")))
+
+	for _, expected := range []string{"first", "second"} {
+		payload, err := net.Direct(3).Receive(1)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(payload))
+	}
+	_, err := net.Direct(3).Receive(2)
+	assert.ErrorIs(t, err, dkg.ErrTimeout)
+
+	assert.Error(t, net.Direct(1).Send(0, nil))
+	assert.Error(t, net.Direct(1).Send(1, nil))
+}
@@ -0,0 +1,48 @@
+package dkg
+
+import (
+	"errors"
+	"fmt"
+
+	bls12381 "github.com/kilic/bls12-381"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
+)
+
+// Refresh re-randomizes the secret key share of the party without changing sk and W. All parties jointly deal random
+// sharings of zero as in Run and add them to their shares, so that shares of different epochs cannot be combined to
+// reconstruct sk. The refreshed key is tagged with the next epoch. Pre-signatures and PCG seeds derived from shares of
+// an older epoch are rejected by PartySecretKey.CheckEpoch and have to be regenerated.
+//
+// key is the current key of the party and commitments are the Feldman commitments to the current sharing, e.g., from
+// the Result of Run or of the previous Refresh. Every refresh needs a fresh transport.
+func (p *Party) Refresh(key *fhks_bbs_plus.PartySecretKey, commitments fhks_bbs_plus.ShareCommitments) (*Result, error) {
+	if p.threshold < 2 {
+		return nil, errors.New("shares of a 1-out-of-n sharing cannot be refreshed")
+	}
+	if key.Index != p.index {
+		return nil, fmt.Errorf("key of party %d used by party %d", key.Index, p.index)
+	}
+	if len(commitments) != p.threshold {
+		return nil, fmt.Errorf("expected %d commitments, got %d", p.threshold, len(commitments))
+	}
+	if err := key.VerifyShare(commitments); err != nil {
+		return nil, fmt.Errorf("verify current share: %w", err)
+	}
+
+	out, err := p.share(true, key.Epoch+1)
+	if err != nil {
+		return nil, err
+	}
+
+	g2 := bls12381.NewG2()
+	skShare := bls12381.NewFr()
+	skShare.Add(key.SKeyShare.Fr, out.share)
+	refreshed := make([]*bls12381.PointG2, p.threshold)
+	for k := range refreshed {
+		refreshed[k] = g2.New()
+		g2.Add(refreshed[k], commitments[k], out.commitments[k])
+	}
+
+	return p.result(skShare, refreshed, key.Epoch+1, out.qualified)
+}
@@ -0,0 +1,84 @@
+package dkg_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/dkg"
+	"github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
+)
+
+// runRefresh refreshes the keys in results and returns the refreshed results indexed by party.
+func runRefresh(t *testing.T, threshold, n int, results map[int]*dkg.Result,
+	keys map[int]*fhks_bbs_plus.PartySecretKey) map[int]*dkg.Result {
+	net := dkg.NewMemoryNetwork(n, time.Second)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	refreshed := make(map[int]*dkg.Result)
+	for index, result := range results {
+		party, err := dkg.NewParty(index, threshold, n, net.Transport(index))
+		require.NoError(t, err)
+
+		key := result.SecretKey
+		if keys[index] != nil {
+			key = keys[index]
+		}
+
+		wg.Add(1)
+		go func(index int, party *dkg.Party, key *fhks_bbs_plus.PartySecretKey, commitments fhks_bbs_plus.ShareCommitments) {
+			defer wg.Done()
+			result, err := party.Refresh(key, commitments)
+			assert.NoError(t, err, "party %d", index)
+
+			mu.Lock()
+			defer mu.Unlock()
+			refreshed[index] = result
+		}(index, party, key, result.Commitments)
+	}
+	wg.Wait()
+	return refreshed
+}
+
+func TestRefresh(t *testing.T) {
+	g2 := bls12381.NewG2()
+
+	results := runDKG(t, 3, 5, time.Second, []int{1, 2, 3, 4, 5}, nil)
+	require.Len(t, results, 5)
+
+	refreshed := runRefresh(t, 3, 5, results, nil)
+	require.Len(t, refreshed, 5)
+	assertConsistent(t, 3, refreshed, []int{1, 2, 3, 4, 5})
+
+	for index, result := range refreshed {
+		assert.Equal(t, uint64(1), result.SecretKey.Epoch, "party %d", index)
+		assert.True(t, g2.Equal(results[index].SecretKey.PublicKey, result.SecretKey.PublicKey), "party %d", index)
+		assert.False(t, results[index].SecretKey.SKeyShare.Equal(result.SecretKey.SKeyShare.Fr), "party %d", index)
+
+		err := result.SecretKey.CheckEpoch(results[index].SecretKey.Epoch)
+		assert.True(t, errors.Is(err, fhks_bbs_plus.ErrEpochMismatch), "party %d", index)
+	}
+
+	// Old shares do not verify against the refreshed commitments.
+	assert.Error(t, results[1].SecretKey.VerifyShare(refreshed[1].Commitments))
+}
+
+func TestRefreshDisqualifiesOtherEpoch(t *testing.T) {
+	results := runDKG(t, 3, 5, time.Second, []int{1, 2, 3, 4, 5}, nil)
+	require.Len(t, results, 5)
+
+	// Party 5 claims to be at another epoch, so its sharing of zero is not accepted by the others.
+	staleKey := *results[5].SecretKey
+	staleKey.Epoch = 7
+	refreshed := runRefresh(t, 3, 5, results, map[int]*fhks_bbs_plus.PartySecretKey{5: &staleKey})
+	require.Len(t, refreshed, 5)
+
+	delete(refreshed, 5)
+	assertConsistent(t, 3, refreshed, []int{1, 2, 3, 4})
+}
@@ -0,0 +1,276 @@
+package dkg
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	bls12381 "github.com/kilic/bls12-381"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
+)
+
+// Resharing describes the transfer of a sharing of sk from an OldThreshold-out-of-OldN committee to a
+// NewThreshold-out-of-NewN committee without changing W.
+//
+// Every old party i deals its share sk_i with a polynomial g_i of degree NewThreshold-1 and commits to g_i, where the
+// commitment to g_i(0) has to match the public share g_2^(sk_i) of the old sharing. New party j verifies its shares
+// g_i(x_j), complaints are handled as in Run and the new share is sum_i lambda_i * g_i(x_j) for the Lagrange
+// coefficients lambda_i of the qualified old parties, of which there have to be at least OldThreshold.
+//
+// Old and new parties are connected by one transport: old party i is addressed as DealerAddress(i), new party j as
+// ReceiverAddress(j). A participant that is in both committees takes both roles with separate transports. Every
+// address broadcasts one (possibly empty) message per round.
+//
+// The new keys belong to epoch Epoch+1. Afterwards the old parties must delete their old shares and all parties
+// must discard pre-signatures and PCG seeds of older epochs, see fhks_bbs_plus.DiscardStale, and generate new seeds
+// for the new committee.
+type Resharing struct {
+	OldThreshold   int
+	OldN           int
+	NewThreshold   int
+	NewN           int
+	OldCommitments fhks_bbs_plus.ShareCommitments // Feldman commitments to the old sharing.
+	Epoch          uint64                         // Epoch of the old keys.
+}
+
+// DealerAddress returns the transport index of old party index.
+func (r *Resharing) DealerAddress(index int) int {
+	return index
+}
+
+// ReceiverAddress returns the transport index of new party index.
+func (r *Resharing) ReceiverAddress(index int) int {
+	return r.OldN + index
+}
+
+// Parties returns the number of addresses of the transport.
+func (r *Resharing) Parties() int {
+	return r.OldN + r.NewN
+}
+
+func (r *Resharing) validate() error {
+	if r.OldThreshold < 1 || r.OldThreshold > r.OldN {
+		return fmt.Errorf("invalid threshold %d for %d old parties", r.OldThreshold, r.OldN)
+	}
+	if r.NewThreshold < 1 || r.NewThreshold > r.NewN {
+		return fmt.Errorf("invalid threshold %d for %d new parties", r.NewThreshold, r.NewN)
+	}
+	if len(r.OldCommitments) != r.OldThreshold {
+		return fmt.Errorf("expected %d commitments, got %d", r.OldThreshold, len(r.OldCommitments))
+	}
+	return nil
+}
+
+// Deal runs the resharing for the old party holding key.
+func (r *Resharing) Deal(key *fhks_bbs_plus.PartySecretKey, transport Transport) error {
+	if err := r.validate(); err != nil {
+		return err
+	}
+	if key.Index < 1 || key.Index > r.OldN {
+		return fmt.Errorf("invalid index %d for %d old parties", key.Index, r.OldN)
+	}
+	if err := key.CheckEpoch(r.Epoch); err != nil {
+		return err
+	}
+	if err := key.VerifyShare(r.OldCommitments); err != nil {
+		return fmt.Errorf("verify current share: %w", err)
+	}
+
+	// Round 1: deal shares of sk_i.
+	g2 := bls12381.NewG2()
+	coefficients := make([]*bls12381.Fr, r.NewThreshold)
+	coefficients[0] = bls12381.NewFr().Set(key.SKeyShare.Fr)
+	for k := 1; k < len(coefficients); k++ {
+		coefficients[k] = bls12381.NewFr()
+		if _, err := coefficients[k].Rand(rand.Reader); err != nil {
+			return fmt.Errorf("sample coefficient: %w", err)
+		}
+	}
+	commitments := make([]*bls12381.PointG2, r.NewThreshold)
+	for k, coefficient := range coefficients {
+		commitments[k] = g2.New()
+		g2.MulScalar(commitments[k], g2.One(), coefficient)
+	}
+	for j := 1; j <= r.NewN; j++ {
+		if err := transport.Send(r.ReceiverAddress(j), RoundDeal, evaluatePolynomial(coefficients, j).ToBytes()); err != nil {
+			return fmt.Errorf("send share to new party %d: %w", j, err)
+		}
+	}
+	if err := transport.Broadcast(RoundDeal, encodeCommitments(r.Epoch+1, commitments)); err != nil {
+		return fmt.Errorf("broadcast commitments: %w", err)
+	}
+	if _, err := transport.Receive(RoundDeal); err != nil {
+		return fmt.Errorf("receive commitments: %w", err)
+	}
+
+	// Round 2: only new parties complain.
+	if err := transport.Broadcast(RoundComplaint, nil); err != nil {
+		return fmt.Errorf("broadcast complaints: %w", err)
+	}
+	messages, err := transport.Receive(RoundComplaint)
+	if err != nil {
+		return fmt.Errorf("receive complaints: %w", err)
+	}
+
+	// Round 3: answer complaints by revealing the disputed shares.
+	justification := make(map[int]*bls12381.Fr)
+	for _, msg := range messages {
+		j, ok := r.receiverIndex(msg.From)
+		if !ok || !msg.Broadcast {
+			continue
+		}
+		dealers, err := decodeIndices(msg.Payload)
+		if err != nil {
+			continue
+		}
+		for _, dealer := range dealers {
+			if dealer == key.Index {
+				justification[j] = evaluatePolynomial(coefficients, j)
+			}
+		}
+	}
+	if err := transport.Broadcast(RoundJustification, encodeJustification(justification)); err != nil {
+		return fmt.Errorf("broadcast justification: %w", err)
+	}
+	if _, err := transport.Receive(RoundJustification); err != nil {
+		return fmt.Errorf("receive justifications: %w", err)
+	}
+
+	return nil
+}
+
+// Receive runs the resharing for new party index and returns its new key.
+func (r *Resharing) Receive(index int, transport Transport) (*Result, error) {
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+	p, err := NewParty(index, r.NewThreshold, r.NewN, transport)
+	if err != nil {
+		return nil, err
+	}
+
+	// Round 1: receive shares of the old shares.
+	if err := transport.Broadcast(RoundDeal, nil); err != nil {
+		return nil, fmt.Errorf("broadcast commitments: %w", err)
+	}
+	messages, err := transport.Receive(RoundDeal)
+	if err != nil {
+		return nil, fmt.Errorf("receive shares: %w", err)
+	}
+
+	g2 := bls12381.NewG2()
+	commitments := make(map[int][]*bls12381.PointG2)
+	shares := make(map[int]*bls12381.Fr)
+	for _, msg := range messages {
+		dealer, ok := r.dealerIndex(msg.From)
+		if !ok {
+			continue
+		}
+		if msg.Broadcast {
+			c, err := decodeCommitments(msg.Payload, r.Epoch+1, r.NewThreshold)
+			if err == nil && g2.Equal(c[0], helper.EvaluateFeldmanCommitments(r.OldCommitments, dealer)) {
+				commitments[dealer] = c
+			}
+		} else if share, err := fhks_bbs_plus.ScalarFromBytes(msg.Payload); err == nil {
+			shares[dealer] = share
+		}
+	}
+
+	disqualified := make(map[int]bool)
+	for i := 1; i <= r.OldN; i++ {
+		if _, ok := commitments[i]; !ok {
+			disqualified[i] = true
+		}
+	}
+
+	// Round 2: complain about missing or invalid shares.
+	var ownComplaints []int
+	for i := 1; i <= r.OldN; i++ {
+		if disqualified[i] {
+			continue
+		}
+		if share, ok := shares[i]; !ok || !verifyShare(commitments[i], index, share) {
+			ownComplaints = append(ownComplaints, i)
+			delete(shares, i)
+		}
+	}
+	if err := transport.Broadcast(RoundComplaint, encodeIndices(ownComplaints)); err != nil {
+		return nil, fmt.Errorf("broadcast complaints: %w", err)
+	}
+	messages, err = transport.Receive(RoundComplaint)
+	if err != nil {
+		return nil, fmt.Errorf("receive complaints: %w", err)
+	}
+	complaints := map[int][]int{index: ownComplaints}
+	for _, msg := range messages {
+		j, ok := r.receiverIndex(msg.From)
+		if !ok || !msg.Broadcast {
+			continue
+		}
+		if indices, err := decodeIndices(msg.Payload); err == nil {
+			complaints[j] = indices
+		}
+	}
+
+	// Round 3: check the answers of the old parties.
+	if err := transport.Broadcast(RoundJustification, nil); err != nil {
+		return nil, fmt.Errorf("broadcast justification: %w", err)
+	}
+	messages, err = transport.Receive(RoundJustification)
+	if err != nil {
+		return nil, fmt.Errorf("receive justifications: %w", err)
+	}
+	justifications := make(map[int]map[int]*bls12381.Fr)
+	for _, msg := range messages {
+		dealer, ok := r.dealerIndex(msg.From)
+		if !ok || !msg.Broadcast {
+			continue
+		}
+		if justification, err := decodeJustification(msg.Payload); err == nil {
+			justifications[dealer] = justification
+		}
+	}
+	resolveComplaints(index, complaints, justifications, commitments, shares, disqualified)
+
+	// Interpolate the new sharing from the sharings of the old shares.
+	var qualified []int
+	for i := 1; i <= r.OldN; i++ {
+		if !disqualified[i] {
+			qualified = append(qualified, i)
+		}
+	}
+	if len(qualified) < r.OldThreshold {
+		return nil, errors.New("not enough qualified old parties")
+	}
+
+	lagrangeCoefficients := helper.Get0LagrangeCoefficientSetFr(qualified)
+	skShare := bls12381.NewFr().Zero()
+	combined := make([]*bls12381.PointG2, r.NewThreshold)
+	for k := range combined {
+		combined[k] = g2.Zero()
+	}
+	tmpFr := bls12381.NewFr()
+	tmpG2 := g2.New()
+	for l, i := range qualified {
+		tmpFr.Mul(lagrangeCoefficients[l], shares[i])
+		skShare.Add(skShare, tmpFr)
+		for k, c := range commitments[i] {
+			g2.MulScalar(tmpG2, c, lagrangeCoefficients[l])
+			g2.Add(combined[k], combined[k], tmpG2)
+		}
+	}
+
+	return p.result(skShare, combined, r.Epoch+1, qualified)
+}
+
+// dealerIndex returns the old party index of the transport address from.
+func (r *Resharing) dealerIndex(from int) (int, bool) {
+	return from, from >= 1 && from <= r.OldN
+}
+
+// receiverIndex returns the new party index of the transport address from.
+func (r *Resharing) receiverIndex(from int) (int, bool) {
+	return from - r.OldN, from > r.OldN && from <= r.OldN+r.NewN
+}
@@ -0,0 +1,99 @@
+package dkg_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/dkg"
+)
+
+// runReshare reshares from the old parties in dealers to all new parties and returns their results indexed by party.
+func runReshare(t *testing.T, r *dkg.Resharing, timeout time.Duration, old map[int]*dkg.Result, dealers []int) map[int]*dkg.Result {
+	net := dkg.NewMemoryNetwork(r.Parties(), timeout)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	results := make(map[int]*dkg.Result)
+	for _, index := range dealers {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			assert.NoError(t, r.Deal(old[index].SecretKey, net.Transport(r.DealerAddress(index))), "old party %d", index)
+		}(index)
+	}
+	for index := 1; index <= r.NewN; index++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			result, err := r.Receive(index, net.Transport(r.ReceiverAddress(index)))
+			assert.NoError(t, err, "new party %d", index)
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[index] = result
+		}(index)
+	}
+	wg.Wait()
+	return results
+}
+
+func TestReshare(t *testing.T) {
+	g2 := bls12381.NewG2()
+
+	old := runDKG(t, 2, 3, time.Second, []int{1, 2, 3}, nil)
+	require.Len(t, old, 3)
+
+	r := &dkg.Resharing{
+		OldThreshold:   2,
+		OldN:           3,
+		NewThreshold:   3,
+		NewN:           5,
+		OldCommitments: old[1].Commitments,
+	}
+	results := runReshare(t, r, time.Second, old, []int{1, 2, 3})
+	require.Len(t, results, 5)
+	assertConsistent(t, 3, results, []int{1, 2, 3})
+
+	for index, result := range results {
+		assert.True(t, g2.Equal(old[1].SecretKey.PublicKey, result.SecretKey.PublicKey), "new party %d", index)
+		assert.Equal(t, uint64(1), result.SecretKey.Epoch, "new party %d", index)
+	}
+}
+
+func TestReshareWithAbsentOldParty(t *testing.T) {
+	old := runDKG(t, 2, 3, time.Second, []int{1, 2, 3}, nil)
+	require.Len(t, old, 3)
+
+	r := &dkg.Resharing{
+		OldThreshold:   2,
+		OldN:           3,
+		NewThreshold:   2,
+		NewN:           2,
+		OldCommitments: old[1].Commitments,
+	}
+	results := runReshare(t, r, 200*time.Millisecond, old, []int{1, 3})
+	require.Len(t, results, 2)
+	assertConsistent(t, 2, results, []int{1, 3})
+}
+
+func TestReshareRejectsForgedShare(t *testing.T) {
+	old := runDKG(t, 2, 3, time.Second, []int{1, 2, 3}, nil)
+	require.Len(t, old, 3)
+
+	// Old party 2 deals a share that is not its share of sk.
+	forged := *old[2].SecretKey
+	forged.SKeyShare.Fr = bls12381.NewFr().One()
+	r := &dkg.Resharing{
+		OldThreshold:   2,
+		OldN:           3,
+		NewThreshold:   2,
+		NewN:           3,
+		OldCommitments: old[1].Commitments,
+	}
+	assert.Error(t, r.Deal(&forged, dkg.NewMemoryNetwork(r.Parties(), time.Second).Transport(r.DealerAddress(2))))
+}
@@ -0,0 +1,211 @@
+package dkg
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrTimeout is returned by the Receive of a DirectTransport if no message arrives in time.
+var ErrTimeout = errors.New("timeout while waiting for message")
+
+// Round identifies a communication round of the DKG.
+type Round int
+
+const (
+	RoundDeal          Round = iota + 1 // Commitments are broadcast and shares are sent.
+	RoundComplaint                      // Complaints about invalid or missing shares are broadcast.
+	RoundJustification                  // Dealers broadcast the shares complained about.
+)
+
+// Message is a message received in a round.
+type Message struct {
+	From      int    // Index of the sender.
+	Broadcast bool   // Whether the message was broadcast or sent to the receiver only.
+	Payload   []byte // Encoded content of the message.
+}
+
+// Transport connects a party to the other parties of the DKG, which are indexed 1, ..., n.
+//
+// Every party broadcasts exactly one message per round, after its direct messages of that round. Broadcasts must be
+// reliable, i.e., all honest parties receive the same broadcast message of a sender, and direct messages must be
+// private and authenticated.
+type Transport interface {
+	// Broadcast sends payload to all other parties.
+	Broadcast(round Round, payload []byte) error
+	// Send sends payload to party to only.
+	Send(to int, round Round, payload []byte) error
+	// Receive returns the messages of the other parties in round. It blocks until the broadcast of every other party
+	// arrived or the transport gives up waiting, e.g., after a timeout. Messages of parties that did not send in time
+	// are missing from the result.
+	Receive(round Round) ([]*Message, error)
+}
+
+// MemoryNetwork delivers messages between parties in the same process. It is intended for tests.
+type MemoryNetwork struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	n       int
+	timeout time.Duration
+	inboxes map[inboxKey][]*Message
+	streams map[link][][]byte
+}
+
+type inboxKey struct {
+	to    int
+	round Round
+}
+
+type link struct {
+	from, to int
+}
+
+// NewMemoryNetwork returns a network for n parties whose Receive gives up waiting for missing parties after
+// timeout.
+func NewMemoryNetwork(n int, timeout time.Duration) *MemoryNetwork {
+	net := &MemoryNetwork{
+		n:       n,
+		timeout: timeout,
+		inboxes: make(map[inboxKey][]*Message),
+		streams: make(map[link][][]byte),
+	}
+	net.cond = sync.NewCond(&net.mu)
+	return net
+}
+
+// Transport returns the transport of party index.
+func (net *MemoryNetwork) Transport(index int) Transport {
+	return &memoryTransport{net: net, index: index}
+}
+
+// Direct returns the transport of party index for direct messages outside the rounds of the DKG, e.g., for the
+// pairwise protocols of the PCG seed generation (see pcg.Transport). Messages between two parties are delivered in
+// the order they were sent.
+func (net *MemoryNetwork) Direct(index int) *DirectTransport {
+	return &DirectTransport{net: net, index: index}
+}
+
+type memoryTransport struct {
+	net   *MemoryNetwork
+	index int
+}
+
+func (mt *memoryTransport) Broadcast(round Round, payload []byte) error {
+	mt.net.mu.Lock()
+	defer mt.net.mu.Unlock()
+
+	for to := 1; to <= mt.net.n; to++ {
+		if to != mt.index {
+			mt.net.deliver(to, round, &Message{From: mt.index, Broadcast: true, Payload: payload})
+		}
+	}
+	mt.net.cond.Broadcast()
+	return nil
+}
+
+func (mt *memoryTransport) Send(to int, round Round, payload []byte) error {
+	if to < 1 || to > mt.net.n || to == mt.index {
+		return fmt.Errorf("invalid receiver %d", to)
+	}
+
+	mt.net.mu.Lock()
+	defer mt.net.mu.Unlock()
+
+	mt.net.deliver(to, round, &Message{From: mt.index, Payload: payload})
+	mt.net.cond.Broadcast()
+	return nil
+}
+
+func (mt *memoryTransport) Receive(round Round) ([]*Message, error) {
+	net := mt.net
+	key := inboxKey{to: mt.index, round: round}
+
+	timedOut := false
+	timer := time.AfterFunc(net.timeout, func() {
+		net.mu.Lock()
+		defer net.mu.Unlock()
+		timedOut = true
+		net.cond.Broadcast()
+	})
+	defer timer.Stop()
+
+	net.mu.Lock()
+	defer net.mu.Unlock()
+	for !timedOut && net.countBroadcasts(key) < net.n-1 {
+		net.cond.Wait()
+	}
+
+	messages := net.inboxes[key]
+	delete(net.inboxes, key)
+	return messages, nil
+}
+
+// deliver appends msg to the inbox of party to. The caller must hold net.mu.
+func (net *MemoryNetwork) deliver(to int, round Round, msg *Message) {
+	key := inboxKey{to: to, round: round}
+	net.inboxes[key] = append(net.inboxes[key], msg)
+}
+
+// countBroadcasts returns the number of parties whose broadcast is in the inbox. The caller must hold net.mu.
+func (net *MemoryNetwork) countBroadcasts(key inboxKey) int {
+	senders := make(map[int]struct{})
+	for _, msg := range net.inboxes[key] {
+		if msg.Broadcast {
+			senders[msg.From] = struct{}{}
+		}
+	}
+	return len(senders)
+}
+
+// DirectTransport exchanges direct messages of a party with the other parties of a MemoryNetwork.
+type DirectTransport struct {
+	net   *MemoryNetwork
+	index int
+}
+
+// Send sends payload to party to.
+func (dt *DirectTransport) Send(to int, payload []byte) error {
+	if to < 1 || to > dt.net.n || to == dt.index {
+		return fmt.Errorf("invalid receiver %d", to)
+	}
+
+	dt.net.mu.Lock()
+	defer dt.net.mu.Unlock()
+
+	key := link{from: dt.index, to: to}
+	dt.net.streams[key] = append(dt.net.streams[key], append([]byte(nil), payload...))
+	dt.net.cond.Broadcast()
+	return nil
+}
+
+// Receive returns the next message of party from. It returns ErrTimeout if no message arrives in time.
+func (dt *DirectTransport) Receive(from int) ([]byte, error) {
+	if from < 1 || from > dt.net.n || from == dt.index {
+		return nil, fmt.Errorf("invalid sender %d", from)
+	}
+	net := dt.net
+	key := link{from: from, to: dt.index}
+
+	timedOut := false
+	timer := time.AfterFunc(net.timeout, func() {
+		net.mu.Lock()
+		defer net.mu.Unlock()
+		timedOut = true
+		net.cond.Broadcast()
+	})
+	defer timer.Stop()
+
+	net.mu.Lock()
+	defer net.mu.Unlock()
+	for !timedOut && len(net.streams[key]) == 0 {
+		net.cond.Wait()
+	}
+	if len(net.streams[key]) == 0 {
+		return nil, fmt.Errorf("receive from party %d: %w", from, ErrTimeout)
+	}
+
+	payload := net.streams[key][0]
+	net.streams[key] = net.streams[key][1:]
+	return payload, nil
+}
@@ -0,0 +1,135 @@
+package fhks_bbs_plus
+
+import (
+	"crypto/rand"
+	"sort"
+
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+// batchExponentBytes is the size of the random exponents of the linear combination checked by BatchVerify. An
+// invalid signature passes a batch with probability at most 2^-128.
+const batchExponentBytes = 16
+
+// BatchEntry is a signature on messages under PublicKey to be checked by BatchVerify.
+type BatchEntry struct {
+	PublicKey *PublicKey
+	Messages  []*bls12381.Fr
+	Signature *ThresholdSignature
+}
+
+// SignedMessages is a signature on messages to be checked by PublicKey.BatchVerify.
+type SignedMessages struct {
+	Messages  []*bls12381.Fr
+	Signature *ThresholdSignature
+}
+
+// batchItem holds the values of a BatchEntry that enter the batched pairing equation.
+type batchItem struct {
+	index    int
+	w        *bls12381.PointG2
+	wKey     string
+	capitalA *bls12381.PointG1
+	e        *bls12381.Fr
+	basis    *bls12381.PointG1
+}
+
+// BatchVerify is BatchVerify for signatures which are all under pk.
+func (pk *PublicKey) BatchVerify(batch []*SignedMessages) []int {
+	entries := make([]*BatchEntry, len(batch))
+	for i, signed := range batch {
+		entries[i] = &BatchEntry{PublicKey: pk, Messages: signed.Messages, Signature: signed.Signature}
+	}
+	return BatchVerify(entries)
+}
+
+// BatchVerify checks all entries at once and returns the indices of the invalid ones in ascending order, which is
+// empty if all signatures are valid. Instead of checking e(A, W + g_2^e) == e(B, g_2) for every entry, it checks a
+// random linear combination of the equations with one pairing per distinct public key plus one. If the combined
+// check fails, the batch is bisected to find the invalid signatures.
+func BatchVerify(entries []*BatchEntry) []int {
+	g2 := bls12381.NewG2()
+
+	var invalid []int
+	items := make([]*batchItem, 0, len(entries))
+	for i, entry := range entries {
+		if len(entry.Messages) > len(entry.PublicKey.H) {
+			invalid = append(invalid, i)
+			continue
+		}
+		items = append(items, &batchItem{
+			index:    i,
+			w:        entry.PublicKey.W,
+			wKey:     string(g2.ToCompressed(entry.PublicKey.W)),
+			capitalA: entry.Signature.CapitalA,
+			e:        entry.Signature.E,
+			basis:    entry.PublicKey.verificationBasis(entry.Messages, entry.Signature.S),
+		})
+	}
+
+	invalid = append(invalid, bisectBatch(items)...)
+	sort.Ints(invalid)
+	return invalid
+}
+
+// bisectBatch returns the indices of the invalid items, splitting items in halves as long as the batch check fails.
+func bisectBatch(items []*batchItem) []int {
+	if len(items) == 0 || checkBatch(items) {
+		return nil
+	}
+	if len(items) == 1 {
+		return []int{items[0].index}
+	}
+	mid := len(items) / 2
+	return append(bisectBatch(items[:mid]), bisectBatch(items[mid:])...)
+}
+
+// checkBatch checks prod_W e(sum_k r_k * A_k, W) * e(sum_k r_k * (e_k * A_k - B_k), g_2) == 1 for fresh random
+// exponents r_k, where the first product is over the distinct public keys W.
+func checkBatch(items []*batchItem) bool {
+	g1 := bls12381.NewG1()
+
+	var wKeys []string
+	ws := make(map[string]*bls12381.PointG2)
+	capitalASums := make(map[string]*bls12381.PointG1)
+	basisSum := g1.Zero()
+
+	for _, item := range items {
+		r := randomBatchExponent()
+
+		rA := g1.New()
+		g1.MulScalar(rA, item.capitalA, r)
+		if _, ok := capitalASums[item.wKey]; !ok {
+			wKeys = append(wKeys, item.wKey)
+			ws[item.wKey] = item.w
+			capitalASums[item.wKey] = g1.Zero()
+		}
+		g1.Add(capitalASums[item.wKey], capitalASums[item.wKey], rA)
+
+		tmp := g1.New()
+		g1.MulScalar(tmp, rA, item.e)
+		g1.Add(basisSum, basisSum, tmp)
+		g1.MulScalar(tmp, item.basis, r)
+		g1.Sub(basisSum, basisSum, tmp)
+	}
+
+	engine := bls12381.NewEngine()
+	for _, wKey := range wKeys {
+		engine.AddPair(capitalASums[wKey], ws[wKey])
+	}
+	engine.AddPair(basisSum, bls12381.NewG2().One())
+	return engine.Check()
+}
+
+// randomBatchExponent samples a non-zero exponent of batchExponentBytes bytes.
+func randomBatchExponent() *bls12381.Fr {
+	buf := make([]byte, batchExponentBytes)
+	r := bls12381.NewFr()
+	for r.IsZero() {
+		if _, err := rand.Read(buf); err != nil {
+			panic("failed to generate batch exponent")
+		}
+		r.FromBytes(buf)
+	}
+	return r
+}
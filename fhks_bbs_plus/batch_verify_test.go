@@ -0,0 +1,63 @@
+package fhks_bbs_plus_test
+
+import (
+	"testing"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
+	"github.com/perun-network/bbs-plus-threshold-wallet/test"
+)
+
+func signRandomMessages(sk fhks_bbs_plus.SecretKey, pk *fhks_bbs_plus.PublicKey) ([]*bls12381.Fr, *fhks_bbs_plus.ThresholdSignature) {
+	messages := make([]*bls12381.Fr, test.MessageCount)
+	for i := range messages {
+		messages[i] = fhks_bbs_plus.GenerateRandomFr()
+	}
+	return messages, sk.Sign(*pk, messages, fhks_bbs_plus.GenerateRandomFr(), fhks_bbs_plus.GenerateRandomFr())
+}
+
+func TestBatchVerifySamePublicKey(t *testing.T) {
+	sk := fhks_bbs_plus.SecretKey{Fr: fhks_bbs_plus.GenerateRandomFr()}
+	pk := sk.GetPublicKey(test.MessageCount)
+
+	batch := make([]*fhks_bbs_plus.SignedMessages, 9)
+	for i := range batch {
+		messages, signature := signRandomMessages(sk, pk)
+		batch[i] = &fhks_bbs_plus.SignedMessages{Messages: messages, Signature: signature}
+	}
+	assert.Empty(t, pk.BatchVerify(batch))
+	assert.Empty(t, pk.BatchVerify(nil))
+
+	// Invalidate some signatures in different ways.
+	g1 := bls12381.NewG1()
+	g1.Add(batch[1].Signature.CapitalA, batch[1].Signature.CapitalA, g1.One())
+	batch[4].Signature.E.Add(batch[4].Signature.E, bls12381.NewFr().One())
+	batch[8].Messages[0] = fhks_bbs_plus.GenerateRandomFr()
+	assert.Equal(t, []int{1, 4, 8}, pk.BatchVerify(batch))
+
+	for i, signed := range batch {
+		assert.Equal(t, i != 1 && i != 4 && i != 8, pk.Verify(signed.Messages, signed.Signature))
+	}
+}
+
+func TestBatchVerifyDifferentPublicKeys(t *testing.T) {
+	entries := make([]*fhks_bbs_plus.BatchEntry, 6)
+	for i := range entries {
+		sk := fhks_bbs_plus.SecretKey{Fr: fhks_bbs_plus.GenerateRandomFr()}
+		pk := sk.GetPublicKey(test.MessageCount)
+		messages, signature := signRandomMessages(sk, pk)
+		entries[i] = &fhks_bbs_plus.BatchEntry{PublicKey: pk, Messages: messages, Signature: signature}
+	}
+	assert.Empty(t, fhks_bbs_plus.BatchVerify(entries))
+
+	// A signature that is valid under another key must be rejected.
+	entries[2].PublicKey, entries[3].PublicKey = entries[3].PublicKey, entries[2].PublicKey
+	assert.Equal(t, []int{2, 3}, fhks_bbs_plus.BatchVerify(entries))
+
+	// Too many messages are rejected without checking the signature.
+	entries[2].PublicKey, entries[3].PublicKey = entries[3].PublicKey, entries[2].PublicKey
+	entries[5].Messages = append(entries[5].Messages, fhks_bbs_plus.GenerateRandomFr())
+	assert.Equal(t, []int{5}, fhks_bbs_plus.BatchVerify(entries))
+}
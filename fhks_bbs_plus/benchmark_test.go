@@ -0,0 +1,69 @@
+package fhks_bbs_plus_test
+
+import (
+	"fmt"
+	"testing"
+
+	bls12381 "github.com/kilic/bls12-381"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
+)
+
+// benchmarkMessageCounts are the numbers of attributes credentials typically carry.
+var benchmarkMessageCounts = []int{10, 25, 50, 100}
+
+func benchmarkSetup(messageCount int) (fhks_bbs_plus.SecretKey, *fhks_bbs_plus.PublicKey, []*bls12381.Fr) {
+	sk := fhks_bbs_plus.SecretKey{Fr: fhks_bbs_plus.GenerateRandomFr()}
+	pk := sk.GetPublicKey(messageCount)
+	messages := make([]*bls12381.Fr, messageCount)
+	for i := range messages {
+		messages[i] = fhks_bbs_plus.GenerateRandomFr()
+	}
+	return sk, pk, messages
+}
+
+func BenchmarkSign(b *testing.B) {
+	for _, messageCount := range benchmarkMessageCounts {
+		b.Run(fmt.Sprintf("messages=%d", messageCount), func(b *testing.B) {
+			sk, pk, messages := benchmarkSetup(messageCount)
+			e, s := fhks_bbs_plus.GenerateRandomFr(), fhks_bbs_plus.GenerateRandomFr()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				sk.Sign(*pk, messages, e, s)
+			}
+		})
+	}
+}
+
+func BenchmarkPartialSign(b *testing.B) {
+	for _, messageCount := range benchmarkMessageCounts {
+		b.Run(fmt.Sprintf("messages=%d", messageCount), func(b *testing.B) {
+			_, pk, messages := benchmarkSetup(messageCount)
+			preSignature := fhks_bbs_plus.NewLivePreSignature()
+			preSignature.AShare.Set(fhks_bbs_plus.GenerateRandomFr())
+			preSignature.AlphaShare.Set(fhks_bbs_plus.GenerateRandomFr())
+			keyShare := &fhks_bbs_plus.PartySecretKey{Index: 1}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := fhks_bbs_plus.NewPartialThresholdSignature().New(messages, pk, keyShare, preSignature); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	for _, messageCount := range benchmarkMessageCounts {
+		b.Run(fmt.Sprintf("messages=%d", messageCount), func(b *testing.B) {
+			sk, pk, messages := benchmarkSetup(messageCount)
+			signature := sk.Sign(*pk, messages, fhks_bbs_plus.GenerateRandomFr(), fhks_bbs_plus.GenerateRandomFr())
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if !pk.Verify(messages, signature) {
+					b.Fatal("signature verification failed")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,355 @@
+package fhks_bbs_plus
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
+)
+
+// PreSignatureCommitment is the public verification data a party publishes for one of its pre-signatures.
+// Every element is g_2 raised to the corresponding share of PerPartyPreSignature, so the commitments of a live
+// pre-signature can be derived for any signer set without learning the shares themselves. AG1 additionally commits to
+// the a share in G1, against which the products in the terms are checked (see CrossCheckPreSignatureCommitments).
+type PreSignatureCommitment struct {
+	AG1        *bls12381.PointG1   // g_1^(a^k_i).
+	A          *bls12381.PointG2   // g_2^(a^k_i).
+	E          *bls12381.PointG2   // g_2^(e^k_i).
+	S          *bls12381.PointG2   // g_2^(s^k_i).
+	AeTermOwn  *bls12381.PointG2   // g_2^(a^k_i * e^k_i).
+	AsTermOwn  *bls12381.PointG2   // g_2^(a^k_i * s^k_i).
+	AskTermOwn *bls12381.PointG2   // g_2^(a^k_i * sk_i).
+	AeTermsA   []*bls12381.PointG2 // Commitments to AeTermsA.
+	AeTermsE   []*bls12381.PointG2 // Commitments to AeTermsE.
+	AsTermsA   []*bls12381.PointG2 // Commitments to AsTermsA.
+	AsTermsS   []*bls12381.PointG2 // Commitments to AsTermsS.
+	AskTermsA  []*bls12381.PointG2 // Commitments to AskTermsA.
+	AskTermsSK []*bls12381.PointG2 // Commitments to AskTermsSK.
+}
+
+// LivePreSignatureCommitment holds the commitments to the shares of a LivePreSignature.
+// It is what a combiner checks a PartialThresholdSignature against.
+type LivePreSignatureCommitment struct {
+	A     *bls12381.PointG2 // g_2^(a_i).
+	E     *bls12381.PointG2 // g_2^(e_i).
+	S     *bls12381.PointG2 // g_2^(s_i).
+	Delta *bls12381.PointG2 // g_2^(delta_i).
+	Alpha *bls12381.PointG2 // g_2^(alpha_i).
+}
+
+// Commitment derives the public verification data of a pre-signature.
+func (ppp *PerPartyPreSignature) Commitment() *PreSignatureCommitment {
+	return &PreSignatureCommitment{
+		AG1:        commitFrG1(ppp.AShare),
+		A:          commitFr(ppp.AShare),
+		E:          commitFr(ppp.EShare),
+		S:          commitFr(ppp.SShare),
+		AeTermOwn:  commitFr(ppp.AeTermOwn),
+		AsTermOwn:  commitFr(ppp.AsTermOwn),
+		AskTermOwn: commitFr(ppp.AskTermOwn),
+		AeTermsA:   commitFrSlice(ppp.AeTermsA),
+		AeTermsE:   commitFrSlice(ppp.AeTermsE),
+		AsTermsA:   commitFrSlice(ppp.AsTermsA),
+		AsTermsS:   commitFrSlice(ppp.AsTermsS),
+		AskTermsA:  commitFrSlice(ppp.AskTermsA),
+		AskTermsSK: commitFrSlice(ppp.AskTermsSK),
+	}
+}
+
+// Commitment derives the public verification data of a pre-signature of the n-out-of-n setting.
+// The shares are already live, hence the commitment can be used by a combiner directly.
+func (pps *PerPartyPreSignatureSimple) Commitment() *LivePreSignatureCommitment {
+	return &LivePreSignatureCommitment{
+		A:     commitFr(pps.AShare),
+		E:     commitFr(pps.EShare),
+		S:     commitFr(pps.SShare),
+		Delta: commitFr(pps.DeltaShare),
+		Alpha: commitFr(pps.AlphaShare),
+	}
+}
+
+// Live computes the commitment to the live pre-signature of party ownIndex for the signer set indices.
+// It mirrors LivePreSignature.FromPreSignature in the exponent.
+func (psc *PreSignatureCommitment) Live(ownIndex int, indices []int) (*LivePreSignatureCommitment, error) {
+	lagrangeCoefficients := helper.Get0LagrangeCoefficientSetFr(indices)
+	return psc.LiveWithCoefficients(ownIndex, indices, lagrangeCoefficients)
+}
+
+// LiveWithCoefficients works like Live but uses the given lagrange coefficients for the signer set indices.
+func (psc *PreSignatureCommitment) LiveWithCoefficients(
+	ownIndex int,
+	indices []int,
+	lagrangeCoefficients []*bls12381.Fr) (*LivePreSignatureCommitment, error) {
+	if len(indices) != len(lagrangeCoefficients) {
+		return nil, errors.New("number of indices and lagrange coefficients must match")
+	}
+
+	g2 := bls12381.NewG2()
+
+	alpha := g2.New().Set(psc.AsTermOwn)
+	ae := g2.New().Set(psc.AeTermOwn)
+	ask := g2.Zero()
+	askOwnCoefficient := g2.New().Set(psc.AskTermOwn)
+
+	indI := -1
+	for indJ, elJ := range indices {
+		if elJ == ownIndex {
+			indI = indJ
+			continue
+		}
+		if elJ < 1 || elJ > len(psc.AeTermsA) || elJ > len(psc.AeTermsE) || elJ > len(psc.AsTermsA) ||
+			elJ > len(psc.AsTermsS) || elJ > len(psc.AskTermsA) || elJ > len(psc.AskTermsSK) {
+			return nil, fmt.Errorf("index %d is out of range of the pre-signature commitment", elJ)
+		}
+
+		g2.Add(ae, ae, psc.AeTermsA[elJ-1])
+		g2.Add(ae, ae, psc.AeTermsE[elJ-1])
+		g2.Add(alpha, alpha, psc.AsTermsA[elJ-1])
+		g2.Add(alpha, alpha, psc.AsTermsS[elJ-1])
+
+		tmp := g2.New()
+		g2.MulScalar(tmp, psc.AskTermsA[elJ-1], lagrangeCoefficients[indJ])
+		g2.Add(ask, ask, tmp)
+
+		g2.Add(askOwnCoefficient, askOwnCoefficient, psc.AskTermsSK[elJ-1])
+	}
+	if indI < 0 {
+		return nil, fmt.Errorf("own index %d is not part of the signer set", ownIndex)
+	}
+	g2.MulScalar(askOwnCoefficient, askOwnCoefficient, lagrangeCoefficients[indI])
+	g2.Add(ask, ask, askOwnCoefficient)
+
+	delta := g2.New()
+	g2.Add(delta, ae, ask)
+
+	return &LivePreSignatureCommitment{
+		A:     g2.New().Set(psc.A),
+		E:     g2.New().Set(psc.E),
+		S:     g2.New().Set(psc.S),
+		Delta: delta,
+		Alpha: alpha,
+	}, nil
+}
+
+// CommitmentConflictError reports that the cross terms of two parties do not add up to the product of their shares.
+// One of the two parties published wrong commitments, but the combiner cannot tell which.
+type CommitmentConflictError struct {
+	Indices [2]int // Indices of the two parties.
+	Reason  string // Which cross term does not match.
+}
+
+func (e *CommitmentConflictError) Error() string {
+	return fmt.Sprintf("commitments of parties %d and %d conflict: %s", e.Indices[0], e.Indices[1], e.Reason)
+}
+
+// CrossCheckPreSignatureCommitments checks the pre-signature commitments of the signer set against each other and
+// against the public keys of the parties. indices, commitments and partyPublicKeys are aligned.
+//
+// For every party i, the own terms must commit to a_i * e_i, a_i * s_i and a_i * sk_i, otherwise the returned error
+// contains a *PartialSignatureError blaming i. For every pair of parties i and j that passed these checks, the cross
+// terms of i and j must commit to shares of a_i * e_j, a_i * s_j and a_i * sk_j, otherwise the error contains a
+// *CommitmentConflictError for both. The returned error joins all findings.
+func CrossCheckPreSignatureCommitments(
+	indices []int,
+	commitments []*PreSignatureCommitment,
+	partyPublicKeys []*PartyPublicKey,
+) error {
+	if len(indices) != len(commitments) || len(indices) != len(partyPublicKeys) {
+		return errors.New("number of indices, commitments and party public keys must match")
+	}
+	for i, index := range indices {
+		if partyPublicKeys[i].Index != index {
+			return fmt.Errorf("public key of party %d given for party %d", partyPublicKeys[i].Index, index)
+		}
+		c := commitments[i]
+		for _, terms := range [][]*bls12381.PointG2{c.AeTermsA, c.AeTermsE, c.AsTermsA, c.AsTermsS, c.AskTermsA, c.AskTermsSK} {
+			for _, other := range indices {
+				if other < 1 || other > len(terms) {
+					return fmt.Errorf("index %d is out of range of the pre-signature commitment of party %d", other, index)
+				}
+			}
+		}
+	}
+
+	g2 := bls12381.NewG2()
+	var errs []error
+	consistent := make([]bool, len(indices))
+	for i, index := range indices {
+		c := commitments[i]
+		switch {
+		case !isProduct(c.AG1, g2.One(), c.A):
+			errs = append(errs, &PartialSignatureError{Index: index, Reason: "a share in G1 does not match commitment"})
+		case !isProduct(c.AG1, c.E, c.AeTermOwn):
+			errs = append(errs, &PartialSignatureError{Index: index, Reason: "a * e term does not match commitments"})
+		case !isProduct(c.AG1, c.S, c.AsTermOwn):
+			errs = append(errs, &PartialSignatureError{Index: index, Reason: "a * s term does not match commitments"})
+		case !isProduct(c.AG1, partyPublicKeys[i].PublicShare, c.AskTermOwn):
+			errs = append(errs, &PartialSignatureError{Index: index, Reason: "a * sk term does not match commitments"})
+		default:
+			consistent[i] = true
+		}
+	}
+
+	for i, indexI := range indices {
+		for j, indexJ := range indices {
+			if i == j || !consistent[i] || !consistent[j] {
+				continue
+			}
+			ci, cj := commitments[i], commitments[j]
+			sum := func(a, b *bls12381.PointG2) *bls12381.PointG2 {
+				return g2.Add(g2.New(), a, b)
+			}
+			conflict := func(reason string) {
+				errs = append(errs, &CommitmentConflictError{Indices: [2]int{indexI, indexJ}, Reason: reason})
+			}
+			switch {
+			case !isProduct(ci.AG1, cj.E, sum(ci.AeTermsA[indexJ-1], cj.AeTermsE[indexI-1])):
+				conflict("a * e cross terms")
+			case !isProduct(ci.AG1, cj.S, sum(ci.AsTermsA[indexJ-1], cj.AsTermsS[indexI-1])):
+				conflict("a * s cross terms")
+			case !isProduct(ci.AG1, partyPublicKeys[j].PublicShare, sum(ci.AskTermsA[indexJ-1], cj.AskTermsSK[indexI-1])):
+				conflict("a * sk cross terms")
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isProduct checks e(x, y) == e(g_1, product), i.e., that product commits to the product of the exponents of x and y.
+func isProduct(x *bls12381.PointG1, y, product *bls12381.PointG2) bool {
+	engine := bls12381.NewEngine()
+	engine.AddPair(x, y)
+	engine.AddPairInv(engine.G1.One(), product)
+	return engine.Check()
+}
+
+// ToBytes serializes the commitment. The G1 commitment AG1 and the six single G2 commitments are followed by the six
+// length-prefixed commitment slices, all points in compressed form.
+func (psc *PreSignatureCommitment) ToBytes() ([]byte, error) {
+	g2 := bls12381.NewG2()
+
+	bytes := bls12381.NewG1().ToCompressed(psc.AG1)
+	for _, p := range []*bls12381.PointG2{psc.A, psc.E, psc.S, psc.AeTermOwn, psc.AsTermOwn, psc.AskTermOwn} {
+		bytes = append(bytes, g2.ToCompressed(p)...)
+	}
+
+	for _, slice := range [][]*bls12381.PointG2{
+		psc.AeTermsA, psc.AeTermsE, psc.AsTermsA, psc.AsTermsS, psc.AskTermsA, psc.AskTermsSK} {
+		lengthBytes := make([]byte, helper.IntSize)
+		binary.LittleEndian.PutUint32(lengthBytes, uint32(len(slice)))
+		bytes = append(bytes, lengthBytes...)
+		for _, p := range slice {
+			bytes = append(bytes, g2.ToCompressed(p)...)
+		}
+	}
+
+	return bytes, nil
+}
+
+// PreSignatureCommitmentFromBytes deserializes a commitment serialized with PreSignatureCommitment.ToBytes.
+func PreSignatureCommitmentFromBytes(data []byte) (*PreSignatureCommitment, error) {
+	g2 := bls12381.NewG2()
+
+	readPoint := func(data []byte) (*bls12381.PointG2, []byte, error) {
+		if len(data) < helper.LenBytesG2Compressed {
+			return nil, nil, fmt.Errorf("%w: data too short to contain G2 point", ErrInvalidEncoding)
+		}
+		p, err := g2.FromCompressed(data[:helper.LenBytesG2Compressed])
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: deserialize G2 compressed commitment: %w", ErrInvalidEncoding, err)
+		}
+		return p, data[helper.LenBytesG2Compressed:], nil
+	}
+
+	readPointSlice := func(data []byte) ([]*bls12381.PointG2, []byte, error) {
+		if len(data) < helper.IntSize {
+			return nil, nil, fmt.Errorf("%w: data too short to contain length", ErrInvalidEncoding)
+		}
+		length := int(binary.LittleEndian.Uint32(data[:helper.IntSize]))
+		data = data[helper.IntSize:]
+		if length > len(data)/helper.LenBytesG2Compressed {
+			return nil, nil, fmt.Errorf("%w: data too short to contain all commitments", ErrInvalidEncoding)
+		}
+
+		slice := make([]*bls12381.PointG2, length)
+		for i := range slice {
+			var err error
+			if slice[i], data, err = readPoint(data); err != nil {
+				return nil, nil, err
+			}
+		}
+		return slice, data, nil
+	}
+
+	if len(data) < helper.LenBytesG1Compressed {
+		return nil, fmt.Errorf("%w: data too short to contain G1 point", ErrInvalidEncoding)
+	}
+	aG1, err := bls12381.NewG1().FromCompressed(data[:helper.LenBytesG1Compressed])
+	if err != nil {
+		return nil, fmt.Errorf("%w: deserialize G1 compressed commitment: %w", ErrInvalidEncoding, err)
+	}
+	data = data[helper.LenBytesG1Compressed:]
+
+	single := make([]*bls12381.PointG2, 6)
+	for i := range single {
+		var err error
+		if single[i], data, err = readPoint(data); err != nil {
+			return nil, err
+		}
+	}
+
+	slices := make([][]*bls12381.PointG2, 6)
+	for i := range slices {
+		var err error
+		if slices[i], data, err = readPointSlice(data); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(data) != 0 {
+		return nil, fmt.Errorf("%w: unexpected %d trailing bytes after pre-signature commitment", ErrInvalidEncoding, len(data))
+	}
+
+	return &PreSignatureCommitment{
+		AG1:        aG1,
+		A:          single[0],
+		E:          single[1],
+		S:          single[2],
+		AeTermOwn:  single[3],
+		AsTermOwn:  single[4],
+		AskTermOwn: single[5],
+		AeTermsA:   slices[0],
+		AeTermsE:   slices[1],
+		AsTermsA:   slices[2],
+		AsTermsS:   slices[3],
+		AskTermsA:  slices[4],
+		AskTermsSK: slices[5],
+	}, nil
+}
+
+// commitFr returns g_2^x.
+func commitFr(x *bls12381.Fr) *bls12381.PointG2 {
+	g2 := bls12381.NewG2()
+	c := g2.New()
+	g2.MulScalar(c, g2.One(), x)
+	return c
+}
+
+// commitFrG1 returns g_1^x.
+func commitFrG1(x *bls12381.Fr) *bls12381.PointG1 {
+	g1 := bls12381.NewG1()
+	c := g1.New()
+	g1.MulScalar(c, g1.One(), x)
+	return c
+}
+
+// commitFrSlice returns g_2^x for every element x of the slice.
+func commitFrSlice(xs []*bls12381.Fr) []*bls12381.PointG2 {
+	cs := make([]*bls12381.PointG2, len(xs))
+	for i, x := range xs {
+		cs[i] = commitFr(x)
+	}
+	return cs
+}
@@ -2,6 +2,7 @@ package fhks_bbs_plus_test
 
 import (
 	"crypto/rand"
+	"encoding/binary"
 	"fmt"
 	bls12381 "github.com/kilic/bls12-381"
 	"github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
@@ -46,6 +47,7 @@ func TestPartySecretKeySerializationDeserialization(t *testing.T) {
 		SKeyShare: fhks_bbs_plus.SecretKey{skShare},
 		PublicKey: publicKey,
 		Index:     index,
+		Epoch:     3,
 	}
 
 	// Serialize the PartySecretKey to bytes
@@ -60,11 +62,25 @@ func TestPartySecretKeySerializationDeserialization(t *testing.T) {
 	assert.True(t, skShare.Equal(unmarshalledPSK.SKeyShare.Fr), "SKShare mismatch")
 	assert.True(t, g2.Equal(originalPSK.PublicKey, unmarshalledPSK.PublicKey), "PublicKey mismatch")
 	assert.Equal(t, originalPSK.Index, unmarshalledPSK.Index, "Index mismatch")
+	assert.Equal(t, originalPSK.Epoch, unmarshalledPSK.Epoch, "Epoch mismatch")
+
+	// Raw keys serialized without epoch belong to epoch 0.
+	legacyBytes := append(skShare.ToBytes(), g2.ToCompressed(publicKey)...)
+	legacyBytes = binary.LittleEndian.AppendUint32(legacyBytes, uint32(index))
+	legacyPSK, err := fhks_bbs_plus.UnmarshalPartyPrivateKeyLegacy(legacyBytes)
+	assert.NoError(t, err)
+	assert.True(t, skShare.Equal(legacyPSK.SKeyShare.Fr), "SKShare mismatch")
+	assert.Equal(t, index, legacyPSK.Index, "Index mismatch")
+	assert.Equal(t, uint64(0), legacyPSK.Epoch, "Epoch mismatch")
+
+	_, err = fhks_bbs_plus.UnmarshalPartyPrivateKey(legacyBytes)
+	assert.Error(t, err, "raw key must not be accepted without envelope")
 }
 
 func TestPerPartyPreSignatureSerializationDeserialization(t *testing.T) {
 	preSigOriginal, err := randomPreSignature()
 	assert.NoError(t, err)
+	preSigOriginal.Epoch = 4
 
 	dataSerialized, err := preSigOriginal.ToBytes()
 	assert.NoError(t, err)
@@ -84,6 +100,7 @@ func TestPerPartyPreSignatureSerializationDeserialization(t *testing.T) {
 		preSigOriginal.AsTermOwn.Equal(preSigDeserialized.AsTermOwn), "AsTermOwn mismatch")
 	assert.True(t,
 		preSigOriginal.AskTermOwn.Equal(preSigDeserialized.AskTermOwn), "AskTermOwn mismatch")
+	assert.Equal(t, preSigOriginal.Epoch, preSigDeserialized.Epoch, "Epoch mismatch")
 
 	for i := range preSigOriginal.AeTermsA {
 		assert.True(t,
@@ -150,6 +167,30 @@ func TestSerializeDeserializeAeTermsA(t *testing.T) {
 	}
 }
 
+func TestPreSignatureCommitmentSerializationDeserialization(t *testing.T) {
+	preSig, err := randomPreSignature()
+	assert.NoError(t, err)
+
+	original := preSig.Commitment()
+	bytes, err := original.ToBytes()
+	assert.NoError(t, err)
+
+	deserialized, err := fhks_bbs_plus.PreSignatureCommitmentFromBytes(bytes)
+	assert.NoError(t, err)
+
+	g2 := bls12381.NewG2()
+	assert.True(t, bls12381.NewG1().Equal(original.AG1, deserialized.AG1), "AG1 mismatch")
+	assert.True(t, g2.Equal(original.A, deserialized.A), "A mismatch")
+	assert.True(t, g2.Equal(original.AskTermOwn, deserialized.AskTermOwn), "AskTermOwn mismatch")
+	assert.Equal(t, len(original.AskTermsSK), len(deserialized.AskTermsSK))
+	for i := range original.AskTermsSK {
+		assert.True(t, g2.Equal(original.AskTermsSK[i], deserialized.AskTermsSK[i]), "AskTermsSK mismatch")
+	}
+
+	_, err = fhks_bbs_plus.PreSignatureCommitmentFromBytes(bytes[:len(bytes)-1])
+	assert.Error(t, err)
+}
+
 func randomPreSignature() (*fhks_bbs_plus.PerPartyPreSignature, error) {
 	generateRandomFr := func() (*bls12381.Fr, error) {
 		fr := bls12381.NewFr()
@@ -0,0 +1,209 @@
+package fhks_bbs_plus
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	bls12381 "github.com/kilic/bls12-381"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
+)
+
+// Serialized signing artifacts are wrapped in an envelope:
+// magic (4 bytes) | version (uint8) | object type (uint8) | ID length (uint8) | ciphersuite ID | payload length (uint32)
+// | payload.
+// The ciphersuite ID is empty for objects that are not bound to a ciphersuite. Decoders reject envelopes of another
+// object type, of unknown versions or ciphersuites, with trailing bytes and payloads that are not exactly consumed.
+// The raw encodings that predate the envelope can be read with the explicit legacy functions.
+
+// ErrInvalidEncoding is wrapped by the errors of all decoders of this package for malformed, truncated or
+// non-canonical input.
+var ErrInvalidEncoding = helper.ErrInvalidEncoding
+
+// EncodingVersion is the version of the envelope and payload formats written by this package.
+const EncodingVersion = 1
+
+// encodingMagic identifies encodings of this package.
+var encodingMagic = []byte("TBBS")
+
+// ObjectType identifies the object in an envelope.
+type ObjectType uint8
+
+const (
+	ObjectPublicKey ObjectType = iota + 1
+	ObjectThresholdSignature
+	ObjectPartialThresholdSignature
+	ObjectPartySecretKey
+	ObjectPerPartyPreSignature
+)
+
+func (t ObjectType) String() string {
+	switch t {
+	case ObjectPublicKey:
+		return "public key"
+	case ObjectThresholdSignature:
+		return "threshold signature"
+	case ObjectPartialThresholdSignature:
+		return "partial threshold signature"
+	case ObjectPartySecretKey:
+		return "party secret key"
+	case ObjectPerPartyPreSignature:
+		return "per-party pre-signature"
+	default:
+		return fmt.Sprintf("unknown object type %d", uint8(t))
+	}
+}
+
+// encodeEnvelope wraps the payload of an object of type objectType.
+func encodeEnvelope(objectType ObjectType, ciphersuiteID string, payload []byte) ([]byte, error) {
+	if len(ciphersuiteID) > 255 {
+		return nil, errors.New("ciphersuite ID is too long")
+	}
+
+	ser := make([]byte, 0, len(encodingMagic)+3+len(ciphersuiteID)+helper.IntSize+len(payload))
+	ser = append(ser, encodingMagic...)
+	ser = append(ser, EncodingVersion, byte(objectType), byte(len(ciphersuiteID)))
+	ser = append(ser, ciphersuiteID...)
+	ser = binary.LittleEndian.AppendUint32(ser, uint32(len(payload)))
+	return append(ser, payload...), nil
+}
+
+// decodeEnvelope returns the ciphersuite ID and payload of an envelope holding an object of type objectType.
+func decodeEnvelope(objectType ObjectType, data []byte) (string, []byte, error) {
+	r := &encodingReader{data: data}
+	if !bytes.Equal(r.next(len(encodingMagic)), encodingMagic) {
+		return "", nil, fmt.Errorf("%w: missing magic number", ErrInvalidEncoding)
+	}
+	if version := r.uint8(); r.err == nil && version != EncodingVersion {
+		return "", nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidEncoding, version)
+	}
+	if t := ObjectType(r.uint8()); r.err == nil && t != objectType {
+		return "", nil, fmt.Errorf("%w: expected %s, got %s", ErrInvalidEncoding, objectType, t)
+	}
+	ciphersuiteID := string(r.next(int(r.uint8())))
+	payload := r.next(int(r.uint32()))
+	if err := r.finish(); err != nil {
+		return "", nil, err
+	}
+	if ciphersuiteID != "" {
+		if _, err := CiphersuiteByID(ciphersuiteID); err != nil {
+			return "", nil, fmt.Errorf("%w: %w", ErrInvalidEncoding, err)
+		}
+	}
+	return ciphersuiteID, payload, nil
+}
+
+// encodingReader reads the fields of an encoding. After the first error all reads return zero values and the error
+// is reported by finish.
+type encodingReader struct {
+	data []byte
+	err  error
+}
+
+func (r *encodingReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || len(r.data) < n {
+		r.err = fmt.Errorf("%w: unexpected end of data", ErrInvalidEncoding)
+		return nil
+	}
+	field := r.data[:n]
+	r.data = r.data[n:]
+	return field
+}
+
+func (r *encodingReader) uint8() uint8 {
+	field := r.next(1)
+	if field == nil {
+		return 0
+	}
+	return field[0]
+}
+
+func (r *encodingReader) uint32() uint32 {
+	field := r.next(helper.IntSize)
+	if field == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(field)
+}
+
+func (r *encodingReader) uint64() uint64 {
+	field := r.next(8)
+	if field == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(field)
+}
+
+// fr reads a canonically encoded scalar, i.e., one that is smaller than the group order.
+func (r *encodingReader) fr() *bls12381.Fr {
+	field := r.next(helper.LenBytesFr)
+	if field == nil {
+		return bls12381.NewFr().Zero()
+	}
+	fr, err := helper.FrFromCanonicalBytes(field)
+	if err != nil {
+		r.err = err
+		return bls12381.NewFr().Zero()
+	}
+	return fr
+}
+
+// frSlice reads a scalar slice prefixed with its length.
+func (r *encodingReader) frSlice() []*bls12381.Fr {
+	length := int(r.uint32())
+	if r.err == nil && length > len(r.data)/helper.LenBytesFr {
+		r.err = fmt.Errorf("%w: unexpected end of data", ErrInvalidEncoding)
+	}
+	if r.err != nil {
+		return nil
+	}
+	slice := make([]*bls12381.Fr, length)
+	for i := range slice {
+		slice[i] = r.fr()
+	}
+	return slice
+}
+
+func (r *encodingReader) g1() *bls12381.PointG1 {
+	g1 := bls12381.NewG1()
+	field := r.next(helper.LenBytesG1Compressed)
+	if field == nil {
+		return g1.Zero()
+	}
+	p, err := g1.FromCompressed(field)
+	if err != nil {
+		r.err = fmt.Errorf("%w: deserialize G1 point: %w", ErrInvalidEncoding, err)
+		return g1.Zero()
+	}
+	return p
+}
+
+func (r *encodingReader) g2() *bls12381.PointG2 {
+	g2 := bls12381.NewG2()
+	field := r.next(helper.LenBytesG2Compressed)
+	if field == nil {
+		return g2.Zero()
+	}
+	p, err := g2.FromCompressed(field)
+	if err != nil {
+		r.err = fmt.Errorf("%w: deserialize G2 point: %w", ErrInvalidEncoding, err)
+		return g2.Zero()
+	}
+	return p
+}
+
+// finish returns the first error of the reads or an error if not all data was read.
+func (r *encodingReader) finish() error {
+	if r.err != nil {
+		return r.err
+	}
+	if len(r.data) != 0 {
+		return fmt.Errorf("%w: %d trailing bytes", ErrInvalidEncoding, len(r.data))
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package fhks_bbs_plus_test
+
+import (
+	"testing"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
+)
+
+func TestPublicKeyEnvelope(t *testing.T) {
+	g1 := bls12381.NewG1()
+	g2 := bls12381.NewG2()
+
+	sk := &fhks_bbs_plus.SecretKey{Fr: fhks_bbs_plus.GenerateRandomFr()}
+	pk, err := fhks_bbs_plus.NewPublicKey(commitSecretKey(sk.Fr), fhks_bbs_plus.CiphersuiteBLS12381SHA256, []byte("seed"), 3)
+	require.NoError(t, err)
+
+	serialized := pk.Serialize()
+	deserialized, err := fhks_bbs_plus.DeserializePublicKey(serialized)
+	require.NoError(t, err)
+	assert.True(t, g2.Equal(pk.W, deserialized.W))
+	assert.True(t, g1.Equal(pk.H0, deserialized.H0))
+	require.Len(t, deserialized.H, len(pk.H))
+	for i := range pk.H {
+		assert.True(t, g1.Equal(pk.H[i], deserialized.H[i]), "H[%d] mismatch", i)
+	}
+	assert.Equal(t, pk.CiphersuiteID, deserialized.CiphersuiteID)
+	assert.Equal(t, pk.GeneratorSeed, deserialized.GeneratorSeed)
+
+	// Trailing bytes, truncations and other object types are rejected.
+	_, err = fhks_bbs_plus.DeserializePublicKey(append(serialized, make([]byte, 48)...))
+	assert.Error(t, err)
+	_, err = fhks_bbs_plus.DeserializePublicKey(serialized[:len(serialized)-1])
+	assert.Error(t, err)
+	signature, err := sk.Sign(*pk, randomMessages(3), fhks_bbs_plus.GenerateRandomFr(), fhks_bbs_plus.GenerateRandomFr()).ToBytes()
+	require.NoError(t, err)
+	_, err = fhks_bbs_plus.DeserializePublicKey(signature)
+	assert.Error(t, err)
+
+	// Unknown versions and magic numbers are rejected.
+	future := append([]byte{}, serialized...)
+	future[4] = fhks_bbs_plus.EncodingVersion + 1
+	_, err = fhks_bbs_plus.DeserializePublicKey(future)
+	assert.Error(t, err)
+	corrupted := append([]byte{}, serialized...)
+	corrupted[0] ^= 1
+	_, err = fhks_bbs_plus.DeserializePublicKey(corrupted)
+	assert.Error(t, err)
+}
+
+func TestPublicKeyLegacy(t *testing.T) {
+	g1 := bls12381.NewG1()
+	g2 := bls12381.NewG2()
+
+	sk := &fhks_bbs_plus.SecretKey{Fr: fhks_bbs_plus.GenerateRandomFr()}
+	pk := sk.GetPublicKey(2)
+	legacy := append(g2.ToCompressed(pk.W), g1.ToCompressed(pk.H0)...)
+	for _, h := range pk.H {
+		legacy = append(legacy, g1.ToCompressed(h)...)
+	}
+
+	deserialized, err := fhks_bbs_plus.DeserializePublicKeyLegacy(legacy)
+	require.NoError(t, err)
+	require.Len(t, deserialized.H, 2)
+	assert.True(t, g1.Equal(pk.H[1], deserialized.H[1]))
+
+	_, err = fhks_bbs_plus.DeserializePublicKeyLegacy(append(legacy, 0))
+	assert.Error(t, err)
+	_, err = fhks_bbs_plus.DeserializePublicKey(legacy)
+	assert.Error(t, err)
+}
+
+func TestThresholdSignatureLegacy(t *testing.T) {
+	sk := &fhks_bbs_plus.SecretKey{Fr: fhks_bbs_plus.GenerateRandomFr()}
+	pk := sk.GetPublicKey(2)
+	messages := randomMessages(2)
+	signature := sk.Sign(*pk, messages, fhks_bbs_plus.GenerateRandomFr(), fhks_bbs_plus.GenerateRandomFr())
+
+	legacy := append(bls12381.NewG1().ToCompressed(signature.CapitalA), signature.E.ToBytes()...)
+	legacy = append(legacy, signature.S.ToBytes()...)
+	deserialized, err := fhks_bbs_plus.ThresholdSignatureFromLegacyBytes(legacy)
+	require.NoError(t, err)
+	assert.True(t, pk.Verify(messages, deserialized))
+
+	// Scalars must be reduced.
+	unreduced := append([]byte{}, legacy...)
+	for i := len(unreduced) - 32; i < len(unreduced); i++ {
+		unreduced[i] = 0xff
+	}
+	_, err = fhks_bbs_plus.ThresholdSignatureFromLegacyBytes(unreduced)
+	assert.Error(t, err)
+}
+
+func randomMessages(count int) []*bls12381.Fr {
+	messages := make([]*bls12381.Fr, count)
+	for i := range messages {
+		messages[i] = fhks_bbs_plus.GenerateRandomFr()
+	}
+	return messages
+}
@@ -0,0 +1,167 @@
+package fhks_bbs_plus_test
+
+import (
+	"bytes"
+	"testing"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/stretchr/testify/require"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
+)
+
+// The fuzz targets check that the decoders never panic and only accept the canonical encoding, i.e., that every
+// accepted input is re-encoded to itself.
+
+func FuzzPartThreshSigFromBytes(f *testing.F) {
+	pts := &fhks_bbs_plus.PartialThresholdSignature{
+		CapitalAShare: bls12381.NewG1().One(),
+		DeltaShare:    fhks_bbs_plus.GenerateRandomFr(),
+		EShare:        fhks_bbs_plus.GenerateRandomFr(),
+		SShare:        fhks_bbs_plus.GenerateRandomFr(),
+	}
+	data, err := pts.ToBytes()
+	require.NoError(f, err)
+	f.Add(data)
+	f.Add(data[:len(data)-1])
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		pts, err := fhks_bbs_plus.PartThreshSigFromBytes(data)
+		if err != nil {
+			require.ErrorIs(t, err, fhks_bbs_plus.ErrInvalidEncoding)
+			return
+		}
+		encoded, err := pts.ToBytes()
+		require.NoError(t, err)
+		require.True(t, bytes.Equal(data, encoded))
+	})
+}
+
+func FuzzThresholdSignatureFromBytes(f *testing.F) {
+	sk := &fhks_bbs_plus.SecretKey{Fr: fhks_bbs_plus.GenerateRandomFr()}
+	signature := sk.Sign(*sk.GetPublicKey(2), randomMessages(2), fhks_bbs_plus.GenerateRandomFr(), fhks_bbs_plus.GenerateRandomFr())
+	data, err := signature.ToBytes()
+	require.NoError(f, err)
+	f.Add(data)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		signature, err := fhks_bbs_plus.ThresholdSignatureFromBytes(data)
+		if err != nil {
+			require.ErrorIs(t, err, fhks_bbs_plus.ErrInvalidEncoding)
+			return
+		}
+		encoded, err := signature.ToBytes()
+		require.NoError(t, err)
+		require.True(t, bytes.Equal(data, encoded))
+	})
+}
+
+func FuzzIETFSignatureFromBytes(f *testing.F) {
+	sk := &fhks_bbs_plus.SecretKey{Fr: fhks_bbs_plus.GenerateRandomFr()}
+	signature, err := sk.SignIETF(sk.GetPublicKey(2), nil, randomMessages(2))
+	require.NoError(f, err)
+	f.Add(signature.ToBytes())
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		signature, err := fhks_bbs_plus.IETFSignatureFromBytes(data)
+		if err != nil {
+			require.ErrorIs(t, err, fhks_bbs_plus.ErrInvalidEncoding)
+			return
+		}
+		require.True(t, bytes.Equal(data, signature.ToBytes()))
+	})
+}
+
+func FuzzPerPartyPreSignatureFromBytes(f *testing.F) {
+	preSignature, err := randomPreSignature()
+	require.NoError(f, err)
+	data, err := preSignature.ToBytes()
+	require.NoError(f, err)
+	f.Add(data)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		preSignature, _, err := fhks_bbs_plus.FromBytes(data)
+		if err != nil {
+			require.ErrorIs(t, err, fhks_bbs_plus.ErrInvalidEncoding)
+			return
+		}
+		encoded, err := preSignature.ToBytes()
+		require.NoError(t, err)
+		require.True(t, bytes.Equal(data, encoded))
+	})
+}
+
+func FuzzPreSignatureCommitmentFromBytes(f *testing.F) {
+	preSignature, err := randomPreSignature()
+	require.NoError(f, err)
+	data, err := preSignature.Commitment().ToBytes()
+	require.NoError(f, err)
+	f.Add(data)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		commitment, err := fhks_bbs_plus.PreSignatureCommitmentFromBytes(data)
+		if err != nil {
+			require.ErrorIs(t, err, fhks_bbs_plus.ErrInvalidEncoding)
+			return
+		}
+		encoded, err := commitment.ToBytes()
+		require.NoError(t, err)
+		require.True(t, bytes.Equal(data, encoded))
+	})
+}
+
+func FuzzUnmarshalPartyPrivateKey(f *testing.F) {
+	ppk := &fhks_bbs_plus.PartySecretKey{
+		SKeyShare: fhks_bbs_plus.SecretKey{Fr: fhks_bbs_plus.GenerateRandomFr()},
+		PublicKey: bls12381.NewG2().One(),
+		Index:     2,
+		Epoch:     1,
+	}
+	data, err := ppk.Marshal()
+	require.NoError(f, err)
+	f.Add(data)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ppk, err := fhks_bbs_plus.UnmarshalPartyPrivateKey(data)
+		if err != nil {
+			require.ErrorIs(t, err, fhks_bbs_plus.ErrInvalidEncoding)
+			return
+		}
+		encoded, err := ppk.Marshal()
+		require.NoError(t, err)
+		require.True(t, bytes.Equal(data, encoded))
+	})
+}
+
+func FuzzDeserializePublicKey(f *testing.F) {
+	pk, err := fhks_bbs_plus.NewPublicKey(bls12381.NewG2().One(), fhks_bbs_plus.CiphersuiteBLS12381SHA256, []byte("seed"), 2)
+	require.NoError(f, err)
+	f.Add(pk.Serialize())
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		pk, err := fhks_bbs_plus.DeserializePublicKey(data)
+		if err != nil {
+			require.ErrorIs(t, err, fhks_bbs_plus.ErrInvalidEncoding)
+			return
+		}
+		require.True(t, bytes.Equal(data, pk.Serialize()))
+	})
+}
+
+func FuzzUnmarshalShareCommitments(f *testing.F) {
+	commitments := fhks_bbs_plus.ShareCommitments{bls12381.NewG2().One(), commitSecretKey(fhks_bbs_plus.GenerateRandomFr())}
+	data, err := commitments.Marshal()
+	require.NoError(f, err)
+	f.Add(data)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		commitments, err := fhks_bbs_plus.UnmarshalShareCommitments(data)
+		if err != nil {
+			require.ErrorIs(t, err, fhks_bbs_plus.ErrInvalidEncoding)
+			return
+		}
+		encoded, err := commitments.Marshal()
+		require.NoError(t, err)
+		require.True(t, bytes.Equal(data, encoded))
+	})
+}
@@ -0,0 +1,118 @@
+package fhks_bbs_plus
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
+)
+
+const (
+	// CiphersuiteBLS12381SHA256 is the identifier of the BLS12-381-SHA-256 ciphersuite of the IETF BBS draft.
+	CiphersuiteBLS12381SHA256 = "BBS_BLS12381G1_XMD:SHA-256_SSWU_RO_"
+
+	// CiphersuiteBLS12381SHAKE256 is the identifier of the BLS12-381-SHAKE-256 ciphersuite of the IETF BBS draft.
+	CiphersuiteBLS12381SHAKE256 = "BBS_BLS12381G1_XOF:SHAKE-256_SSWU_RO_"
+
+	// apiIDSuffix is appended to the ciphersuite identifier to form the api_id of the hash-to-messages interface.
+	apiIDSuffix = "H2G_HM2S_"
+
+	// expandLen is the output length of expand_message in create_generators.
+	expandLen = 48
+)
+
+// Ciphersuite bundles the hash functions a public key derives its generators with.
+type Ciphersuite struct {
+	ID            string
+	ExpandMessage func(msg, dst []byte, outLen int) ([]byte, error)
+	HashToCurveG1 func(msg, dst []byte) (*bls12381.PointG1, error)
+}
+
+// BLS12381SHA256 is the BLS12-381-SHA-256 ciphersuite, using expand_message_xmd and hash_to_curve
+// with BLS12381G1_XMD:SHA-256_SSWU_RO_.
+var BLS12381SHA256 = &Ciphersuite{
+	ID:            CiphersuiteBLS12381SHA256,
+	ExpandMessage: helper.ExpandMessageXMD,
+	HashToCurveG1: func(msg, dst []byte) (*bls12381.PointG1, error) {
+		return bls12381.NewG1().HashToCurve(msg, dst)
+	},
+}
+
+// BLS12381SHAKE256 is the BLS12-381-SHAKE-256 ciphersuite, using expand_message_xof and hash_to_curve
+// with BLS12381G1_XOF:SHAKE-256_SSWU_RO_.
+var BLS12381SHAKE256 = &Ciphersuite{
+	ID:            CiphersuiteBLS12381SHAKE256,
+	ExpandMessage: helper.ExpandMessageXOF,
+	HashToCurveG1: helper.HashToCurveG1XOF,
+}
+
+var ciphersuites = map[string]*Ciphersuite{
+	CiphersuiteBLS12381SHA256:   BLS12381SHA256,
+	CiphersuiteBLS12381SHAKE256: BLS12381SHAKE256,
+}
+
+// CiphersuiteByID returns the ciphersuite with the given identifier.
+func CiphersuiteByID(id string) (*Ciphersuite, error) {
+	cs, ok := ciphersuites[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown ciphersuite %q", id)
+	}
+	return cs, nil
+}
+
+// APIID returns the api_id of the ciphersuite, which prefixes all its domain separation tags.
+func (cs *Ciphersuite) APIID() []byte {
+	return []byte(cs.ID + apiIDSuffix)
+}
+
+// DefaultGeneratorSeed returns the generator seed used if no custom seed is given.
+func (cs *Ciphersuite) DefaultGeneratorSeed() []byte {
+	return append(cs.APIID(), []byte("MESSAGE_GENERATOR_SEED")...)
+}
+
+// P1 returns the base point of the signatures, the single generator derived from the seed
+// api_id || "BP_MESSAGE_GENERATOR_SEED".
+func (cs *Ciphersuite) P1() (*bls12381.PointG1, error) {
+	generators, err := cs.CreateGenerators(1, append(cs.APIID(), []byte("BP_MESSAGE_GENERATOR_SEED")...))
+	if err != nil {
+		return nil, err
+	}
+	return generators[0], nil
+}
+
+// CreateGenerators implements create_generators of the IETF BBS draft. It derives count points of G1 by
+// hashing to the curve, so nobody knows their discrete logarithms. If generatorSeed is nil, the default
+// seed of the ciphersuite is used.
+func (cs *Ciphersuite) CreateGenerators(count int, generatorSeed []byte) ([]*bls12381.PointG1, error) {
+	if count < 0 {
+		return nil, fmt.Errorf("invalid number of generators %d", count)
+	}
+	if generatorSeed == nil {
+		generatorSeed = cs.DefaultGeneratorSeed()
+	}
+
+	apiID := cs.APIID()
+	seedDST := append(append([]byte{}, apiID...), []byte("SIG_GENERATOR_SEED_")...)
+	generatorDST := append(append([]byte{}, apiID...), []byte("SIG_GENERATOR_DST_")...)
+
+	v, err := cs.ExpandMessage(generatorSeed, seedDST, expandLen)
+	if err != nil {
+		return nil, fmt.Errorf("expand generator seed: %w", err)
+	}
+
+	generators := make([]*bls12381.PointG1, count)
+	for i := 1; i <= count; i++ {
+		counter := make([]byte, 8)
+		binary.BigEndian.PutUint64(counter, uint64(i))
+		v, err = cs.ExpandMessage(append(v, counter...), seedDST, expandLen)
+		if err != nil {
+			return nil, fmt.Errorf("expand generator seed: %w", err)
+		}
+		generators[i-1], err = cs.HashToCurveG1(v, generatorDST)
+		if err != nil {
+			return nil, fmt.Errorf("hash to curve: %w", err)
+		}
+	}
+	return generators, nil
+}
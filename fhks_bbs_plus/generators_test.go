@@ -0,0 +1,85 @@
+package fhks_bbs_plus_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
+	"github.com/perun-network/bbs-plus-threshold-wallet/test"
+)
+
+// Base points and generators of both ciphersuites from the test vectors of the IETF BBS draft.
+var generatorVectors = []struct {
+	ciphersuite *fhks_bbs_plus.Ciphersuite
+	p1          string
+	generators  []string
+}{
+	{
+		ciphersuite: fhks_bbs_plus.BLS12381SHA256,
+		p1:          "a8ce256102840821a3e94ea9025e4662b205762f9776b3a766c872b948f1fd225e7c59698588e70d11406d161b4e28c9",
+		generators: []string{
+			"a9ec65b70a7fbe40c874c9eb041c2cb0a7af36ccec1bea48fa2ba4c2eb67ef7f9ecb17ed27d38d27cdeddff44c8137be",
+			"98cd5313283aaf5db1b3ba8611fe6070d19e605de4078c38df36019fbaad0bd28dd090fd24ed27f7f4d22d5ff5dea7d4",
+			"a31fbe20c5c135bcaa8d9fc4e4ac665cc6db0226f35e737507e803044093f37697a9d452490a970eea6f9ad6c3dcaa3a",
+		},
+	},
+	{
+		ciphersuite: fhks_bbs_plus.BLS12381SHAKE256,
+		p1:          "8929dfbc7e6642c4ed9cba0856e493f8b9d7d5fcb0c31ef8fdcd34d50648a56c795e106e9eada6e0bda386b414150755",
+		generators: []string{
+			"a9d40131066399fd41af51d883f4473b0dcd7d028d3d34ef17f3241d204e28507d7ecae032afa1d5490849b7678ec1f8",
+			"903c7ca0b7e78a2017d0baf74103bd00ca8ff9bf429f834f071c75ffe6bfdec6d6dca15417e4ac08ca4ae1e78b7adc0e",
+			"84321f5855bfb6b001f0dfcb47ac9b5cc68f1a4edd20f0ec850e0563b27d2accee6edff1a26b357762fb24e8ddbb6fcb",
+		},
+	},
+}
+
+func TestCreateGeneratorsTestVectors(t *testing.T) {
+	g1 := bls12381.NewG1()
+	for _, vector := range generatorVectors {
+		p1, err := vector.ciphersuite.P1()
+		assert.NoError(t, err)
+		assert.Equal(t, vector.p1, hex.EncodeToString(g1.ToCompressed(p1)), "%s: P1 mismatch", vector.ciphersuite.ID)
+
+		generators, err := vector.ciphersuite.CreateGenerators(len(vector.generators), nil)
+		assert.NoError(t, err)
+		for i, expected := range vector.generators {
+			assert.Equal(t, expected, hex.EncodeToString(g1.ToCompressed(generators[i])), "%s: generator %d mismatch", vector.ciphersuite.ID, i)
+		}
+	}
+}
+
+func TestGetPublicKeySignVerify(t *testing.T) {
+	sk := fhks_bbs_plus.SecretKey{Fr: fhks_bbs_plus.GenerateRandomFr()}
+	pk := sk.GetPublicKey(test.MessageCount)
+
+	messages := make([]*bls12381.Fr, test.MessageCount)
+	for i := range messages {
+		messages[i] = fhks_bbs_plus.GenerateRandomFr()
+	}
+
+	signature := sk.Sign(*pk, messages, fhks_bbs_plus.GenerateRandomFr(), fhks_bbs_plus.GenerateRandomFr())
+	assert.True(t, pk.Verify(messages, signature), "signature verification failed")
+}
+
+func TestCompactPublicKeySerializationDeserialization(t *testing.T) {
+	sk := fhks_bbs_plus.GenerateRandomFr()
+
+	for _, pk := range []*fhks_bbs_plus.PublicKey{
+		(&fhks_bbs_plus.SecretKey{Fr: sk}).GetPublicKey(test.MessageCount),
+		fhks_bbs_plus.GeneratePublicKey(test.SeedKeys, sk, test.MessageCount),
+	} {
+		bytes, err := pk.SerializeCompact()
+		assert.NoError(t, err)
+
+		deserialized, err := fhks_bbs_plus.DeserializeCompactPublicKey(bytes)
+		assert.NoError(t, err)
+		assert.Equal(t, pk.Serialize(), deserialized.Serialize())
+
+		_, err = fhks_bbs_plus.DeserializeCompactPublicKey(bytes[:len(bytes)-1])
+		assert.Error(t, err)
+	}
+}
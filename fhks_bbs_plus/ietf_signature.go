@@ -0,0 +1,212 @@
+package fhks_bbs_plus
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
+)
+
+// LenBytesIETFSignature is the length of a signature encoded with IETFSignature.ToBytes.
+const LenBytesIETFSignature = helper.LenBytesG1Compressed + helper.LenBytesFr
+
+// IETFSignature is a BBS signature (A, e) as defined by the IETF BBS draft. It differs from a BBS+ signature by the
+// missing s, the basis is P1 + Q_1 * domain + H_1 * msg_1 + ... + H_L * msg_L.
+type IETFSignature struct {
+	CapitalA *bls12381.PointG1
+	E        *bls12381.Fr
+}
+
+// HashToScalar implements hash_to_scalar of the IETF BBS draft.
+func (cs *Ciphersuite) HashToScalar(msg, dst []byte) (*bls12381.Fr, error) {
+	uniformBytes, err := cs.ExpandMessage(msg, dst, expandLen)
+	if err != nil {
+		return nil, fmt.Errorf("expand message: %w", err)
+	}
+	scalar := new(big.Int).SetBytes(uniformBytes)
+	scalar.Mod(scalar, bls12381.NewG1().Q())
+	return bls12381.NewFr().FromBytes(scalar.Bytes()), nil
+}
+
+// MessagesToScalars implements messages_to_scalars of the IETF BBS draft, mapping every message to a scalar with
+// hash_to_scalar.
+func (cs *Ciphersuite) MessagesToScalars(messages [][]byte) ([]*bls12381.Fr, error) {
+	dst := append(cs.APIID(), []byte("MAP_MSG_TO_SCALAR_AS_HASH_")...)
+
+	scalars := make([]*bls12381.Fr, len(messages))
+	for i, message := range messages {
+		scalar, err := cs.HashToScalar(message, dst)
+		if err != nil {
+			return nil, fmt.Errorf("map message %d to scalar: %w", i, err)
+		}
+		scalars[i] = scalar
+	}
+	return scalars, nil
+}
+
+// CalculateDomain implements calculate_domain of the IETF BBS draft. It binds the signature to the public key, its
+// generators and the header.
+func (cs *Ciphersuite) CalculateDomain(pk *PublicKey, header []byte) (*bls12381.Fr, error) {
+	g1 := bls12381.NewG1()
+
+	domInput := bls12381.NewG2().ToCompressed(pk.W)
+	domInput = binary.BigEndian.AppendUint64(domInput, uint64(len(pk.H)))
+	domInput = append(domInput, g1.ToCompressed(pk.H0)...)
+	for _, h := range pk.H {
+		domInput = append(domInput, g1.ToCompressed(h)...)
+	}
+	domInput = append(domInput, cs.APIID()...)
+	domInput = binary.BigEndian.AppendUint64(domInput, uint64(len(header)))
+	domInput = append(domInput, header...)
+
+	return cs.HashToScalar(domInput, cs.HashToScalarDST())
+}
+
+// HashToScalarDST returns the domain separation tag hash_to_scalar is called with for the domain, e and
+// the challenge of a proof.
+func (cs *Ciphersuite) HashToScalarDST() []byte {
+	return append(cs.APIID(), []byte("H2S_")...)
+}
+
+// IETFCiphersuite returns the ciphersuite of the public key if its generators are the ones the IETF BBS draft uses,
+// i.e., they are derived with the default seed of the ciphersuite.
+func (pk *PublicKey) IETFCiphersuite() (*Ciphersuite, error) {
+	if pk.CiphersuiteID == "" {
+		return nil, errors.New("public key has no ciphersuite")
+	}
+	if pk.GeneratorSeed != nil {
+		return nil, errors.New("public key generators are not derived from the default seed")
+	}
+	return CiphersuiteByID(pk.CiphersuiteID)
+}
+
+// IETFBasis returns the domain and the basis B = P1 + Q_1 * domain + H_1 * msg_1 + ... + H_L * msg_L that
+// IETF BBS signatures on messages and header under pk are computed on.
+func (pk *PublicKey) IETFBasis(header []byte, messages []*bls12381.Fr) (*bls12381.Fr, *bls12381.PointG1, error) {
+	if len(messages) != len(pk.H) {
+		return nil, nil, fmt.Errorf("expected %d messages, got %d", len(pk.H), len(messages))
+	}
+
+	cs, err := pk.IETFCiphersuite()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	domain, err := cs.CalculateDomain(pk, header)
+	if err != nil {
+		return nil, nil, fmt.Errorf("calculate domain: %w", err)
+	}
+
+	p1, err := cs.P1()
+	if err != nil {
+		return nil, nil, fmt.Errorf("create base point: %w", err)
+	}
+
+	g1 := bls12381.NewG1()
+	basis := g1.New()
+	g1.MulScalar(basis, pk.H0, domain)
+	g1.Add(basis, basis, p1)
+	for i, message := range messages {
+		tmp := g1.New()
+		g1.MulScalar(tmp, pk.H[i], message)
+		g1.Add(basis, basis, tmp)
+	}
+
+	return domain, basis, nil
+}
+
+// SignIETF computes the IETF BBS signature on messages and header, deriving e deterministically as the draft's Sign.
+func (sk *SecretKey) SignIETF(pk *PublicKey, header []byte, messages []*bls12381.Fr) (*IETFSignature, error) {
+	domain, basis, err := pk.IETFBasis(header, messages)
+	if err != nil {
+		return nil, err
+	}
+	cs, _ := pk.IETFCiphersuite()
+
+	eInput := sk.Fr.ToBytes()
+	for _, message := range messages {
+		eInput = append(eInput, message.ToBytes()...)
+	}
+	eInput = append(eInput, domain.ToBytes()...)
+	e, err := cs.HashToScalar(eInput, cs.HashToScalarDST())
+	if err != nil {
+		return nil, fmt.Errorf("hash to e: %w", err)
+	}
+
+	ske := bls12381.NewFr()
+	ske.Add(sk.Fr, e)
+	expo := bls12381.NewFr()
+	expo.Inverse(ske)
+
+	g1 := bls12381.NewG1()
+	capitalA := g1.New()
+	g1.MulScalar(capitalA, basis, expo)
+
+	return &IETFSignature{CapitalA: capitalA, E: e}, nil
+}
+
+// VerifyIETF implements CoreVerify of the IETF BBS draft.
+func (pk *PublicKey) VerifyIETF(header []byte, messages []*bls12381.Fr, signature *IETFSignature) error {
+	_, basis, err := pk.IETFBasis(header, messages)
+	if err != nil {
+		return err
+	}
+
+	// Check e(A, W + g_2^e) == e(B, g_2).
+	g2 := bls12381.NewG2()
+	u := g2.New()
+	g2.MulScalar(u, g2.One(), signature.E)
+	g2.Add(u, u, pk.W)
+
+	engine := bls12381.NewEngine()
+	engine.AddPair(signature.CapitalA, u)
+	engine.AddPairInv(basis, g2.One())
+	if !engine.Check() {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// ToBytes implements signature_to_octets of the IETF BBS draft.
+func (s *IETFSignature) ToBytes() []byte {
+	return append(bls12381.NewG1().ToCompressed(s.CapitalA), s.E.ToBytes()...)
+}
+
+// IETFSignatureFromBytes implements octets_to_signature of the IETF BBS draft.
+func IETFSignatureFromBytes(data []byte) (*IETFSignature, error) {
+	if len(data) != LenBytesIETFSignature {
+		return nil, fmt.Errorf("%w: invalid serialized signature length: expected %d, got %d", ErrInvalidEncoding, LenBytesIETFSignature, len(data))
+	}
+
+	g1 := bls12381.NewG1()
+	capitalA, err := g1.FromCompressed(data[:helper.LenBytesG1Compressed])
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to deserialize CapitalA: %w", ErrInvalidEncoding, err)
+	}
+	if g1.IsZero(capitalA) {
+		return nil, fmt.Errorf("%w: CapitalA is the identity", ErrInvalidEncoding)
+	}
+
+	e, err := ScalarFromBytes(data[helper.LenBytesG1Compressed:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize e: %w", err)
+	}
+
+	return &IETFSignature{CapitalA: capitalA, E: e}, nil
+}
+
+// ScalarFromBytes decodes a non-zero scalar in canonical big-endian encoding, as required for scalars in IETF BBS
+// signatures and proofs.
+func ScalarFromBytes(data []byte) (*bls12381.Fr, error) {
+	scalar, err := helper.FrFromCanonicalBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	if scalar.IsZero() {
+		return nil, fmt.Errorf("%w: scalar is zero", ErrInvalidEncoding)
+	}
+	return scalar, nil
+}
@@ -0,0 +1,164 @@
+package fhks_bbs_plus_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
+	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation"
+	"github.com/perun-network/bbs-plus-threshold-wallet/test"
+)
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	assert.NoError(t, err)
+	return b
+}
+
+func ietfPublicKey(sk *bls12381.Fr, ciphersuiteID string, messageCount int) (*fhks_bbs_plus.PublicKey, error) {
+	g2 := bls12381.NewG2()
+	w := g2.New()
+	g2.MulScalar(w, g2.One(), sk)
+	return fhks_bbs_plus.NewPublicKey(w, ciphersuiteID, nil, messageCount)
+}
+
+func TestIETFSignatureTestVectors(t *testing.T) {
+	for _, vector := range test.IETFVectors {
+		sk := fhks_bbs_plus.SecretKey{Fr: bls12381.NewFr().FromBytes(mustDecodeHex(t, vector.SecretKey))}
+		pk, err := ietfPublicKey(sk.Fr, vector.CiphersuiteID, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, vector.PublicKey, hex.EncodeToString(bls12381.NewG2().ToCompressed(pk.W)), "%s: public key mismatch", vector.CiphersuiteID)
+
+		cs, err := fhks_bbs_plus.CiphersuiteByID(vector.CiphersuiteID)
+		assert.NoError(t, err)
+		messages, err := cs.MessagesToScalars([][]byte{mustDecodeHex(t, vector.Message)})
+		assert.NoError(t, err)
+		header := mustDecodeHex(t, vector.Header)
+
+		signature, err := sk.SignIETF(pk, header, messages)
+		assert.NoError(t, err)
+		assert.Equal(t, vector.Signature, hex.EncodeToString(signature.ToBytes()), "%s: signature mismatch", vector.CiphersuiteID)
+
+		decoded, err := fhks_bbs_plus.IETFSignatureFromBytes(mustDecodeHex(t, vector.Signature))
+		assert.NoError(t, err)
+		assert.NoError(t, pk.VerifyIETF(header, messages, decoded))
+		assert.Error(t, pk.VerifyIETF(nil, messages, decoded), "%s: signature verified with wrong header", vector.CiphersuiteID)
+	}
+}
+
+func TestIETFMultiMessageSignatureTestVectors(t *testing.T) {
+	messageBytes := make([][]byte, len(test.IETFMessages))
+	for i, message := range test.IETFMessages {
+		messageBytes[i] = mustDecodeHex(t, message)
+	}
+	for i, vector := range test.IETFMultiMessageVectors {
+		keyVector := test.IETFVectors[i]
+		assert.Equal(t, keyVector.CiphersuiteID, vector.CiphersuiteID)
+
+		sk := fhks_bbs_plus.SecretKey{Fr: bls12381.NewFr().FromBytes(mustDecodeHex(t, keyVector.SecretKey))}
+		pk, err := ietfPublicKey(sk.Fr, vector.CiphersuiteID, len(messageBytes))
+		assert.NoError(t, err)
+
+		cs, err := fhks_bbs_plus.CiphersuiteByID(vector.CiphersuiteID)
+		assert.NoError(t, err)
+		messages, err := cs.MessagesToScalars(messageBytes)
+		assert.NoError(t, err)
+		header := mustDecodeHex(t, keyVector.Header)
+
+		signature, err := sk.SignIETF(pk, header, messages)
+		assert.NoError(t, err)
+		assert.Equal(t, vector.Signature, hex.EncodeToString(signature.ToBytes()), "%s: signature mismatch", vector.CiphersuiteID)
+		assert.NoError(t, pk.VerifyIETF(header, messages, signature))
+		assert.Error(t, pk.VerifyIETF(header, messages[:len(messages)-1], signature), "%s: signature verified with fewer messages", vector.CiphersuiteID)
+	}
+}
+
+func TestIETFSignatureFromBytesInvalid(t *testing.T) {
+	valid := mustDecodeHex(t, test.IETFVectors[0].Signature)
+
+	_, err := fhks_bbs_plus.IETFSignatureFromBytes(valid[:len(valid)-1])
+	assert.Error(t, err)
+
+	zeroE := append([]byte{}, valid...)
+	copy(zeroE[helper.LenBytesG1Compressed:], make([]byte, helper.LenBytesFr))
+	_, err = fhks_bbs_plus.IETFSignatureFromBytes(zeroE)
+	assert.Error(t, err)
+}
+
+func TestIETFSigningMockedPre(t *testing.T) {
+	messages := helper.GetRandomMessagesFromSeed(test.SeedMessages, test.K, test.MessageCount)
+	header := []byte("header")
+
+	sk, preComputation := precomputation.GeneratePPPrecomputationMock(test.SeedPresignatures, test.Threshold, test.K, test.N)
+
+	pk, err := ietfPublicKey(sk, fhks_bbs_plus.CiphersuiteBLS12381SHA256, test.MessageCount)
+	assert.NoError(t, err)
+
+	for iK := 0; iK < test.K; iK++ {
+		partialSignatures := make([]*fhks_bbs_plus.PartialBBSSignature, test.Threshold)
+		for iT := 0; iT < test.Threshold; iT++ {
+			ownIndex := test.Indices[iK][iT]
+			partialSignatures[iT], err = fhks_bbs_plus.NewPartialBBSSignature().New(
+				messages[iK],
+				pk,
+				header,
+				partySecretKey(preComputation, ownIndex),
+				fhks_bbs_plus.NewLivePreSignature().FromPreSignature(
+					ownIndex,
+					test.Indices[iK],
+					preComputation[ownIndex-1].PreSignatures[iK],
+				).BBS(),
+			)
+			assert.NoError(t, err)
+		}
+		signature := fhks_bbs_plus.NewIETFSignature().FromPartialSignatures(partialSignatures)
+
+		decoded, err := fhks_bbs_plus.IETFSignatureFromBytes(signature.ToBytes())
+		assert.NoError(t, err)
+		assert.NoError(t, pk.VerifyIETF(header, messages[iK], decoded), "signature verification failed")
+	}
+}
+
+func TestThresholdBBSSigning(t *testing.T) {
+	header := []byte("header")
+
+	for _, tc := range []struct {
+		name string
+		gen  func() (*bls12381.Fr, [][]*fhks_bbs_plus.LiveBBSPreSignature)
+	}{
+		{"tau-out-of-n", func() (*bls12381.Fr, [][]*fhks_bbs_plus.LiveBBSPreSignature) {
+			sk, _, preSignatures := precomputation.GeneratePPPrecomputationBBSTauOutOfN(2, test.K, 4)
+			return sk, preSignatures
+		}},
+		{"n-out-of-n", func() (*bls12381.Fr, [][]*fhks_bbs_plus.LiveBBSPreSignature) {
+			sk, _, preSignatures := precomputation.GeneratePPPrecomputationBBSNOutOfN(2, test.K, 2)
+			return sk, preSignatures
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			messages := helper.GetRandomMessagesFromSeed(test.SeedMessages, test.K, test.MessageCount)
+			sk, preSignatures := tc.gen()
+
+			pk, err := ietfPublicKey(sk, fhks_bbs_plus.CiphersuiteBLS12381SHA256, test.MessageCount)
+			assert.NoError(t, err)
+
+			for iK := 0; iK < test.K; iK++ {
+				partialSignatures := make([]*fhks_bbs_plus.PartialBBSSignature, len(preSignatures[iK]))
+				for i, preSignature := range preSignatures[iK] {
+					keyShare := &fhks_bbs_plus.PartySecretKey{Index: i + 1}
+					partialSignatures[i], err = fhks_bbs_plus.NewPartialBBSSignature().New(messages[iK], pk, header, keyShare, preSignature)
+					assert.NoError(t, err)
+				}
+				signature := fhks_bbs_plus.NewIETFSignature().FromPartialSignatures(partialSignatures)
+
+				decoded, err := fhks_bbs_plus.IETFSignatureFromBytes(signature.ToBytes())
+				assert.NoError(t, err)
+				assert.NoError(t, pk.VerifyIETF(header, messages[iK], decoded), "signature verification failed")
+			}
+		})
+	}
+}
@@ -25,77 +25,165 @@ func (sk *SecretKey) Deserialize(serialized []byte) error {
 	return nil
 }
 
+// ErrEpochMismatch is returned for key material from a different epoch than the secret key share.
+var ErrEpochMismatch = errors.New("key epoch mismatch")
+
 type PartySecretKey struct {
 	SKeyShare SecretKey
 	PublicKey *bls12381.PointG2
 	Index     int
+	Epoch     uint64 // Number of share refreshes since the key was generated.
+}
+
+// CheckEpoch returns ErrEpochMismatch if material tagged with epoch, e.g., a pre-signature or PCG seed, was derived
+// from a secret key share of another epoch than ppk. Such material must not be used together with ppk.
+func (ppk *PartySecretKey) CheckEpoch(epoch uint64) error {
+	if epoch != ppk.Epoch {
+		return fmt.Errorf("%w: expected epoch %d, got %d", ErrEpochMismatch, ppk.Epoch, epoch)
+	}
+	return nil
+}
+
+// EpochTagged is key material derived from the secret key share of an epoch, e.g., a pre-signature or PCG seed.
+type EpochTagged interface {
+	GetEpoch() uint64
+}
+
+// DiscardStale returns the material which is compatible with ppk. After a refresh or resharing of the secret key
+// shares, parties must replace all stored material by the result of DiscardStale and delete the rest.
+func DiscardStale[T EpochTagged](ppk *PartySecretKey, material []T) []T {
+	var compatible []T
+	for _, m := range material {
+		if ppk.CheckEpoch(m.GetEpoch()) == nil {
+			compatible = append(compatible, m)
+		}
+	}
+	return compatible
+}
+
+// Zeroize overwrites the secret key share with zeros. The key must not be used afterwards.
+func (ppk *PartySecretKey) Zeroize() {
+	ppk.SKeyShare.Fr.Zero()
 }
 
 func (ppk *PartySecretKey) Marshal() ([]byte, error) {
+	payload := ppk.marshalPayload()
+	// The envelope holds a copy of the payload, the temporary buffer with the raw share is wiped.
+	defer clear(payload)
+	return encodeEnvelope(ObjectPartySecretKey, "", payload)
+}
+
+// marshalPayload encodes the party secret key as sk share | W | index (uint32) | epoch (uint64).
+func (ppk *PartySecretKey) marshalPayload() []byte {
 	g2 := bls12381.NewG2()
 
 	skShareBytes := ppk.SKeyShare.ToBytes()
+	defer clear(skShareBytes)
 
 	pkBytes := g2.ToCompressed(ppk.PublicKey)
 
-	indexBytes := make([]byte, 4)
-	binary.LittleEndian.PutUint32(indexBytes, uint32(ppk.Index))
-
-	bytes := append(skShareBytes, pkBytes...)
-	bytes = append(bytes, indexBytes...)
+	// Allocate the full payload up front, so that appending does not leave copies of the share behind.
+	bytes := make([]byte, 0, len(skShareBytes)+len(pkBytes)+4+8)
+	bytes = append(bytes, skShareBytes...)
+	bytes = append(bytes, pkBytes...)
+	bytes = binary.LittleEndian.AppendUint32(bytes, uint32(ppk.Index))
+	bytes = binary.LittleEndian.AppendUint64(bytes, ppk.Epoch)
 
-	return bytes, nil
+	return bytes
 }
 
 func UnmarshalPartyPrivateKey(partyPrivKeyBytes []byte) (*PartySecretKey, error) {
-	g2 := bls12381.NewG2()
-	fr := bls12381.NewFr()
-
-	expectedLength := helper.LenBytesFr + helper.LenBytesG2Compressed + 4 // Adjust lengths accordingly
+	_, payload, err := decodeEnvelope(ObjectPartySecretKey, partyPrivKeyBytes)
+	if err != nil {
+		return nil, err
+	}
 
-	if len(partyPrivKeyBytes) != expectedLength {
-		return nil, errors.New("invalid size of party private key")
+	r := &encodingReader{data: payload}
+	ppk := &PartySecretKey{
+		SKeyShare: SecretKey{r.fr()},
+		PublicKey: r.g2(),
+		Index:     int(r.uint32()),
+		Epoch:     r.uint64(),
 	}
+	if err := r.finish(); err != nil {
+		return nil, fmt.Errorf("decode party secret key: %w", err)
+	}
+	return ppk, nil
+}
 
-	skShare := fr.FromBytes(partyPrivKeyBytes[:helper.LenBytesFr])
+// UnmarshalPartyPrivateKeyLegacy reads the raw encoding of a party secret key without envelope. Keys serialized
+// before the epoch was added have no epoch and belong to epoch 0.
+func UnmarshalPartyPrivateKeyLegacy(partyPrivKeyBytes []byte) (*PartySecretKey, error) {
+	expectedLength := helper.LenBytesFr + helper.LenBytesG2Compressed + 4
 
-	publicKey, err := g2.FromCompressed(partyPrivKeyBytes[helper.LenBytesFr : helper.LenBytesFr+helper.LenBytesG2Compressed])
-	if err != nil {
-		return nil, fmt.Errorf("deserialize G2 compressed public key: %w", err)
+	if len(partyPrivKeyBytes) != expectedLength && len(partyPrivKeyBytes) != expectedLength+8 {
+		return nil, fmt.Errorf("%w: invalid size of party private key", ErrInvalidEncoding)
 	}
 
-	index := int(binary.LittleEndian.Uint32(partyPrivKeyBytes[helper.LenBytesFr+helper.LenBytesG2Compressed:]))
-
-	return &PartySecretKey{
-		SKeyShare: SecretKey{skShare},
-		PublicKey: publicKey,
-		Index:     index,
-	}, nil
+	r := &encodingReader{data: partyPrivKeyBytes}
+	ppk := &PartySecretKey{
+		SKeyShare: SecretKey{r.fr()},
+		PublicKey: r.g2(),
+		Index:     int(r.uint32()),
+	}
+	if len(partyPrivKeyBytes) > expectedLength {
+		ppk.Epoch = r.uint64()
+	}
+	if err := r.finish(); err != nil {
+		return nil, fmt.Errorf("decode party secret key: %w", err)
+	}
+	return ppk, nil
 }
 
 type PublicKey struct {
 	H0 *bls12381.PointG1
 	H  []*bls12381.PointG1
 	W  *bls12381.PointG2
+
+	// CiphersuiteID and GeneratorSeed determine H0 and H if they were derived with CreateGenerators.
+	// CiphersuiteID is empty for keys with explicitly given generators. A nil GeneratorSeed denotes
+	// the default seed of the ciphersuite.
+	CiphersuiteID string
+	GeneratorSeed []byte
+}
+
+// NewPublicKey derives the public key for w and messageCount messages from the generators of the ciphersuite.
+// H0 is the first generator, H the following messageCount ones.
+func NewPublicKey(w *bls12381.PointG2, ciphersuiteID string, generatorSeed []byte, messageCount int) (*PublicKey, error) {
+	cs, err := CiphersuiteByID(ciphersuiteID)
+	if err != nil {
+		return nil, err
+	}
+
+	generators, err := cs.CreateGenerators(messageCount+1, generatorSeed)
+	if err != nil {
+		return nil, fmt.Errorf("create generators: %w", err)
+	}
+
+	return &PublicKey{
+		H0:            generators[0],
+		H:             generators[1:],
+		W:             bls12381.NewG2().New().Set(w),
+		CiphersuiteID: ciphersuiteID,
+		GeneratorSeed: generatorSeed,
+	}, nil
 }
 
 func (sk *SecretKey) GetPublicKey(messageCount int) *PublicKey {
-	return GeneratePublicKeyFromRng(rand.New(rand.NewSource(0)), sk.Fr, messageCount)
+	g2 := bls12381.NewG2()
+	w := g2.New()
+	g2.MulScalar(w, g2.One(), sk.Fr)
+
+	pk, err := NewPublicKey(w, CiphersuiteBLS12381SHA256, nil, messageCount)
+	if err != nil {
+		panic(err)
+	}
+	return pk
 }
 
 func (sk *SecretKey) Sign(pk PublicKey, msgs []*bls12381.Fr, e *bls12381.Fr, s *bls12381.Fr) *ThresholdSignature {
 	g1 := bls12381.NewG1()
-	h0s := g1.New()
-	g1.MulScalar(h0s, pk.H0, s)
-
-	capitalA := g1.One()
-	g1.Add(capitalA, capitalA, h0s)
-
-	for i, msg := range msgs {
-		tmp := g1.New().Set(pk.H[i])
-		g1.MulScalar(tmp, tmp, msg)
-		g1.Add(capitalA, capitalA, tmp)
-	}
+	capitalA := pk.signingBasis(msgs, s)
 
 	ske := bls12381.NewFr().Set(sk.Fr)
 	ske.Add(sk.Fr, e)
@@ -112,44 +200,53 @@ func (sk *SecretKey) Sign(pk PublicKey, msgs []*bls12381.Fr, e *bls12381.Fr, s *
 	}
 }
 func (pk *PublicKey) Verify(messages []*bls12381.Fr, ts *ThresholdSignature) bool {
-	g1 := bls12381.NewG1()
-
-	// Compute h0^S (blinding factor)
-	h0s := g1.New().Set(pk.H0)
-	g1.MulScalar(h0s, h0s, ts.S)
-
-	// Compute verification basis: H0^S + sum(H_i^m_i)
-	verificationBasis := g1.One()
-	g1.Add(verificationBasis, verificationBasis, h0s)
-
-	for i, message := range messages {
-		tmp := g1.New().Set(pk.H[i])
-		g1.MulScalar(tmp, tmp, message) // Multiply H_i by hashed message
-		g1.Add(verificationBasis, verificationBasis, tmp)
-	}
+	return verifySignature(pk.W, bls12381.NewG2().One(), ts, pk.verificationBasis(messages, ts.S))
+}
 
-	// Compute u = W * g_2^E = g_2^sk * g_2^E
-	g2 := bls12381.NewG2()
-	u := g2.One()
-	g2.MulScalar(u, u, ts.E)
-	g2.Add(u, u, pk.W)
+// signingBasis computes B = g_1 + H0 * s + H_1 * m_1 + ... + H_L * m_L in constant time, as the messages of a
+// signature can be secret.
+func (pk *PublicKey) signingBasis(messages []*bls12381.Fr, s *bls12381.Fr) *bls12381.PointG1 {
+	g1 := bls12381.NewG1()
+	basis := helper.MultiScalarMulG1(append([]*bls12381.PointG1{pk.H0}, pk.H[:len(messages)]...), append([]*bls12381.Fr{s}, messages...))
+	return g1.Add(basis, basis, g1.One())
+}
 
-	// Compute t1 = e(A,u)
-	t1 := bls12381.NewEngine().AddPair(ts.CapitalA, u).Result()
+// verificationBasis computes B = g_1 + H0 * s + H_1 * m_1 + ... + H_L * m_L in variable time.
+func (pk *PublicKey) verificationBasis(messages []*bls12381.Fr, s *bls12381.Fr) *bls12381.PointG1 {
+	g1 := bls12381.NewG1()
+	basis := helper.MultiScalarMulVarTimeG1(append([]*bls12381.PointG1{pk.H0}, pk.H[:len(messages)]...), append([]*bls12381.Fr{s}, messages...))
+	return g1.Add(basis, basis, g1.One())
+}
 
-	// Compute t2 = e(verificationBasis, g_2)
-	t2 := bls12381.NewEngine().AddPair(verificationBasis, g2.One()).Result()
+// verifySignature checks e(A, w * g_2^e) == e(basis, g_2) as e(A, w) * e(A^e / basis, g_2) == 1, which needs a
+// single multi-pairing with one final exponentiation and moves the multiplication by e from G2 to G1.
+func verifySignature(w, g2One *bls12381.PointG2, ts *ThresholdSignature, basis *bls12381.PointG1) bool {
+	g1 := bls12381.NewG1()
+	eA := g1.New()
+	g1.MulScalar(eA, ts.CapitalA, ts.E)
+	g1.Sub(eA, eA, basis)
 
-	// Return whether t1 == t2
-	return t1.Equal(t2)
+	return bls12381.NewEngine().AddPair(ts.CapitalA, w).AddPair(eA, g2One).Check()
 }
 
+// GeneratePublicKey derives the public key of sk, using seedArray as generator seed of the
+// BLS12-381-SHA-256 ciphersuite.
 func GeneratePublicKey(seedArray [16]uint8, sk *bls12381.Fr, messageCount int) *PublicKey {
-	seed := int64(binary.BigEndian.Uint64(seedArray[:]))
-	rng := rand.New(rand.NewSource(seed))
-	return GeneratePublicKeyFromRng(rng, sk, messageCount)
+	g2 := bls12381.NewG2()
+	w := g2.New()
+	g2.MulScalar(w, g2.One(), sk)
+
+	pk, err := NewPublicKey(w, CiphersuiteBLS12381SHA256, seedArray[:], messageCount)
+	if err != nil {
+		panic(err)
+	}
+	return pk
 }
 
+// GeneratePublicKeyFromRng derives the generators as random multiples of the generator of G1.
+//
+// Deprecated: Anyone knowing the state of rng learns the discrete logarithms of the generators, which breaks
+// the security of BBS+. Use NewPublicKey instead.
 func GeneratePublicKeyFromRng(rng *rand.Rand, sk *bls12381.Fr, messageCount int) *PublicKey {
 	g2 := bls12381.NewG2()
 	w := g2.One()
@@ -207,58 +304,171 @@ func (pk *PublicKey) Validate() error {
 	return nil
 }
 
+// Serialize encodes the public key in an envelope with the payload
+// W | H0 | message count (uint32) | H | seed flag (uint8) [| seed length (uint32) | seed].
+// It panics if the ciphersuite ID is longer than 255 bytes, which is never the case for the supported ciphersuites.
 func (pk *PublicKey) Serialize() []byte {
-	ser := make([]byte, 0)
-
 	g2 := bls12381.NewG2()
 	g1 := bls12381.NewG1()
 
-	bytesW := g2.ToCompressed(pk.W)
+	ser := g2.ToCompressed(pk.W)
+	ser = append(ser, g1.ToCompressed(pk.H0)...)
+	ser = binary.LittleEndian.AppendUint32(ser, uint32(len(pk.H)))
+	for _, h := range pk.H {
+		ser = append(ser, g1.ToCompressed(h)...)
+	}
+	if pk.GeneratorSeed == nil {
+		ser = append(ser, 0)
+	} else {
+		ser = append(ser, 1)
+		ser = binary.LittleEndian.AppendUint32(ser, uint32(len(pk.GeneratorSeed)))
+		ser = append(ser, pk.GeneratorSeed...)
+	}
 
-	ser = append(ser, bytesW...)
+	envelope, err := encodeEnvelope(ObjectPublicKey, pk.CiphersuiteID, ser)
+	if err != nil {
+		panic(err)
+	}
+	return envelope
+}
 
-	bytesH0 := g1.ToCompressed(pk.H0)
+func DeserializePublicKey(serialized []byte) (*PublicKey, error) {
+	ciphersuiteID, payload, err := decodeEnvelope(ObjectPublicKey, serialized)
+	if err != nil {
+		return nil, err
+	}
 
-	ser = append(ser, bytesH0...)
+	r := &encodingReader{data: payload}
+	pk := &PublicKey{
+		W:             r.g2(),
+		H0:            r.g1(),
+		CiphersuiteID: ciphersuiteID,
+	}
+	messageCount := int(r.uint32())
+	if r.err == nil && messageCount > len(r.data)/helper.LenBytesG1Compressed {
+		return nil, fmt.Errorf("decode public key: %w: invalid message count", ErrInvalidEncoding)
+	}
+	pk.H = make([]*bls12381.PointG1, messageCount)
+	for i := range pk.H {
+		pk.H[i] = r.g1()
+	}
+	switch r.uint8() {
+	case 0:
+	case 1:
+		pk.GeneratorSeed = append([]byte{}, r.next(int(r.uint32()))...)
+	default:
+		return nil, fmt.Errorf("decode public key: %w: invalid seed flag", ErrInvalidEncoding)
+	}
+	if err := r.finish(); err != nil {
+		return nil, fmt.Errorf("decode public key: %w", err)
+	}
+	return pk, nil
+}
 
-	for _, h := range pk.H {
-		bytesH := g1.ToCompressed(h)
+// DeserializePublicKeyLegacy reads the raw encoding W | H0 | H of a public key without envelope.
+func DeserializePublicKeyLegacy(serialized []byte) (*PublicKey, error) {
+	headerLength := helper.LenBytesG2Compressed + helper.LenBytesG1Compressed
+	if len(serialized) < headerLength || (len(serialized)-headerLength)%helper.LenBytesG1Compressed != 0 {
+		return nil, fmt.Errorf("%w: invalid public key length", ErrInvalidEncoding)
+	}
 
-		ser = append(ser, bytesH...)
+	r := &encodingReader{data: serialized}
+	pk := &PublicKey{
+		W:  r.g2(),
+		H0: r.g1(),
+		H:  make([]*bls12381.PointG1, (len(serialized)-headerLength)/helper.LenBytesG1Compressed),
+	}
+	for i := range pk.H {
+		pk.H[i] = r.g1()
+	}
+	if err := r.finish(); err != nil {
+		return nil, fmt.Errorf("decode public key: %w", err)
 	}
-	return ser
+	return pk, nil
 }
 
-func DeserializePublicKey(serialized []byte) (*PublicKey, error) {
-	g1 := bls12381.NewG1()
-	g2 := bls12381.NewG2()
+// maxCompactMessageCount bounds the number of generators DeserializeCompactPublicKey derives, as the message count of
+// a compact public key is not backed by encoded data.
+const maxCompactMessageCount = 1 << 16
 
-	if len(serialized) < 2*helper.LenBytesG2Compressed+helper.LenBytesG1Compressed {
-		return nil, errors.New("invalid public key length")
+// SerializeCompact serializes a public key whose generators are derived with CreateGenerators. Only W, the
+// message count, the ciphersuite ID and the generator seed are encoded:
+// W | message count (uint32) | ID length (uint8) | ID | seed flag (uint8) [| seed length (uint32) | seed].
+func (pk *PublicKey) SerializeCompact() ([]byte, error) {
+	if pk.CiphersuiteID == "" {
+		return nil, errors.New("public key has no ciphersuite")
+	}
+	if len(pk.CiphersuiteID) > 255 {
+		return nil, errors.New("ciphersuite ID is too long")
 	}
 
-	w, err := g2.FromCompressed(serialized[:helper.LenBytesG2Compressed])
-	if err != nil {
-		return nil, err
+	ser := bls12381.NewG2().ToCompressed(pk.W)
+
+	countBytes := make([]byte, helper.IntSize)
+	binary.LittleEndian.PutUint32(countBytes, uint32(len(pk.H)))
+	ser = append(ser, countBytes...)
+
+	ser = append(ser, byte(len(pk.CiphersuiteID)))
+	ser = append(ser, pk.CiphersuiteID...)
+
+	if pk.GeneratorSeed == nil {
+		return append(ser, 0), nil
+	}
+	ser = append(ser, 1)
+	seedLengthBytes := make([]byte, helper.IntSize)
+	binary.LittleEndian.PutUint32(seedLengthBytes, uint32(len(pk.GeneratorSeed)))
+	ser = append(ser, seedLengthBytes...)
+	return append(ser, pk.GeneratorSeed...), nil
+}
+
+// DeserializeCompactPublicKey deserializes a public key serialized with SerializeCompact and re-derives its generators.
+func DeserializeCompactPublicKey(serialized []byte) (*PublicKey, error) {
+	if len(serialized) < helper.LenBytesG2Compressed+helper.IntSize+1 {
+		return nil, fmt.Errorf("%w: invalid compact public key length", ErrInvalidEncoding)
 	}
 
-	h0, err := g1.FromCompressed(serialized[helper.LenBytesG2Compressed : helper.LenBytesG2Compressed+helper.LenBytesG1Compressed])
+	w, err := bls12381.NewG2().FromCompressed(serialized[:helper.LenBytesG2Compressed])
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: deserialize G2 compressed public key: %w", ErrInvalidEncoding, err)
 	}
+	offset := helper.LenBytesG2Compressed
 
-	h := make([]*bls12381.PointG1, 0)
-	for i := helper.LenBytesG2Compressed + helper.LenBytesG1Compressed; i < len(serialized); i += helper.LenBytesG1Compressed {
-		hElem, err := g1.FromCompressed(serialized[i : i+helper.LenBytesG1Compressed])
-		if err != nil {
-			return nil, err
+	messageCount := int(binary.LittleEndian.Uint32(serialized[offset : offset+helper.IntSize]))
+	offset += helper.IntSize
+	if messageCount > maxCompactMessageCount {
+		return nil, fmt.Errorf("%w: message count %d exceeds %d", ErrInvalidEncoding, messageCount, maxCompactMessageCount)
+	}
+
+	idLength := int(serialized[offset])
+	offset++
+	if len(serialized) < offset+idLength+1 {
+		return nil, fmt.Errorf("%w: invalid compact public key length", ErrInvalidEncoding)
+	}
+	ciphersuiteID := string(serialized[offset : offset+idLength])
+	offset += idLength
+
+	var generatorSeed []byte
+	switch serialized[offset] {
+	case 0:
+		offset++
+	case 1:
+		offset++
+		if len(serialized) < offset+helper.IntSize {
+			return nil, fmt.Errorf("%w: invalid compact public key length", ErrInvalidEncoding)
+		}
+		seedLength := int(binary.LittleEndian.Uint32(serialized[offset : offset+helper.IntSize]))
+		offset += helper.IntSize
+		if seedLength > len(serialized)-offset {
+			return nil, fmt.Errorf("%w: invalid compact public key length", ErrInvalidEncoding)
 		}
-		h = append(h, hElem)
+		generatorSeed = append([]byte{}, serialized[offset:offset+seedLength]...)
+		offset += seedLength
+	default:
+		return nil, fmt.Errorf("%w: invalid generator seed flag", ErrInvalidEncoding)
+	}
+	if offset != len(serialized) {
+		return nil, fmt.Errorf("%w: invalid compact public key length", ErrInvalidEncoding)
 	}
 
-	return &PublicKey{
-		W:  w,
-		H0: h0,
-		H:  h,
-	}, nil
+	return NewPublicKey(w, ciphersuiteID, generatorSeed, messageCount)
 }
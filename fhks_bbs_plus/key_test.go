@@ -0,0 +1,26 @@
+package fhks_bbs_plus_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
+)
+
+func TestDiscardStale(t *testing.T) {
+	key := &fhks_bbs_plus.PartySecretKey{Index: 1, Epoch: 2}
+
+	preSignatures := make([]*fhks_bbs_plus.LiveBBSPreSignature, 4)
+	for i := range preSignatures {
+		preSignatures[i] = fhks_bbs_plus.NewLiveBBSPreSignature()
+		preSignatures[i].Epoch = uint64(i)
+	}
+
+	compatible := fhks_bbs_plus.DiscardStale(key, preSignatures)
+	assert.Equal(t, []*fhks_bbs_plus.LiveBBSPreSignature{preSignatures[2]}, compatible)
+
+	assert.NoError(t, key.CheckEpoch(2))
+	assert.True(t, errors.Is(key.CheckEpoch(1), fhks_bbs_plus.ErrEpochMismatch))
+}
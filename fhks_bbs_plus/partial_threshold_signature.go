@@ -1,6 +1,7 @@
 package fhks_bbs_plus
 
 import (
+	"errors"
 	"fmt"
 	bls12381 "github.com/kilic/bls12-381"
 	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
@@ -31,29 +32,107 @@ func NewPartialThresholdSignatureFromValues(capitalAShare *bls12381.PointG1, del
 	}
 }
 
-func (pts *PartialThresholdSignature) New(messages []*bls12381.Fr, pk *PublicKey, preSignature *LivePreSignature) *PartialThresholdSignature {
-	g1 := bls12381.NewG1()
-	basis := bls12381.NewG1().One()
-
-	for i := 0; i < len(pk.H); i++ {
-		tmp := g1.New().Set(pk.H[i])
-		g1.MulScalar(tmp, tmp, messages[i])
-		g1.Add(basis, basis, tmp)
+// New computes the partial signature of messages with the live pre-signature of the party holding keyShare. It returns
+// ErrEpochMismatch if the pre-signature was derived from a secret key share of another epoch than keyShare.
+func (pts *PartialThresholdSignature) New(messages []*bls12381.Fr, pk *PublicKey, keyShare *PartySecretKey, preSignature *LivePreSignature) (*PartialThresholdSignature, error) {
+	if err := keyShare.CheckEpoch(preSignature.Epoch); err != nil {
+		return nil, err
 	}
 
-	capitalAShare := g1.New().Set(basis)
-	g1.MulScalar(capitalAShare, capitalAShare, preSignature.AShare)
-	tmp := g1.New().Set(pk.H0)
-	g1.MulScalar(tmp, tmp, preSignature.AlphaShare)
-	g1.Add(capitalAShare, capitalAShare, tmp)
+	g1 := bls12381.NewG1()
+	basis := helper.MultiScalarMulG1(pk.H, messages[:len(pk.H)])
+	g1.Add(basis, basis, g1.One())
+
+	capitalAShare := helper.MultiScalarMulG1(
+		[]*bls12381.PointG1{basis, pk.H0},
+		[]*bls12381.Fr{preSignature.AShare, preSignature.AlphaShare},
+	)
 
 	pts.CapitalAShare.Set(capitalAShare)
 	pts.DeltaShare.Set(preSignature.DeltaShare)
 	pts.EShare.Set(preSignature.EShare)
 	pts.SShare.Set(preSignature.SShare)
-	return pts
+	return pts, nil
+}
+
+// PartialSignatureError identifies a party whose partial signature does not match its published commitments.
+type PartialSignatureError struct {
+	Index  int    // Index of the offending party.
+	Reason string // Which check failed.
+}
+
+func (e *PartialSignatureError) Error() string {
+	return fmt.Sprintf("invalid partial signature of party %d: %s", e.Index, e.Reason)
+}
+
+// FaultyParties returns the indices of all parties blamed by the PartialSignatureErrors contained in err, in the
+// order in which they occur. It walks errors wrapped with fmt.Errorf as well as errors joined with errors.Join.
+func FaultyParties(err error) []int {
+	var indices []int
+	blamed := make(map[int]bool)
+	var walk func(err error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case nil:
+			return
+		case interface{ Unwrap() []error }:
+			for _, inner := range e.Unwrap() {
+				walk(inner)
+			}
+		case interface{ Unwrap() error }:
+			walk(e.Unwrap())
+		default:
+			var pse *PartialSignatureError
+			if errors.As(err, &pse) && !blamed[pse.Index] {
+				blamed[pse.Index] = true
+				indices = append(indices, pse.Index)
+			}
+		}
+	}
+	walk(err)
+	return indices
 }
 
+// Verify checks the partial signature of party index on messages against the commitments to the live
+// pre-signature it was computed from. It returns a *PartialSignatureError if any share does not match.
+func (pts *PartialThresholdSignature) Verify(
+	messages []*bls12381.Fr,
+	pk *PublicKey,
+	index int,
+	commitment *LivePreSignatureCommitment) error {
+	if len(messages) != len(pk.H) {
+		return fmt.Errorf("expected %d messages, got %d", len(pk.H), len(messages))
+	}
+
+	g1 := bls12381.NewG1()
+	g2 := bls12381.NewG2()
+
+	if !g2.Equal(commitFr(pts.EShare), commitment.E) {
+		return &PartialSignatureError{Index: index, Reason: "e share does not match commitment"}
+	}
+	if !g2.Equal(commitFr(pts.SShare), commitment.S) {
+		return &PartialSignatureError{Index: index, Reason: "s share does not match commitment"}
+	}
+	if !g2.Equal(commitFr(pts.DeltaShare), commitment.Delta) {
+		return &PartialSignatureError{Index: index, Reason: "delta share does not match commitment"}
+	}
+
+	basis := helper.MultiScalarMulVarTimeG1(pk.H, messages[:len(pk.H)])
+	g1.Add(basis, basis, g1.One())
+
+	// Check e(A_i, g_2) == e(basis, g_2^(a_i)) * e(h_0, g_2^(alpha_i)).
+	engine := bls12381.NewEngine()
+	engine.AddPair(pts.CapitalAShare, g2.One())
+	engine.AddPairInv(basis, commitment.A)
+	engine.AddPairInv(pk.H0, commitment.Alpha)
+	if !engine.Check() {
+		return &PartialSignatureError{Index: index, Reason: "A share does not match commitment"}
+	}
+
+	return nil
+}
+
+// ToBytes encodes the partial signature in an envelope with the payload A share | delta share | e share | s share.
 func (pts *PartialThresholdSignature) ToBytes() ([]byte, error) {
 	g1 := bls12381.NewG1()
 
@@ -71,33 +150,33 @@ func (pts *PartialThresholdSignature) ToBytes() ([]byte, error) {
 	sShareBytes := pts.SShare.ToBytes()
 	bytes = append(bytes, sShareBytes...)
 
-	return bytes, nil
+	return encodeEnvelope(ObjectPartialThresholdSignature, "", bytes)
 }
 
 func PartThreshSigFromBytes(partSigBytes []byte) (*PartialThresholdSignature, error) {
-	g1 := bls12381.NewG1()
-
-	fmt.Println("len of partSigBytes: ", len(partSigBytes))
-
-	capitalAShare, err := g1.FromCompressed(partSigBytes[:helper.LenBytesG1Compressed])
+	_, payload, err := decodeEnvelope(ObjectPartialThresholdSignature, partSigBytes)
 	if err != nil {
-		return nil, fmt.Errorf("deserialize G1 compressed signature: %w", err)
+		return nil, err
 	}
+	return PartThreshSigFromLegacyBytes(payload)
+}
 
-	offset := helper.LenBytesG1Compressed
-
-	deltaShare := bls12381.NewFr().FromBytes(partSigBytes[offset : offset+helper.LenBytesFr])
-	offset += helper.LenBytesFr
-
-	eShare := bls12381.NewFr().FromBytes(partSigBytes[offset : offset+helper.LenBytesFr])
-	offset += helper.LenBytesFr
-
-	sShare := bls12381.NewFr().FromBytes(partSigBytes[offset:])
+// PartThreshSigFromLegacyBytes reads the raw encoding A share | delta share | e share | s share of a partial
+// signature without envelope.
+func PartThreshSigFromLegacyBytes(partSigBytes []byte) (*PartialThresholdSignature, error) {
+	if len(partSigBytes) != helper.LenBytesG1Compressed+3*helper.LenBytesFr {
+		return nil, fmt.Errorf("%w: invalid serialized partial signature length: expected %d, got %d", ErrInvalidEncoding, helper.LenBytesG1Compressed+3*helper.LenBytesFr, len(partSigBytes))
+	}
 
-	return &PartialThresholdSignature{
-		CapitalAShare: capitalAShare,
-		DeltaShare:    deltaShare,
-		EShare:        eShare,
-		SShare:        sShare,
-	}, nil
+	r := &encodingReader{data: partSigBytes}
+	pts := &PartialThresholdSignature{
+		CapitalAShare: r.g1(),
+		DeltaShare:    r.fr(),
+		EShare:        r.fr(),
+		SShare:        r.fr(),
+	}
+	if err := r.finish(); err != nil {
+		return nil, fmt.Errorf("decode partial signature: %w", err)
+	}
+	return pts, nil
 }
@@ -2,7 +2,6 @@ package fhks_bbs_plus
 
 import (
 	"encoding/binary"
-	"errors"
 	"fmt"
 	bls12381 "github.com/kilic/bls12-381"
 	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
@@ -21,6 +20,11 @@ type PerPartyPreSignature struct {
 	AsTermsS   []*bls12381.Fr // Share of a^k_j * s^k_i for k in [t], j in [n] (j can also be i -- this time other share).
 	AskTermsA  []*bls12381.Fr // Share of a^k_i * sk_j for k in [t], j in [n] (j can also be i).
 	AskTermsSK []*bls12381.Fr // Share of a^k_j * sk_i for k in [t], j in [n] (j can also be i -- this time other share).
+	Epoch      uint64         // Epoch of the secret key share the pre-signature is derived from.
+}
+
+func (pps *PerPartyPreSignature) GetEpoch() uint64 {
+	return pps.Epoch
 }
 
 type PerPartyPreSignatureSimple struct {
@@ -56,28 +60,17 @@ func SerializeAeTermsA(aeTermsA []*bls12381.Fr) ([]byte, error) {
 	return append(lengthBytes, dataBytes...), nil
 }
 func DeserializeAeTermsA(data []byte) ([]*bls12381.Fr, error) {
-	if len(data) < 4 {
-		return nil, errors.New("data too short to contain length")
-	}
-
-	length := int(binary.LittleEndian.Uint32(data[:4]))
-	data = data[4:]
-
-	elementSize := helper.LenBytesFr
-	expectedDataLength := length * elementSize
-	if len(data) != expectedDataLength {
-		return nil, fmt.Errorf("data length mismatch: expected %d bytes, got %d bytes", expectedDataLength, len(data))
-	}
-
-	elements := make([]*bls12381.Fr, length)
-	for i := 0; i < length; i++ {
-		start := i * elementSize
-		end := start + elementSize
-		elements[i] = bls12381.NewFr().FromBytes(data[start:end])
+	r := &encodingReader{data: data}
+	elements := r.frSlice()
+	if err := r.finish(); err != nil {
+		return nil, err
 	}
-
 	return elements, nil
 }
+
+// ToBytes encodes the pre-signature in an envelope. The payload consists of the shares a, e, s and the own terms
+// a * e, a * s, a * sk followed by the slices of cross terms, each prefixed with its length (uint32), and the epoch
+// (uint64).
 func (ppp *PerPartyPreSignature) ToBytes() ([]byte, error) {
 	aShareBytes := ppp.AShare.ToBytes()
 	eShareBytes := ppp.EShare.ToBytes()
@@ -134,94 +127,59 @@ func (ppp *PerPartyPreSignature) ToBytes() ([]byte, error) {
 	result = append(result, asTermsSBytes...)
 	result = append(result, askTermsABytes...)
 	result = append(result, askTermsSKBytes...)
+	result = binary.LittleEndian.AppendUint64(result, ppp.Epoch)
 
-	return result, nil
+	return encodeEnvelope(ObjectPerPartyPreSignature, "", result)
 }
-func FromBytes(data []byte) (*PerPartyPreSignature, *bls12381.Fr, error) {
-
-	readFr := func(data []byte) (*bls12381.Fr, []byte) {
-		element := bls12381.NewFr().FromBytes(data[:helper.LenBytesFr])
-		copiedElement := bls12381.NewFr()
-		copiedElement.Set(element) // Assuming Set() exists for copying values
-		return copiedElement, data[helper.LenBytesFr:]
-	}
-
-	readFrSlice := func(data []byte) ([]*bls12381.Fr, []byte, error) {
-		if len(data) < 4 {
-			return nil, nil, errors.New("data too short to contain length")
-		}
-
-		length := int(binary.LittleEndian.Uint32(data[:4]))
-		data = data[4:]
-
-		elementSize := helper.LenBytesFr
-		if len(data) < length*elementSize {
-			return nil, nil, errors.New("data too short to contain all elements")
-		}
-
-		slice := make([]*bls12381.Fr, length)
-		for i := 0; i < length; i++ {
-			element := bls12381.NewFr().FromBytes(data[i*elementSize : (i+1)*elementSize])
-			copiedElement := bls12381.NewFr()
-			copiedElement.Set(element)
-			slice[i] = copiedElement
-		}
-
-		return slice, data[length*elementSize:], nil
-	}
 
-	aShare, data := readFr(data)
-	eShare, data := readFr(data)
-	sShare, data := readFr(data)
-	aeTermOwn, data := readFr(data)
-	asTermOwn, data := readFr(data)
-	askTermOwn, data := readFr(data)
-
-	var aeTermsA []*bls12381.Fr
-	var aeTermsE []*bls12381.Fr
-	var asTermsA []*bls12381.Fr
-	var asTermsS []*bls12381.Fr
-	var askTermsA []*bls12381.Fr
-	var askTermsSK []*bls12381.Fr
-
-	var err error
-
-	if aeTermsA, data, err = readFrSlice(data); err != nil {
-		return nil, nil, err
-	}
-	if aeTermsE, data, err = readFrSlice(data); err != nil {
+// FromBytes decodes a pre-signature encoded with ToBytes. It also returns the a share of the pre-signature.
+func FromBytes(data []byte) (*PerPartyPreSignature, *bls12381.Fr, error) {
+	_, payload, err := decodeEnvelope(ObjectPerPartyPreSignature, data)
+	if err != nil {
 		return nil, nil, err
 	}
-	if asTermsA, data, err = readFrSlice(data); err != nil {
-		return nil, nil, err
+	return FromLegacyBytes(payload)
+}
+
+// FromLegacyBytes reads the raw encoding of a pre-signature without envelope. It also returns the a share of the
+// pre-signature. Pre-signatures serialized before the epoch was added have no epoch and belong to epoch 0.
+func FromLegacyBytes(data []byte) (*PerPartyPreSignature, *bls12381.Fr, error) {
+	r := &encodingReader{data: data}
+	preSignature := &PerPartyPreSignature{
+		AShare:     r.fr(),
+		EShare:     r.fr(),
+		SShare:     r.fr(),
+		AeTermOwn:  r.fr(),
+		AsTermOwn:  r.fr(),
+		AskTermOwn: r.fr(),
+		AeTermsA:   r.frSlice(),
+		AeTermsE:   r.frSlice(),
+		AsTermsA:   r.frSlice(),
+		AsTermsS:   r.frSlice(),
+		AskTermsA:  r.frSlice(),
+		AskTermsSK: r.frSlice(),
 	}
-	if asTermsS, data, err = readFrSlice(data); err != nil {
-		return nil, nil, err
+	if r.err == nil && len(r.data) > 0 {
+		preSignature.Epoch = r.uint64()
 	}
-	if askTermsA, data, err = readFrSlice(data); err != nil {
-		return nil, nil, err
+	if err := r.finish(); err != nil {
+		return nil, nil, fmt.Errorf("decode pre-signature: %w", err)
 	}
-	if askTermsSK, _, err = readFrSlice(data); err != nil {
-		return nil, nil, err
-	}
-
-	return &PerPartyPreSignature{
-		AShare:     aShare,
-		EShare:     eShare,
-		SShare:     sShare,
-		AeTermOwn:  aeTermOwn,
-		AsTermOwn:  asTermOwn,
-		AskTermOwn: askTermOwn,
-		AeTermsA:   aeTermsA,
-		AeTermsE:   aeTermsE,
-		AsTermsA:   asTermsA,
-		AsTermsS:   asTermsS,
-		AskTermsA:  askTermsA,
-		AskTermsSK: askTermsSK,
-	}, aShare, nil
+	return preSignature, preSignature.AShare, nil
 }
 
+// ToBytes encodes the precomputations as sk share | W | index (uint32) | pre-signatures | epoch (uint64). As all
+// pre-signatures of a party are derived from the same secret key share, the epoch is encoded only once.
 func (ppp *PerPartyPrecomputationsWithPubKey) ToBytes() ([]byte, error) {
+	var epoch uint64
+	for i, preSignature := range ppp.PreSignatures {
+		if i == 0 {
+			epoch = preSignature.Epoch
+		} else if preSignature.Epoch != epoch {
+			return nil, fmt.Errorf("pre-signatures of epochs %d and %d cannot be encoded together", epoch, preSignature.Epoch)
+		}
+	}
+
 	preSigsLenBytes := make([]byte, helper.IntSize)
 
 	binary.LittleEndian.PutUint32(preSigsLenBytes, uint32(len(ppp.PreSignatures)))
@@ -270,10 +228,93 @@ func (ppp *PerPartyPrecomputationsWithPubKey) ToBytes() ([]byte, error) {
 			bytes = append(bytes, ask.ToBytes()...)
 		}
 	}
+	bytes = binary.LittleEndian.AppendUint64(bytes, epoch)
 
 	return bytes, nil
 }
 
+// PerPartyPrecomputationsWithPubKeyFromBytes reads precomputations serialized with
+// PerPartyPrecomputationsWithPubKey.ToBytes for n parties. The encoding does not contain the number of parties, every
+// pre-signature holds n cross terms per kind. Precomputations serialized before the epoch was added have no epoch and
+// belong to epoch 0.
+func PerPartyPrecomputationsWithPubKeyFromBytes(data []byte, n int) (*PerPartyPrecomputationsWithPubKey, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("invalid number of parties %d", n)
+	}
+	headerLength := helper.LenBytesFr + helper.LenBytesG2Compressed + helper.IntSize
+	preSignatureLength := (6 + 6*n) * helper.LenBytesFr
+	// The length of the pre-signatures is a multiple of the length of Fr, hence the epoch is present iff 8 bytes remain.
+	epochLength := 0
+	if len(data) >= headerLength && (len(data)-headerLength)%preSignatureLength == 8 {
+		epochLength = 8
+	}
+	if len(data) < headerLength || (len(data)-headerLength-epochLength)%preSignatureLength != 0 {
+		return nil, fmt.Errorf("%w: invalid size of precomputations for %d parties", ErrInvalidEncoding, n)
+	}
+
+	r := &encodingReader{data: data}
+	ppp := &PerPartyPrecomputationsWithPubKey{
+		SkShare:       r.fr(),
+		PublicKey:     r.g2(),
+		Index:         int(r.uint32()),
+		PreSignatures: make([]*PerPartyPreSignature, (len(data)-headerLength-epochLength)/preSignatureLength),
+	}
+	readTerms := func() []*bls12381.Fr {
+		terms := make([]*bls12381.Fr, n)
+		for i := range terms {
+			terms[i] = r.fr()
+		}
+		return terms
+	}
+	for i := range ppp.PreSignatures {
+		ppp.PreSignatures[i] = &PerPartyPreSignature{
+			AShare:     r.fr(),
+			EShare:     r.fr(),
+			SShare:     r.fr(),
+			AeTermOwn:  r.fr(),
+			AsTermOwn:  r.fr(),
+			AskTermOwn: r.fr(),
+			AeTermsA:   readTerms(),
+			AeTermsE:   readTerms(),
+			AsTermsA:   readTerms(),
+			AsTermsS:   readTerms(),
+			AskTermsA:  readTerms(),
+			AskTermsSK: readTerms(),
+		}
+	}
+	if epochLength > 0 {
+		epoch := r.uint64()
+		for _, preSignature := range ppp.PreSignatures {
+			preSignature.Epoch = epoch
+		}
+	}
+	if err := r.finish(); err != nil {
+		return nil, fmt.Errorf("decode precomputations: %w", err)
+	}
+	return ppp, nil
+}
+
+// Zeroize overwrites the secret key share and all pre-signatures with zeros. The precomputations must not be used
+// afterwards.
+func (ppp *PerPartyPrecomputationsWithPubKey) Zeroize() {
+	ppp.SkShare.Zero()
+	for _, preSignature := range ppp.PreSignatures {
+		preSignature.Zeroize()
+	}
+}
+
+// Zeroize overwrites all shares and terms of the pre-signature with zeros.
+func (pps *PerPartyPreSignature) Zeroize() {
+	for _, fr := range []*bls12381.Fr{pps.AShare, pps.EShare, pps.SShare, pps.AeTermOwn, pps.AsTermOwn, pps.AskTermOwn} {
+		fr.Zero()
+	}
+	for _, terms := range [][]*bls12381.Fr{pps.AeTermsA, pps.AeTermsE, pps.AsTermsA, pps.AsTermsS, pps.AskTermsA, pps.AskTermsSK} {
+		for _, fr := range terms {
+			fr.Zero()
+		}
+	}
+}
+
 type PerPartyPrecomputationsSimple struct {
 	Index         int // Position at which sk-polynomial for own secret key share is evaluated.
 	SkShare       *bls12381.Fr
@@ -286,6 +327,7 @@ type LivePreSignatureSk struct {
 	SShare     *bls12381.Fr
 	DeltaShare *bls12381.Fr
 	AlphaShare *bls12381.Fr
+	Epoch      uint64 // Epoch of the secret key share the pre-signature is derived from.
 }
 
 type LivePreSignature struct {
@@ -294,6 +336,15 @@ type LivePreSignature struct {
 	SShare     *bls12381.Fr
 	DeltaShare *bls12381.Fr
 	AlphaShare *bls12381.Fr
+	Epoch      uint64 // Epoch of the secret key share the pre-signature is derived from.
+}
+
+func (lps *LivePreSignatureSk) GetEpoch() uint64 {
+	return lps.Epoch
+}
+
+func (lps *LivePreSignature) GetEpoch() uint64 {
+	return lps.Epoch
 }
 
 func NewLivePreSignature() *LivePreSignature {
@@ -372,5 +423,6 @@ func (lps *LivePreSignature) FromPresignatureWithCoefficients(
 	lps.SShare.Set(preSignature.SShare)
 	lps.DeltaShare.Set(deltaShare)
 	lps.AlphaShare.Set(alphaShare)
+	lps.Epoch = preSignature.Epoch
 	return lps
 }
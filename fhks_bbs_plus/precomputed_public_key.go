@@ -0,0 +1,57 @@
+package fhks_bbs_plus
+
+import (
+	bls12381 "github.com/kilic/bls12-381"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
+)
+
+// PrecomputedPublicKey speeds up the computation of the basis g_1 * H0^s * H_1^m_1 * ... * H_L^m_L, which every
+// verification of a signature under a PublicKey recomputes, with fixed-base tables of H0 and H_1, ..., H_L.
+// The pairing itself is computed as in PublicKey.Verify, only from W and g_2 in affine form. The G2 line coefficients
+// of W and g_2 are not cached: kilic/bls12-381 keeps the Miller loop, the final exponentiation and the Fp12 arithmetic
+// unexported and only accepts pairing results in GT, so cached lines could not be fed into its pairing without a copy
+// of the pairing code.
+// As all cached points are affine, the pairing engine does not modify them and a PrecomputedPublicKey can be used
+// concurrently.
+type PrecomputedPublicKey struct {
+	PublicKey *PublicKey
+	w         *bls12381.PointG2
+	g2One     *bls12381.PointG2
+	h0Table   *helper.G1FixedBaseTable
+	hTables   []*helper.G1FixedBaseTable
+}
+
+// Precompute returns the PrecomputedPublicKey of pk. It pays off from a few verifications on.
+func (pk *PublicKey) Precompute() *PrecomputedPublicKey {
+	g2 := bls12381.NewG2()
+
+	hTables := make([]*helper.G1FixedBaseTable, len(pk.H))
+	for i, h := range pk.H {
+		hTables[i] = helper.NewG1FixedBaseTable(h)
+	}
+
+	return &PrecomputedPublicKey{
+		PublicKey: pk,
+		w:         g2.Affine(g2.New().Set(pk.W)),
+		g2One:     g2.One(),
+		h0Table:   helper.NewG1FixedBaseTable(pk.H0),
+		hTables:   hTables,
+	}
+}
+
+// Verify is PublicKey.Verify using the precomputed values.
+func (cpk *PrecomputedPublicKey) Verify(messages []*bls12381.Fr, ts *ThresholdSignature) bool {
+	if len(messages) > len(cpk.hTables) {
+		return false
+	}
+
+	g1 := bls12381.NewG1()
+	basis := cpk.h0Table.Mul(ts.S)
+	g1.Add(basis, basis, g1.One())
+	for i, message := range messages {
+		g1.Add(basis, basis, cpk.hTables[i].Mul(message))
+	}
+
+	return verifySignature(cpk.w, cpk.g2One, ts, basis)
+}
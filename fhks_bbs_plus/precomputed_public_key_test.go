@@ -0,0 +1,30 @@
+package fhks_bbs_plus_test
+
+import (
+	"testing"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
+	"github.com/perun-network/bbs-plus-threshold-wallet/test"
+)
+
+func TestPrecomputedPublicKeyVerify(t *testing.T) {
+	sk := fhks_bbs_plus.SecretKey{Fr: fhks_bbs_plus.GenerateRandomFr()}
+	pk := sk.GetPublicKey(test.MessageCount)
+	precomputed := pk.Precompute()
+
+	for i := 0; i < 3; i++ {
+		messages, signature := signRandomMessages(sk, pk)
+		assert.True(t, precomputed.Verify(messages, signature), "signature verification failed")
+		assert.True(t, signature.Verify(messages, pk), "signature verification failed")
+
+		signature.S.Add(signature.S, bls12381.NewFr().One())
+		assert.False(t, precomputed.Verify(messages, signature))
+		assert.False(t, pk.Verify(messages, signature))
+	}
+
+	messages, signature := signRandomMessages(sk, pk)
+	assert.False(t, precomputed.Verify(append(messages, fhks_bbs_plus.GenerateRandomFr()), signature))
+}
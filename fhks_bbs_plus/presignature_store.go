@@ -0,0 +1,267 @@
+package fhks_bbs_plus
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+// ErrPreSignatureConsumed is returned if a pre-signature that was already used for one signing request is used for
+// another one. Using a pre-signature for two different messages leaks the secret key.
+var ErrPreSignatureConsumed = errors.New("pre-signature has already been used for another request")
+
+// PreSignatureStore records which pre-signatures have been used.
+//
+// A pre-signature is identified by its key epoch and its index in the batch of the party and is bound to the digest
+// of the first signing request it is consumed for, which covers the messages and the signer set. Consuming it again
+// for the same request is allowed, so that partial signatures can be recomputed after a crash. A request for another
+// signer set needs a fresh pre-signature.
+type PreSignatureStore interface {
+	// Consume binds pre-signature index of epoch to the request digest. It returns ErrPreSignatureConsumed if the
+	// pre-signature is bound to another digest. Once Consume returned nil, the binding must survive restarts and
+	// crashes.
+	Consume(epoch uint64, index int, digest [32]byte) error
+}
+
+// Scheme tags of the request digests, pre-signatures must not be shared by schemes with different bases.
+const (
+	requestSchemeBBSPlus = "BBS+"
+	requestSchemeIETF    = "IETF"
+)
+
+// requestDigest hashes the scheme, header, messages and signer set of a signing request. The order of the signer set
+// does not matter.
+func requestDigest(scheme string, header []byte, messages []*bls12381.Fr, signerSet []int) [32]byte {
+	h := sha256.New()
+	h.Write([]byte(scheme))
+	h.Write(binary.LittleEndian.AppendUint64(nil, uint64(len(header))))
+	h.Write(header)
+	h.Write(binary.LittleEndian.AppendUint64(nil, uint64(len(messages))))
+	for _, message := range messages {
+		h.Write(message.ToBytes())
+	}
+	sorted := append([]int(nil), signerSet...)
+	sort.Ints(sorted)
+	h.Write(binary.LittleEndian.AppendUint64(nil, uint64(len(sorted))))
+	for _, index := range sorted {
+		h.Write(binary.LittleEndian.AppendUint64(nil, uint64(index)))
+	}
+	var digest [32]byte
+	h.Sum(digest[:0])
+	return digest
+}
+
+// NewOnce consumes pre-signature index in store for messages and signerSet, the signer set preSignature was derived
+// for, and only then computes the partial signature as New. Pre-signatures of another epoch than keyShare are rejected
+// before they are consumed.
+func (pts *PartialThresholdSignature) NewOnce(
+	store PreSignatureStore,
+	index int,
+	signerSet []int,
+	messages []*bls12381.Fr,
+	pk *PublicKey,
+	keyShare *PartySecretKey,
+	preSignature *LivePreSignature) (*PartialThresholdSignature, error) {
+	if err := keyShare.CheckEpoch(preSignature.Epoch); err != nil {
+		return nil, err
+	}
+	if err := store.Consume(preSignature.Epoch, index, requestDigest(requestSchemeBBSPlus, nil, messages, signerSet)); err != nil {
+		return nil, err
+	}
+	return pts.New(messages, pk, keyShare, preSignature)
+}
+
+// NewOnce consumes pre-signature index in store for messages, header and signerSet and only then computes the partial
+// signature as New. Pre-signatures of another epoch than keyShare are rejected before they are consumed.
+func (pbs *PartialBBSSignature) NewOnce(
+	store PreSignatureStore,
+	index int,
+	signerSet []int,
+	messages []*bls12381.Fr,
+	pk *PublicKey,
+	header []byte,
+	keyShare *PartySecretKey,
+	preSignature *LiveBBSPreSignature) (*PartialBBSSignature, error) {
+	if err := keyShare.CheckEpoch(preSignature.Epoch); err != nil {
+		return nil, err
+	}
+	if err := store.Consume(preSignature.Epoch, index, requestDigest(requestSchemeIETF, header, messages, signerSet)); err != nil {
+		return nil, err
+	}
+	return pbs.New(messages, pk, header, keyShare, preSignature)
+}
+
+type preSignatureID struct {
+	epoch uint64
+	index int
+}
+
+// consumedSet is the in-memory state shared by the store implementations.
+type consumedSet map[preSignatureID][32]byte
+
+func (c consumedSet) check(id preSignatureID, digest [32]byte) (bool, error) {
+	bound, ok := c[id]
+	if !ok {
+		return false, nil
+	}
+	if bound != digest {
+		return true, fmt.Errorf("%w: pre-signature %d of epoch %d", ErrPreSignatureConsumed, id.index, id.epoch)
+	}
+	return true, nil
+}
+
+// MemoryPreSignatureStore is a PreSignatureStore that does not persist its records. It only protects against reuse
+// within one process.
+type MemoryPreSignatureStore struct {
+	mu       sync.Mutex
+	consumed consumedSet
+}
+
+func NewMemoryPreSignatureStore() *MemoryPreSignatureStore {
+	return &MemoryPreSignatureStore{consumed: make(consumedSet)}
+}
+
+func (s *MemoryPreSignatureStore) Consume(epoch uint64, index int, digest [32]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := preSignatureID{epoch, index}
+	if found, err := s.consumed.check(id, digest); found {
+		return err
+	}
+	s.consumed[id] = digest
+	return nil
+}
+
+// A record of the file store is epoch (uint64) | index (uint32) | digest | CRC-32 of the preceding fields.
+const preSignatureRecordLength = 8 + 4 + 32 + 4
+
+// FilePreSignatureStore is a PreSignatureStore for a single node that appends its records to a file and syncs it
+// before Consume returns. A record that was torn by a crash during Consume is discarded on opening, as its
+// pre-signature was not released. The file must only be opened by one store at a time.
+type FilePreSignatureStore struct {
+	mu       sync.Mutex
+	file     *os.File
+	consumed consumedSet
+	err      error // Set if a record could not be written, the state of the file is unknown afterwards.
+}
+
+// OpenFilePreSignatureStore opens the store in the file at path, creating the file if necessary.
+func OpenFilePreSignatureStore(path string) (*FilePreSignatureStore, error) {
+	_, statErr := os.Stat(path)
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if os.IsNotExist(statErr) {
+		// Persist the directory entry of the new file.
+		if err := syncDir(filepath.Dir(path)); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+
+	s := &FilePreSignatureStore{file: file, consumed: make(consumedSet)}
+	if err := s.load(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// load reads all records and truncates a torn last record.
+func (s *FilePreSignatureStore) load() error {
+	data, err := io.ReadAll(s.file)
+	if err != nil {
+		return err
+	}
+
+	valid := 0
+	for offset := 0; offset+preSignatureRecordLength <= len(data); offset += preSignatureRecordLength {
+		record := data[offset : offset+preSignatureRecordLength]
+		checksum := binary.LittleEndian.Uint32(record[preSignatureRecordLength-4:])
+		if crc32.ChecksumIEEE(record[:preSignatureRecordLength-4]) != checksum {
+			if offset+preSignatureRecordLength < len(data) {
+				return fmt.Errorf("corrupted pre-signature record at offset %d", offset)
+			}
+			break
+		}
+
+		id := preSignatureID{binary.LittleEndian.Uint64(record), int(binary.LittleEndian.Uint32(record[8:]))}
+		var digest [32]byte
+		copy(digest[:], record[12:])
+		if _, ok := s.consumed[id]; !ok {
+			s.consumed[id] = digest
+		}
+		valid = offset + preSignatureRecordLength
+	}
+
+	if valid != len(data) {
+		if err := s.file.Truncate(int64(valid)); err != nil {
+			return err
+		}
+		if err := s.file.Sync(); err != nil {
+			return err
+		}
+	}
+	_, err = s.file.Seek(int64(valid), io.SeekStart)
+	return err
+}
+
+func (s *FilePreSignatureStore) Consume(epoch uint64, index int, digest [32]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if index < 0 || uint64(index) > uint64(^uint32(0)) {
+		return fmt.Errorf("invalid pre-signature index %d", index)
+	}
+	id := preSignatureID{epoch, index}
+	if found, err := s.consumed.check(id, digest); found {
+		return err
+	}
+	if s.err != nil {
+		return s.err
+	}
+
+	record := binary.LittleEndian.AppendUint64(make([]byte, 0, preSignatureRecordLength), epoch)
+	record = binary.LittleEndian.AppendUint32(record, uint32(index))
+	record = append(record, digest[:]...)
+	record = binary.LittleEndian.AppendUint32(record, crc32.ChecksumIEEE(record))
+
+	// The pre-signature counts as consumed even if persisting fails, it must not be used for another request.
+	s.consumed[id] = digest
+	if _, err := s.file.Write(record); err != nil {
+		s.err = fmt.Errorf("write pre-signature record: %w", err)
+		return s.err
+	}
+	if err := s.file.Sync(); err != nil {
+		s.err = fmt.Errorf("sync pre-signature records: %w", err)
+		return s.err
+	}
+	return nil
+}
+
+// Close closes the file of the store.
+func (s *FilePreSignatureStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,118 @@
+package fhks_bbs_plus_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
+	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation"
+	"github.com/perun-network/bbs-plus-threshold-wallet/test"
+)
+
+func TestFilePreSignatureStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "consumed")
+	store, err := fhks_bbs_plus.OpenFilePreSignatureStore(path)
+	require.NoError(t, err)
+
+	request, other := [32]byte{1}, [32]byte{2}
+	require.NoError(t, store.Consume(1, 0, request))
+	require.NoError(t, store.Consume(1, 0, request), "retrying the same request must succeed")
+	assert.ErrorIs(t, store.Consume(1, 0, other), fhks_bbs_plus.ErrPreSignatureConsumed)
+	require.NoError(t, store.Consume(2, 0, other), "epochs must be separate")
+	require.NoError(t, store.Close())
+
+	// Simulate a crash while appending a record.
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = file.Write([]byte{1, 2, 3})
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	store, err = fhks_bbs_plus.OpenFilePreSignatureStore(path)
+	require.NoError(t, err)
+	assert.ErrorIs(t, store.Consume(1, 0, other), fhks_bbs_plus.ErrPreSignatureConsumed)
+	assert.ErrorIs(t, store.Consume(2, 0, request), fhks_bbs_plus.ErrPreSignatureConsumed)
+	require.NoError(t, store.Consume(1, 1, other))
+	require.NoError(t, store.Close())
+
+	store, err = fhks_bbs_plus.OpenFilePreSignatureStore(path)
+	require.NoError(t, err)
+	assert.ErrorIs(t, store.Consume(1, 1, request), fhks_bbs_plus.ErrPreSignatureConsumed)
+	require.NoError(t, store.Close())
+
+	// A corrupted record that is not the last one must not be skipped.
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	data[0] ^= 1
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	_, err = fhks_bbs_plus.OpenFilePreSignatureStore(path)
+	assert.Error(t, err)
+}
+
+func TestPartialSignatureOnce(t *testing.T) {
+	messages := helper.GetRandomMessagesFromSeed(test.SeedMessages, test.K, test.MessageCount)
+	sk, preComputation := precomputation.GeneratePPPrecomputationMock(test.SeedPresignatures, test.Threshold, test.K, test.N)
+	pk := fhks_bbs_plus.GeneratePublicKey(test.SeedKeys, sk, test.MessageCount)
+
+	ownIndex := test.Indices[0][0]
+	keyShare := partySecretKey(preComputation, ownIndex)
+	store := fhks_bbs_plus.NewMemoryPreSignatureStore()
+	livePreSignature := func(signerSet []int) *fhks_bbs_plus.LivePreSignature {
+		return fhks_bbs_plus.NewLivePreSignature().FromPreSignature(ownIndex, signerSet, preComputation[ownIndex-1].PreSignatures[0])
+	}
+
+	signerSet := test.Indices[0]
+	partialSignature, err := fhks_bbs_plus.NewPartialThresholdSignature().NewOnce(store, 0, signerSet, messages[0], pk, keyShare, livePreSignature(signerSet))
+	require.NoError(t, err)
+	expected, err := fhks_bbs_plus.NewPartialThresholdSignature().New(messages[0], pk, keyShare, livePreSignature(signerSet))
+	require.NoError(t, err)
+	assert.Equal(t, expected, partialSignature)
+
+	_, err = fhks_bbs_plus.NewPartialThresholdSignature().NewOnce(store, 0, []int{5, 3, 1}, messages[0], pk, keyShare, livePreSignature(signerSet))
+	assert.NoError(t, err, "the same request may be signed again")
+	_, err = fhks_bbs_plus.NewPartialThresholdSignature().NewOnce(store, 0, signerSet, messages[1], pk, keyShare, livePreSignature(signerSet))
+	assert.ErrorIs(t, err, fhks_bbs_plus.ErrPreSignatureConsumed)
+	_, err = fhks_bbs_plus.NewPartialThresholdSignature().NewOnce(store, 0, test.Indices[1], messages[0], pk, keyShare, livePreSignature(test.Indices[1]))
+	assert.ErrorIs(t, err, fhks_bbs_plus.ErrPreSignatureConsumed, "another signer set is another request")
+	_, err = fhks_bbs_plus.NewPartialBBSSignature().NewOnce(store, 0, signerSet, messages[0], pk, nil, keyShare, livePreSignature(signerSet).BBS())
+	assert.ErrorIs(t, err, fhks_bbs_plus.ErrPreSignatureConsumed)
+
+	// A retry for a subset of the signer set, e.g. by FromPartialSignaturesRobust, needs a fresh pre-signature.
+	retrySignerSet := []int{1, 5}
+	_, err = fhks_bbs_plus.NewPartialThresholdSignature().NewOnce(store, 0, retrySignerSet, messages[0], pk, keyShare, livePreSignature(retrySignerSet))
+	assert.ErrorIs(t, err, fhks_bbs_plus.ErrPreSignatureConsumed)
+	fresh := fhks_bbs_plus.NewLivePreSignature().FromPreSignature(ownIndex, retrySignerSet, preComputation[ownIndex-1].PreSignatures[1])
+	_, err = fhks_bbs_plus.NewPartialThresholdSignature().NewOnce(store, 1, retrySignerSet, messages[0], pk, keyShare, fresh)
+	assert.NoError(t, err)
+}
+
+func TestPartialSignatureStaleEpoch(t *testing.T) {
+	messages := helper.GetRandomMessagesFromSeed(test.SeedMessages, test.K, test.MessageCount)
+	sk, preComputation := precomputation.GeneratePPPrecomputationMock(test.SeedPresignatures, test.Threshold, test.K, test.N)
+	pk := fhks_bbs_plus.GeneratePublicKey(test.SeedKeys, sk, test.MessageCount)
+
+	// The key share was refreshed, the pre-signatures still belong to epoch 0.
+	ownIndex := test.Indices[0][0]
+	keyShare := partySecretKey(preComputation, ownIndex)
+	keyShare.Epoch = 1
+	preSignature := preComputation[ownIndex-1].PreSignatures[0]
+	livePreSignature := fhks_bbs_plus.NewLivePreSignature().FromPreSignature(ownIndex, test.Indices[0], preSignature)
+	assert.Equal(t, preSignature.Epoch, livePreSignature.Epoch)
+
+	_, err := fhks_bbs_plus.NewPartialThresholdSignature().New(messages[0], pk, keyShare, livePreSignature)
+	assert.ErrorIs(t, err, fhks_bbs_plus.ErrEpochMismatch)
+	_, err = fhks_bbs_plus.NewPartialBBSSignature().New(messages[0], pk, nil, keyShare, livePreSignature.BBS())
+	assert.ErrorIs(t, err, fhks_bbs_plus.ErrEpochMismatch)
+
+	store := fhks_bbs_plus.NewMemoryPreSignatureStore()
+	_, err = fhks_bbs_plus.NewPartialThresholdSignature().NewOnce(store, 0, test.Indices[0], messages[0], pk, keyShare, livePreSignature)
+	assert.ErrorIs(t, err, fhks_bbs_plus.ErrEpochMismatch)
+	keyShare.Epoch = 0
+	_, err = fhks_bbs_plus.NewPartialThresholdSignature().NewOnce(store, 0, test.Indices[0], messages[1], pk, keyShare, livePreSignature)
+	assert.NoError(t, err, "rejected pre-signatures must not be consumed")
+}
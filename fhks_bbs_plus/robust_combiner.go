@@ -0,0 +1,284 @@
+package fhks_bbs_plus
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	bls12381 "github.com/kilic/bls12-381"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
+)
+
+// ErrNoValidSubset is returned by FromPartialSignaturesRobust if fewer than threshold parties provide partial
+// signatures that match their commitments.
+var ErrNoValidSubset = errors.New("not enough valid partial signatures to combine a signature")
+
+// IndexedPartialSignature is a partial signature tagged with the index of the party that computed it and the
+// commitment to the pre-signature it was computed from.
+type IndexedPartialSignature struct {
+	Index            int
+	PartialSignature *PartialThresholdSignature
+	Commitment       *PreSignatureCommitment
+}
+
+// PartialSignatureRequester asks party index for a partial signature on the same messages, computed for the given
+// signer set. As a pre-signature must not be used for two signer sets, the party computes it with NewOnce from a fresh
+// pre-signature, which is the same for all parties of the signer set, and returns the commitment to it.
+type PartialSignatureRequester func(index int, signerSet []int) (*IndexedPartialSignature, error)
+
+// CombineReport describes how FromPartialSignaturesRobust arrived at its result.
+type CombineReport struct {
+	SignerSet          []int    // Signer set of the returned signature, nil if none was found.
+	FailedSubsets      [][]int  // Signer sets whose partial signatures did not yield a valid signature, in order.
+	FaultyParties      []int    // Parties whose commitments or partial signatures are invalid.
+	ConflictingParties [][2]int // Pairs of parties whose commitments conflict, at least one of each pair is faulty.
+	UnreachableParties []int    // Parties that did not answer a request for a partial signature.
+}
+
+// FromPartialSignaturesRobust combines a valid signature from a superset of partial signatures.
+// All given partial signatures must be computed for the signer set formed by their indices. The pre-signature
+// commitments of the signer set are first cross-checked against each other and the public keys of the parties (see
+// CrossCheckPreSignatureCommitments), then every partial signature is checked against the live commitment of its
+// party (see PartialThresholdSignature.Verify). If all parties pass, their partial signatures are combined.
+// Otherwise, the blamed parties are excluded. As honest parties never conflict with each other, the parties with the
+// most conflicting commitments (see CommitmentConflictError) are excluded one after another; once only isolated pairs
+// conflict, both parties of each pair are excluded. Then new partial
+// signatures of the first threshold remaining parties are requested for their signer set via request and checked in
+// the same way. Parties that fail the checks or do not answer are excluded as well, until a signer set of valid
+// partial signatures is found or fewer than threshold parties remain. request may be nil, in which case only the given
+// partial signatures are combined. partyPublicKeys must contain the public key of every party that responded.
+func (ts *ThresholdSignature) FromPartialSignaturesRobust(
+	messages []*bls12381.Fr,
+	pk *PublicKey,
+	threshold int,
+	partialSignatures []*IndexedPartialSignature,
+	partyPublicKeys []*PartyPublicKey,
+	request PartialSignatureRequester,
+) (*ThresholdSignature, *CombineReport, error) {
+	if threshold < 1 {
+		return nil, nil, fmt.Errorf("invalid threshold %d", threshold)
+	}
+	if len(partialSignatures) < threshold {
+		return nil, nil, fmt.Errorf("need at least %d partial signatures, got %d", threshold, len(partialSignatures))
+	}
+
+	publicKeys := make(map[int]*PartyPublicKey, len(partyPublicKeys))
+	for _, ppk := range partyPublicKeys {
+		publicKeys[ppk.Index] = ppk
+	}
+	responders := make([]int, len(partialSignatures))
+	given := make(map[int]*IndexedPartialSignature, len(partialSignatures))
+	for i, ps := range partialSignatures {
+		if _, ok := given[ps.Index]; ok {
+			return nil, nil, fmt.Errorf("duplicate partial signature of party %d", ps.Index)
+		}
+		if _, ok := publicKeys[ps.Index]; !ok {
+			return nil, nil, fmt.Errorf("missing public key of party %d", ps.Index)
+		}
+		given[ps.Index] = ps
+		responders[i] = ps.Index
+	}
+	sort.Ints(responders)
+
+	report := &CombineReport{}
+
+	// check checks the partial signatures of the signer set and returns the parties that passed all checks.
+	check := func(signerSet []int, partials map[int]*IndexedPartialSignature) ([]int, error) {
+		var present []int
+		var commitments []*PreSignatureCommitment
+		var keys []*PartyPublicKey
+		for _, index := range signerSet {
+			if partial, ok := partials[index]; ok {
+				if partial.PartialSignature == nil || partial.Commitment == nil || !partial.Commitment.covers(signerSet) {
+					report.FaultyParties = append(report.FaultyParties, index)
+					continue
+				}
+				present = append(present, index)
+				commitments = append(commitments, partial.Commitment)
+				keys = append(keys, publicKeys[index])
+			}
+		}
+
+		excluded := make(map[int]bool)
+		crossCheck := CrossCheckPreSignatureCommitments(present, commitments, keys)
+		var findings []error
+		if joined, ok := crossCheck.(interface{ Unwrap() []error }); ok {
+			findings = joined.Unwrap()
+		} else if crossCheck != nil {
+			findings = []error{crossCheck}
+		}
+		var conflicts [][2]int
+		seen := make(map[[2]int]bool)
+		for _, finding := range findings {
+			var pse *PartialSignatureError
+			var cce *CommitmentConflictError
+			switch {
+			case errors.As(finding, &pse):
+				excluded[pse.Index] = true
+				report.FaultyParties = append(report.FaultyParties, pse.Index)
+			case errors.As(finding, &cce):
+				pair := [2]int{min(cce.Indices[0], cce.Indices[1]), max(cce.Indices[0], cce.Indices[1])}
+				if !seen[pair] {
+					seen[pair] = true
+					conflicts = append(conflicts, pair)
+				}
+			default:
+				return nil, finding
+			}
+		}
+		report.ConflictingParties = append(report.ConflictingParties, conflicts...)
+		excludeConflicting(present, conflicts, excluded)
+
+		lagrangeCoefficients := helper.Get0LagrangeCoefficientSetFr(signerSet)
+		var passed []int
+		for i, index := range present {
+			if excluded[index] {
+				continue
+			}
+			commitment, err := commitments[i].LiveWithCoefficients(index, signerSet, lagrangeCoefficients)
+			if err != nil {
+				return nil, err
+			}
+			if partials[index].PartialSignature.Verify(messages, pk, index, commitment) != nil {
+				report.FaultyParties = append(report.FaultyParties, index)
+				continue
+			}
+			passed = append(passed, index)
+		}
+		if len(passed) < len(signerSet) {
+			report.FailedSubsets = append(report.FailedSubsets, signerSet)
+		}
+		return passed, nil
+	}
+
+	finish := func() {
+		sort.Ints(report.FaultyParties)
+		sort.Ints(report.UnreachableParties)
+		sort.Slice(report.ConflictingParties, func(i, j int) bool {
+			a, b := report.ConflictingParties[i], report.ConflictingParties[j]
+			return a[0] < b[0] || a[0] == b[0] && a[1] < b[1]
+		})
+	}
+
+	// combine combines the partial signatures of the signer set, which all passed the checks.
+	combine := func(signerSet []int, partials map[int]*IndexedPartialSignature) (*ThresholdSignature, *CombineReport, error) {
+		ordered := make([]*PartialThresholdSignature, len(signerSet))
+		for i, index := range signerSet {
+			ordered[i] = partials[index].PartialSignature
+		}
+		finish()
+		signature := NewThresholdSignature().FromPartialSignatures(ordered)
+		if !signature.Verify(messages, pk) {
+			report.FailedSubsets = append(report.FailedSubsets, signerSet)
+			return nil, report, errors.New("partial signatures match the cross-checked commitments, but the signature is invalid")
+		}
+		report.SignerSet = signerSet
+		return ts.set(signature), report, nil
+	}
+
+	candidates, err := check(responders, given)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(candidates) == len(responders) {
+		return combine(responders, given)
+	}
+	if request == nil {
+		finish()
+		return nil, report, ErrNoValidSubset
+	}
+
+	// Every round either succeeds or excludes at least one party, hence there are at most n - threshold + 1 rounds.
+	for len(candidates) >= threshold {
+		signerSet := candidates[:threshold:threshold]
+		partials := make(map[int]*IndexedPartialSignature, threshold)
+		for _, index := range signerSet {
+			partial, err := request(index, signerSet)
+			if err != nil || partial == nil || partial.Index != index {
+				report.UnreachableParties = append(report.UnreachableParties, index)
+				continue
+			}
+			partials[index] = partial
+		}
+		passed, err := check(signerSet, partials)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(passed) == threshold {
+			return combine(signerSet, partials)
+		}
+
+		excluded := make(map[int]bool, threshold-len(passed))
+		for _, index := range signerSet {
+			excluded[index] = true
+		}
+		for _, index := range passed {
+			excluded[index] = false
+		}
+		remaining := make([]int, 0, len(candidates))
+		for _, index := range candidates {
+			if !excluded[index] {
+				remaining = append(remaining, index)
+			}
+		}
+		candidates = remaining
+	}
+
+	finish()
+	return nil, report, ErrNoValidSubset
+}
+
+// excludeConflicting resolves the conflicts between the parties. Honest parties never conflict with each other, so
+// every conflict involves a faulty party. The party with the most conflicts is excluded until no conflicts remain or
+// every party has at most one, in which case both parties of each remaining conflict are excluded.
+func excludeConflicting(parties []int, conflicts [][2]int, excluded map[int]bool) {
+	for len(conflicts) > 0 {
+		degree := make(map[int]int)
+		for _, pair := range conflicts {
+			degree[pair[0]]++
+			degree[pair[1]]++
+		}
+		worst, most := 0, 0
+		for _, index := range parties {
+			if degree[index] > most {
+				worst, most = index, degree[index]
+			}
+		}
+		if most == 1 {
+			for _, pair := range conflicts {
+				excluded[pair[0]], excluded[pair[1]] = true, true
+			}
+			return
+		}
+
+		excluded[worst] = true
+		remaining := conflicts[:0]
+		for _, pair := range conflicts {
+			if pair[0] != worst && pair[1] != worst {
+				remaining = append(remaining, pair)
+			}
+		}
+		conflicts = remaining
+	}
+}
+
+// covers reports whether the commitment holds the cross terms of all parties of the signer set.
+func (psc *PreSignatureCommitment) covers(signerSet []int) bool {
+	for _, terms := range [][]*bls12381.PointG2{
+		psc.AeTermsA, psc.AeTermsE, psc.AsTermsA, psc.AsTermsS, psc.AskTermsA, psc.AskTermsSK} {
+		for _, index := range signerSet {
+			if index < 1 || index > len(terms) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (ts *ThresholdSignature) set(signature *ThresholdSignature) *ThresholdSignature {
+	ts.CapitalA.Set(signature.CapitalA)
+	ts.E.Set(signature.E)
+	ts.S.Set(signature.S)
+	return ts
+}
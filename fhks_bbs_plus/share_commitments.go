@@ -0,0 +1,130 @@
+package fhks_bbs_plus
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	bls12381 "github.com/kilic/bls12-381"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
+)
+
+// ShareCommitments are the Feldman commitments g_2^(f_k) to the coefficients of the polynomial f that shares the
+// secret key, starting with W = g_2^f(0). Party i holds the share f(helper.ShamirPositionFr(i)).
+type ShareCommitments []*bls12381.PointG2
+
+// PartyPublicKey is the public verification key g_2^(sk_i) of the party with the 1-based Index.
+type PartyPublicKey struct {
+	PublicShare *bls12381.PointG2
+	Index       int
+}
+
+// PublicKey returns W = g_2^sk.
+func (c ShareCommitments) PublicKey() *bls12381.PointG2 {
+	return c[0]
+}
+
+// PartyPublicKey derives the public verification key of the party with the 1-based index from the commitments.
+func (c ShareCommitments) PartyPublicKey(index int) *PartyPublicKey {
+	return &PartyPublicKey{
+		PublicShare: helper.EvaluateFeldmanCommitments(c, index),
+		Index:       index,
+	}
+}
+
+func (c ShareCommitments) Marshal() ([]byte, error) {
+	g2 := bls12381.NewG2()
+
+	bytes := make([]byte, 4, 4+len(c)*helper.LenBytesG2Compressed)
+	binary.LittleEndian.PutUint32(bytes, uint32(len(c)))
+	for _, commitment := range c {
+		bytes = append(bytes, g2.ToCompressed(commitment)...)
+	}
+
+	return bytes, nil
+}
+
+func UnmarshalShareCommitments(commitmentsBytes []byte) (ShareCommitments, error) {
+	g2 := bls12381.NewG2()
+
+	if len(commitmentsBytes) < 4 {
+		return nil, fmt.Errorf("%w: invalid size of share commitments", ErrInvalidEncoding)
+	}
+	count := int(binary.LittleEndian.Uint32(commitmentsBytes))
+	if count == 0 || count > (len(commitmentsBytes)-4)/helper.LenBytesG2Compressed || len(commitmentsBytes) != 4+count*helper.LenBytesG2Compressed {
+		return nil, fmt.Errorf("%w: invalid size of share commitments", ErrInvalidEncoding)
+	}
+
+	commitments := make(ShareCommitments, count)
+	for k := range commitments {
+		offset := 4 + k*helper.LenBytesG2Compressed
+		commitment, err := g2.FromCompressed(commitmentsBytes[offset : offset+helper.LenBytesG2Compressed])
+		if err != nil {
+			return nil, fmt.Errorf("%w: deserialize G2 compressed commitment %d: %w", ErrInvalidEncoding, k, err)
+		}
+		commitments[k] = commitment
+	}
+
+	return commitments, nil
+}
+
+// PartyPublicKey returns the public verification key g_2^(sk_i) of the party.
+func (ppk *PartySecretKey) PartyPublicKey() *PartyPublicKey {
+	return &PartyPublicKey{
+		PublicShare: commitFr(ppk.SKeyShare.Fr),
+		Index:       ppk.Index,
+	}
+}
+
+// VerifyShare checks that the share of the party is consistent with the Feldman commitments of the sharing and that
+// the commitments share the public key of the party.
+func (ppk *PartySecretKey) VerifyShare(commitments ShareCommitments) error {
+	g2 := bls12381.NewG2()
+
+	if len(commitments) == 0 {
+		return errors.New("no share commitments")
+	}
+	if ppk.Index < 1 {
+		return fmt.Errorf("invalid party index %d", ppk.Index)
+	}
+	if !g2.Equal(commitments.PublicKey(), ppk.PublicKey) {
+		return errors.New("commitments do not match the public key")
+	}
+	if !g2.Equal(commitments.PartyPublicKey(ppk.Index).PublicShare, commitFr(ppk.SKeyShare.Fr)) {
+		return fmt.Errorf("share of party %d does not match the commitments", ppk.Index)
+	}
+
+	return nil
+}
+
+func (ppk *PartyPublicKey) Marshal() ([]byte, error) {
+	g2 := bls12381.NewG2()
+
+	publicShareBytes := g2.ToCompressed(ppk.PublicShare)
+
+	indexBytes := make([]byte, 4)
+	binary.LittleEndian.PutUint32(indexBytes, uint32(ppk.Index))
+
+	return append(publicShareBytes, indexBytes...), nil
+}
+
+func UnmarshalPartyPublicKey(partyPubKeyBytes []byte) (*PartyPublicKey, error) {
+	g2 := bls12381.NewG2()
+
+	if len(partyPubKeyBytes) != helper.LenBytesG2Compressed+4 {
+		return nil, fmt.Errorf("%w: invalid size of party public key", ErrInvalidEncoding)
+	}
+
+	publicShare, err := g2.FromCompressed(partyPubKeyBytes[:helper.LenBytesG2Compressed])
+	if err != nil {
+		return nil, fmt.Errorf("%w: deserialize G2 compressed public share: %w", ErrInvalidEncoding, err)
+	}
+
+	index := int(binary.LittleEndian.Uint32(partyPubKeyBytes[helper.LenBytesG2Compressed:]))
+
+	return &PartyPublicKey{
+		PublicShare: publicShare,
+		Index:       index,
+	}, nil
+}
@@ -0,0 +1,83 @@
+package fhks_bbs_plus_test
+
+import (
+	"crypto/rand"
+	"testing"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
+)
+
+func TestVerifyShare(t *testing.T) {
+	g2 := bls12381.NewG2()
+	threshold, n := 3, 5
+
+	sk := fhks_bbs_plus.GenerateRandomFr()
+	shares, commitmentPoints := helper.ShamirSharedSecretKeyWithCommitments(sk, threshold, n)
+	commitments := fhks_bbs_plus.ShareCommitments(commitmentPoints)
+	w := commitSecretKey(sk)
+
+	for i, share := range shares {
+		psk := &fhks_bbs_plus.PartySecretKey{
+			SKeyShare: fhks_bbs_plus.SecretKey{Fr: share},
+			PublicKey: w,
+			Index:     i + 1,
+		}
+		assert.NoError(t, psk.VerifyShare(commitments), "party %d", i+1)
+		assert.True(t, g2.Equal(psk.PartyPublicKey().PublicShare, commitments.PartyPublicKey(i+1).PublicShare),
+			"public share of party %d", i+1)
+	}
+
+	// A share handed to the wrong party does not verify.
+	wrongIndex := &fhks_bbs_plus.PartySecretKey{SKeyShare: fhks_bbs_plus.SecretKey{Fr: shares[0]}, PublicKey: w, Index: 2}
+	assert.Error(t, wrongIndex.VerifyShare(commitments))
+
+	// A share of a different public key does not verify.
+	otherW := commitSecretKey(fhks_bbs_plus.GenerateRandomFr())
+	wrongKey := &fhks_bbs_plus.PartySecretKey{SKeyShare: fhks_bbs_plus.SecretKey{Fr: shares[0]}, PublicKey: otherW, Index: 1}
+	assert.Error(t, wrongKey.VerifyShare(commitments))
+}
+
+func TestShareCommitmentsSerializationDeserialization(t *testing.T) {
+	g2 := bls12381.NewG2()
+
+	_, _, commitmentPoints := helper.GetShamirSharedRandomElementWithCommitments(rand.Reader, 3, 5)
+	commitments := fhks_bbs_plus.ShareCommitments(commitmentPoints)
+
+	bytes, err := commitments.Marshal()
+	require.NoError(t, err)
+	unmarshalled, err := fhks_bbs_plus.UnmarshalShareCommitments(bytes)
+	require.NoError(t, err)
+	require.Len(t, unmarshalled, len(commitments))
+	for k := range commitments {
+		assert.True(t, g2.Equal(commitments[k], unmarshalled[k]), "commitment %d mismatch", k)
+	}
+
+	_, err = fhks_bbs_plus.UnmarshalShareCommitments(bytes[:len(bytes)-1])
+	assert.Error(t, err)
+}
+
+func TestPartyPublicKeySerializationDeserialization(t *testing.T) {
+	g2 := bls12381.NewG2()
+
+	original := &fhks_bbs_plus.PartyPublicKey{PublicShare: commitSecretKey(fhks_bbs_plus.GenerateRandomFr()), Index: 17}
+
+	bytes, err := original.Marshal()
+	require.NoError(t, err)
+	unmarshalled, err := fhks_bbs_plus.UnmarshalPartyPublicKey(bytes)
+	require.NoError(t, err)
+
+	assert.True(t, g2.Equal(original.PublicShare, unmarshalled.PublicShare), "PublicShare mismatch")
+	assert.Equal(t, original.Index, unmarshalled.Index, "Index mismatch")
+}
+
+func commitSecretKey(sk *bls12381.Fr) *bls12381.PointG2 {
+	g2 := bls12381.NewG2()
+	w := g2.New()
+	g2.MulScalar(w, g2.One(), sk)
+	return w
+}
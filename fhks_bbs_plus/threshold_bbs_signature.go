@@ -0,0 +1,108 @@
+package fhks_bbs_plus
+
+import (
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+// LiveBBSPreSignature is the share of a pre-computed BBS signature of a signer. Compared to LivePreSignature it has
+// no share of s and alpha, delta is a share of a * (sk + e).
+type LiveBBSPreSignature struct {
+	AShare     *bls12381.Fr
+	EShare     *bls12381.Fr
+	DeltaShare *bls12381.Fr
+	Epoch      uint64 // Epoch of the secret key share the pre-signature is derived from.
+}
+
+func NewLiveBBSPreSignature() *LiveBBSPreSignature {
+	return &LiveBBSPreSignature{
+		AShare:     bls12381.NewFr().Zero(),
+		EShare:     bls12381.NewFr().Zero(),
+		DeltaShare: bls12381.NewFr().Zero(),
+	}
+}
+
+func (lps *LiveBBSPreSignature) GetEpoch() uint64 {
+	return lps.Epoch
+}
+
+func NewLiveBBSPreSignatureFromValues(aShare, eShare, deltaShare *bls12381.Fr) *LiveBBSPreSignature {
+	lps := NewLiveBBSPreSignature()
+	lps.AShare.Set(aShare)
+	lps.EShare.Set(eShare)
+	lps.DeltaShare.Set(deltaShare)
+	return lps
+}
+
+// BBS drops s and alpha of a BBS+ pre-signature. delta is a share of a * (sk + e) in both schemes, so that BBS+
+// pre-signatures can be used for BBS signatures as well.
+func (lps *LivePreSignature) BBS() *LiveBBSPreSignature {
+	bbs := NewLiveBBSPreSignatureFromValues(lps.AShare, lps.EShare, lps.DeltaShare)
+	bbs.Epoch = lps.Epoch
+	return bbs
+}
+
+// PartialBBSSignature is the share of a threshold BBS signature (A, e) as defined by the IETF BBS draft.
+type PartialBBSSignature struct {
+	CapitalAShare *bls12381.PointG1
+	DeltaShare    *bls12381.Fr
+	EShare        *bls12381.Fr
+}
+
+func NewPartialBBSSignature() *PartialBBSSignature {
+	return &PartialBBSSignature{
+		CapitalAShare: bls12381.NewG1().Zero(),
+		DeltaShare:    bls12381.NewFr().Zero(),
+		EShare:        bls12381.NewFr().Zero(),
+	}
+}
+
+// New computes the partial signature on messages and header. The share of A is a_i * B for the IETF basis B.
+// It returns ErrEpochMismatch if the pre-signature was derived from a secret key share of another epoch than keyShare.
+func (pbs *PartialBBSSignature) New(messages []*bls12381.Fr, pk *PublicKey, header []byte, keyShare *PartySecretKey, preSignature *LiveBBSPreSignature) (*PartialBBSSignature, error) {
+	if err := keyShare.CheckEpoch(preSignature.Epoch); err != nil {
+		return nil, err
+	}
+	_, basis, err := pk.IETFBasis(header, messages)
+	if err != nil {
+		return nil, err
+	}
+
+	g1 := bls12381.NewG1()
+	capitalAShare := g1.New()
+	g1.MulScalar(capitalAShare, basis, preSignature.AShare)
+
+	pbs.CapitalAShare.Set(capitalAShare)
+	pbs.DeltaShare.Set(preSignature.DeltaShare)
+	pbs.EShare.Set(preSignature.EShare)
+	return pbs, nil
+}
+
+func NewIETFSignature() *IETFSignature {
+	return &IETFSignature{
+		CapitalA: bls12381.NewG1().Zero(),
+		E:        bls12381.NewFr().Zero(),
+	}
+}
+
+// FromPartialSignatures combines the partial signatures of all signers to A = (sum_i A_i) / delta and e = sum_i e_i.
+func (s *IETFSignature) FromPartialSignatures(partialSignatures []*PartialBBSSignature) *IETFSignature {
+	g1 := bls12381.NewG1()
+	delta := bls12381.NewFr().Zero()
+	e := bls12381.NewFr().Zero()
+	capitalA := g1.Zero()
+
+	for _, partialSignature := range partialSignatures {
+		delta.Add(delta, partialSignature.DeltaShare)
+		e.Add(e, partialSignature.EShare)
+		g1.Add(capitalA, capitalA, partialSignature.CapitalAShare)
+	}
+
+	epsilon := bls12381.NewFr()
+	epsilon.Inverse(delta)
+
+	g1.MulScalar(capitalA, capitalA, epsilon)
+
+	s.CapitalA.Set(capitalA)
+	s.E.Set(e)
+	return s
+}
@@ -1,6 +1,7 @@
 package fhks_bbs_plus
 
 import (
+	"errors"
 	"fmt"
 	bls12381 "github.com/kilic/bls12-381"
 	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
@@ -19,6 +20,8 @@ func NewThresholdSignature() *ThresholdSignature {
 		S:        bls12381.NewFr().Zero(),
 	}
 }
+
+// ToBytes encodes the signature in an envelope with the payload A | e | s.
 func (s *ThresholdSignature) ToBytes() ([]byte, error) {
 	bytes := make([]byte, helper.LenBytesG1Compressed+2*helper.LenBytesFr)
 
@@ -33,31 +36,33 @@ func (s *ThresholdSignature) ToBytes() ([]byte, error) {
 	sBytes := s.S.ToBytes()
 	copy(bytes[helper.LenBytesG1Compressed+helper.LenBytesFr:], sBytes)
 
-	return bytes, nil
+	return encodeEnvelope(ObjectThresholdSignature, "", bytes)
 }
 
 func ThresholdSignatureFromBytes(data []byte) (*ThresholdSignature, error) {
-	if len(data) != helper.LenBytesG1Compressed+2*helper.LenBytesFr {
-		return nil, fmt.Errorf("invalid serialized signature length: expected %d, got %d", helper.LenBytesG1Compressed+2*helper.LenBytesFr, len(data))
-	}
-
-	g1 := bls12381.NewG1()
-	capitalA, err := g1.FromCompressed(data[:helper.LenBytesG1Compressed])
+	_, payload, err := decodeEnvelope(ObjectThresholdSignature, data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to deserialize CapitalA: %v", err)
+		return nil, err
 	}
+	return ThresholdSignatureFromLegacyBytes(payload)
+}
 
-	e := bls12381.NewFr()
-	e.FromBytes(data[helper.LenBytesG1Compressed : helper.LenBytesG1Compressed+helper.LenBytesFr])
-
-	s := bls12381.NewFr()
-	s.FromBytes(data[helper.LenBytesG1Compressed+helper.LenBytesFr:])
+// ThresholdSignatureFromLegacyBytes reads the raw encoding A | e | s of a signature without envelope.
+func ThresholdSignatureFromLegacyBytes(data []byte) (*ThresholdSignature, error) {
+	if len(data) != helper.LenBytesG1Compressed+2*helper.LenBytesFr {
+		return nil, fmt.Errorf("%w: invalid serialized signature length: expected %d, got %d", ErrInvalidEncoding, helper.LenBytesG1Compressed+2*helper.LenBytesFr, len(data))
+	}
 
-	return &ThresholdSignature{
-		CapitalA: capitalA,
-		E:        e,
-		S:        s,
-	}, nil
+	r := &encodingReader{data: data}
+	signature := &ThresholdSignature{
+		CapitalA: r.g1(),
+		E:        r.fr(),
+		S:        r.fr(),
+	}
+	if err := r.finish(); err != nil {
+		return nil, fmt.Errorf("decode signature: %w", err)
+	}
+	return signature, nil
 }
 
 func (ts *ThresholdSignature) FromPartialSignatures(partialSignatures []*PartialThresholdSignature) *ThresholdSignature {
@@ -85,6 +90,61 @@ func (ts *ThresholdSignature) FromPartialSignatures(partialSignatures []*Partial
 	return ts
 }
 
+// FromVerifiedPartialSignatures checks every partial signature against the commitment to the live pre-signature of
+// the corresponding party before combining them. indices, partialSignatures and commitments are aligned.
+// If any partial signature is invalid, the signature is not combined and the returned error joins one
+// *PartialSignatureError per offending party (see FaultyParties).
+func (ts *ThresholdSignature) FromVerifiedPartialSignatures(
+	messages []*bls12381.Fr,
+	pk *PublicKey,
+	indices []int,
+	partialSignatures []*PartialThresholdSignature,
+	commitments []*LivePreSignatureCommitment,
+) (*ThresholdSignature, error) {
+	if len(indices) != len(partialSignatures) || len(indices) != len(commitments) {
+		return nil, errors.New("number of indices, partial signatures and commitments must match")
+	}
+
+	var errs []error
+	for i, partialSignature := range partialSignatures {
+		if err := partialSignature.Verify(messages, pk, indices[i], commitments[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return ts.FromPartialSignatures(partialSignatures), nil
+}
+
+// FromCrossCheckedPartialSignatures works like FromVerifiedPartialSignatures, but derives the commitments to the live
+// pre-signatures from the pre-signature commitments of the parties, after cross-checking them with
+// CrossCheckPreSignatureCommitments. Thus, a party cannot pass the verification with commitments that are consistent
+// with its own partial signature only. indices, partialSignatures, commitments and partyPublicKeys are aligned.
+func (ts *ThresholdSignature) FromCrossCheckedPartialSignatures(
+	messages []*bls12381.Fr,
+	pk *PublicKey,
+	indices []int,
+	partialSignatures []*PartialThresholdSignature,
+	commitments []*PreSignatureCommitment,
+	partyPublicKeys []*PartyPublicKey,
+) (*ThresholdSignature, error) {
+	if err := CrossCheckPreSignatureCommitments(indices, commitments, partyPublicKeys); err != nil {
+		return nil, err
+	}
+
+	lagrangeCoefficients := helper.Get0LagrangeCoefficientSetFr(indices)
+	liveCommitments := make([]*LivePreSignatureCommitment, len(commitments))
+	for i, commitment := range commitments {
+		var err error
+		if liveCommitments[i], err = commitment.LiveWithCoefficients(indices[i], indices, lagrangeCoefficients); err != nil {
+			return nil, err
+		}
+	}
+	return ts.FromVerifiedPartialSignatures(messages, pk, indices, partialSignatures, liveCommitments)
+}
+
 func (ts *ThresholdSignature) FromSecretKey(
 	pk *PublicKey,
 	sk *bls12381.Fr,
@@ -93,16 +153,7 @@ func (ts *ThresholdSignature) FromSecretKey(
 	messages []*bls12381.Fr,
 ) *ThresholdSignature {
 	g1 := bls12381.NewG1()
-	h0s := g1.New().Set(pk.H0)
-	g1.MulScalar(h0s, h0s, s)
-	capitalA := g1.One()
-	g1.Add(capitalA, capitalA, h0s)
-
-	for i, message := range messages {
-		tmp := g1.New().Set(pk.H[i])
-		g1.MulScalar(tmp, tmp, message)
-		g1.Add(capitalA, capitalA, tmp)
-	}
+	capitalA := pk.signingBasis(messages, s)
 
 	ske := bls12381.NewFr().Set(sk)
 	ske.Add(sk, e)
@@ -119,30 +170,5 @@ func (ts *ThresholdSignature) FromSecretKey(
 }
 
 func (ts *ThresholdSignature) Verify(messages []*bls12381.Fr, pk *PublicKey) bool {
-	g1 := bls12381.NewG1()
-	h0s := g1.New().Set(pk.H0)
-	g1.MulScalar(h0s, h0s, ts.S)
-
-	verificationBasis := g1.One()
-	g1.Add(verificationBasis, verificationBasis, h0s)
-	for i, message := range messages {
-		tmp := g1.New().Set(pk.H[i])
-		g1.MulScalar(tmp, tmp, message)
-		g1.Add(verificationBasis, verificationBasis, tmp)
-	}
-
-	// Compute u = w * g_2^e = g_2^sk * g_2^e
-	g2 := bls12381.NewG2()
-	u := g2.One()
-	g2.MulScalar(u, u, ts.E)
-	g2.Add(u, u, pk.W)
-
-	// Compute t1 = e(A,u)
-	t1 := bls12381.NewEngine().AddPair(ts.CapitalA, u).Result()
-
-	//Different basis
-	//Compute t2 = e(basis, g_2)
-	t2 := bls12381.NewEngine().AddPair(verificationBasis, g2.One()).Result()
-
-	return t1.Equal(t2)
+	return pk.Verify(messages, ts)
 }
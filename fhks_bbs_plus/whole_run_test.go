@@ -1,10 +1,14 @@
 package fhks_bbs_plus_test
 
 import (
+	"errors"
+	"fmt"
+	"slices"
 	"testing"
 
 	bls12381 "github.com/kilic/bls12-381"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
 	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
@@ -24,15 +28,17 @@ func TestSimpleSigningMockedPre(t *testing.T) {
 		partialSignatures := make([]*fhks_bbs_plus.PartialThresholdSignature, test.Threshold)
 		for iT := 0; iT < test.Threshold; iT++ {
 			ownIndex := test.Indices[iK][iT]
-			x := fhks_bbs_plus.NewPartialThresholdSignature().New(
+			x, err := fhks_bbs_plus.NewPartialThresholdSignature().New(
 				messages[iK],
 				pk,
+				partySecretKey(preComputation, ownIndex),
 				fhks_bbs_plus.NewLivePreSignature().FromPreSignature(
 					ownIndex,
 					test.Indices[iK],
 					preComputation[ownIndex-1].PreSignatures[iK],
 				),
 			)
+			assert.NoError(t, err)
 			partialSignatures[iT] = x
 		}
 		signature := fhks_bbs_plus.NewThresholdSignature().FromPartialSignatures(partialSignatures)
@@ -43,6 +49,214 @@ func TestSimpleSigningMockedPre(t *testing.T) {
 	}
 }
 
+func TestVerifiedSigningMockedPre(t *testing.T) {
+	messages := helper.GetRandomMessagesFromSeed(test.SeedMessages, test.K, test.MessageCount)
+
+	sk, preComputation := precomputation.GeneratePPPrecomputationMock(test.SeedPresignatures, test.Threshold, test.K, test.N)
+
+	pk := fhks_bbs_plus.GeneratePublicKey(test.SeedKeys, sk, test.MessageCount)
+
+	for iK := 0; iK < test.K; iK++ {
+		partialSignatures := make([]*fhks_bbs_plus.PartialThresholdSignature, test.Threshold)
+		commitments := make([]*fhks_bbs_plus.LivePreSignatureCommitment, test.Threshold)
+		for iT := 0; iT < test.Threshold; iT++ {
+			ownIndex := test.Indices[iK][iT]
+			preSignature := preComputation[ownIndex-1].PreSignatures[iK]
+			var err error
+			partialSignatures[iT], err = fhks_bbs_plus.NewPartialThresholdSignature().New(
+				messages[iK],
+				pk,
+				partySecretKey(preComputation, ownIndex),
+				fhks_bbs_plus.NewLivePreSignature().FromPreSignature(ownIndex, test.Indices[iK], preSignature),
+			)
+			assert.NoError(t, err)
+
+			commitment, err := preSignature.Commitment().Live(ownIndex, test.Indices[iK])
+			assert.NoError(t, err)
+			commitments[iT] = commitment
+		}
+
+		signature, err := fhks_bbs_plus.NewThresholdSignature().FromVerifiedPartialSignatures(
+			messages[iK], pk, test.Indices[iK], partialSignatures, commitments)
+		assert.NoError(t, err)
+		assert.True(t, signature.Verify(messages[iK], pk), "Signature verification failed")
+
+		// Tamper with the A share of the last signer.
+		g1 := bls12381.NewG1()
+		g1.Add(partialSignatures[test.Threshold-1].CapitalAShare, partialSignatures[test.Threshold-1].CapitalAShare, g1.One())
+		_, err = fhks_bbs_plus.NewThresholdSignature().FromVerifiedPartialSignatures(
+			messages[iK], pk, test.Indices[iK], partialSignatures, commitments)
+		var pse *fhks_bbs_plus.PartialSignatureError
+		assert.ErrorAs(t, err, &pse)
+		assert.Equal(t, []int{test.Indices[iK][test.Threshold-1]}, fhks_bbs_plus.FaultyParties(err))
+
+		// Tamper with the delta share of the first signer as well.
+		partialSignatures[0].DeltaShare.Add(partialSignatures[0].DeltaShare, bls12381.NewFr().One())
+		_, err = fhks_bbs_plus.NewThresholdSignature().FromVerifiedPartialSignatures(
+			messages[iK], pk, test.Indices[iK], partialSignatures, commitments)
+		assert.Equal(t, []int{test.Indices[iK][0], test.Indices[iK][test.Threshold-1]}, fhks_bbs_plus.FaultyParties(err))
+	}
+}
+
+func TestCrossCheckedSigningMockedPre(t *testing.T) {
+	messages := helper.GetRandomMessagesFromSeed(test.SeedMessages, test.K, test.MessageCount)
+
+	sk, preComputation := precomputation.GeneratePPPrecomputationMock(test.SeedPresignatures, test.Threshold, test.K, test.N)
+
+	pk := fhks_bbs_plus.GeneratePublicKey(test.SeedKeys, sk, test.MessageCount)
+
+	iK := 0
+	indices := test.Indices[iK]
+	partialSignatures := make([]*fhks_bbs_plus.PartialThresholdSignature, test.Threshold)
+	commitments := make([]*fhks_bbs_plus.PreSignatureCommitment, test.Threshold)
+	partyPublicKeys := make([]*fhks_bbs_plus.PartyPublicKey, test.Threshold)
+	for iT, ownIndex := range indices {
+		preSignature := preComputation[ownIndex-1].PreSignatures[iK]
+		keyShare := partySecretKey(preComputation, ownIndex)
+		var err error
+		partialSignatures[iT], err = fhks_bbs_plus.NewPartialThresholdSignature().New(
+			messages[iK],
+			pk,
+			keyShare,
+			fhks_bbs_plus.NewLivePreSignature().FromPreSignature(ownIndex, indices, preSignature),
+		)
+		assert.NoError(t, err)
+		commitments[iT] = preSignature.Commitment()
+		partyPublicKeys[iT] = keyShare.PartyPublicKey()
+	}
+
+	signature, err := fhks_bbs_plus.NewThresholdSignature().FromCrossCheckedPartialSignatures(
+		messages[iK], pk, indices, partialSignatures, commitments, partyPublicKeys)
+	assert.NoError(t, err)
+	assert.True(t, signature.Verify(messages[iK], pk), "Signature verification failed")
+
+	g2 := bls12381.NewG2()
+
+	// A cross term of the first signer that does not match the commitments of the second one.
+	aeTerm := commitments[0].AeTermsA[indices[1]-1]
+	g2.Add(aeTerm, aeTerm, g2.One())
+	_, err = fhks_bbs_plus.NewThresholdSignature().FromCrossCheckedPartialSignatures(
+		messages[iK], pk, indices, partialSignatures, commitments, partyPublicKeys)
+	var conflict *fhks_bbs_plus.CommitmentConflictError
+	assert.ErrorAs(t, err, &conflict)
+	assert.Equal(t, [2]int{indices[0], indices[1]}, conflict.Indices)
+	assert.Empty(t, fhks_bbs_plus.FaultyParties(err))
+	g2.Sub(aeTerm, aeTerm, g2.One())
+
+	// An own term of the last signer that does not match its own commitments.
+	askTerm := commitments[test.Threshold-1].AskTermOwn
+	g2.Add(askTerm, askTerm, g2.One())
+	_, err = fhks_bbs_plus.NewThresholdSignature().FromCrossCheckedPartialSignatures(
+		messages[iK], pk, indices, partialSignatures, commitments, partyPublicKeys)
+	assert.Equal(t, []int{indices[test.Threshold-1]}, fhks_bbs_plus.FaultyParties(err))
+}
+
+func TestFaultyPartiesWrapped(t *testing.T) {
+	err := fmt.Errorf("combine: %w", errors.Join(
+		fmt.Errorf("party 4: %w", &fhks_bbs_plus.PartialSignatureError{Index: 4, Reason: "e share does not match commitment"}),
+		errors.New("unrelated"),
+		fmt.Errorf("retry: %w", errors.Join(
+			&fhks_bbs_plus.PartialSignatureError{Index: 2, Reason: "A share does not match commitment"},
+			&fhks_bbs_plus.PartialSignatureError{Index: 4, Reason: "s share does not match commitment"},
+		)),
+	))
+	assert.Equal(t, []int{4, 2}, fhks_bbs_plus.FaultyParties(err))
+	assert.Empty(t, fhks_bbs_plus.FaultyParties(errors.New("unrelated")))
+	assert.Empty(t, fhks_bbs_plus.FaultyParties(nil))
+}
+
+func TestRobustSigningMockedPre(t *testing.T) {
+	messages := helper.GetRandomMessagesFromSeed(test.SeedMessages, test.K, test.MessageCount)[0]
+
+	sk, preComputation := precomputation.GeneratePPPrecomputationMock(test.SeedPresignatures, test.Threshold, test.K, test.N)
+
+	pk := fhks_bbs_plus.GeneratePublicKey(test.SeedKeys, sk, test.MessageCount)
+
+	partyPublicKeys := make([]*fhks_bbs_plus.PartyPublicKey, test.N)
+	for i := range partyPublicKeys {
+		partyPublicKeys[i] = partySecretKey(preComputation, i+1).PartyPublicKey()
+	}
+
+	responders := []int{1, 2, 3, 4, 5, 6}
+	committedFaulty := 2 // Shifts its e share and commits to the shifted share.
+	unreachable := 3
+	faulty := 4 // Shifts its e share only.
+
+	// sign computes the partial signature of party index from pre-signature k for the signer set.
+	sign := func(index, k int, signerSet []int) *fhks_bbs_plus.IndexedPartialSignature {
+		preSignature := preComputation[index-1].PreSignatures[k]
+		partialSignature, err := fhks_bbs_plus.NewPartialThresholdSignature().New(
+			messages,
+			pk,
+			partySecretKey(preComputation, index),
+			fhks_bbs_plus.NewLivePreSignature().FromPreSignature(index, signerSet, preSignature),
+		)
+		require.NoError(t, err)
+		commitment := preSignature.Commitment()
+
+		g2 := bls12381.NewG2()
+		switch index {
+		case committedFaulty:
+			partialSignature.EShare.Add(partialSignature.EShare, bls12381.NewFr().One())
+			g2.Add(commitment.E, commitment.E, g2.One())
+			g2.Add(commitment.AeTermOwn, commitment.AeTermOwn, commitment.A)
+		case faulty:
+			partialSignature.EShare.Add(partialSignature.EShare, bls12381.NewFr().One())
+		}
+		return &fhks_bbs_plus.IndexedPartialSignature{Index: index, PartialSignature: partialSignature, Commitment: commitment}
+	}
+
+	// Every requested signer set uses the next fresh pre-signature.
+	var requested [][]int
+	request := func(index int, signerSet []int) (*fhks_bbs_plus.IndexedPartialSignature, error) {
+		if len(requested) == 0 || !slices.Equal(requested[len(requested)-1], signerSet) {
+			requested = append(requested, signerSet)
+		}
+		if index == unreachable {
+			return nil, errors.New("timeout")
+		}
+		return sign(index, len(requested), signerSet), nil
+	}
+
+	partialSignatures := make([]*fhks_bbs_plus.IndexedPartialSignature, len(responders))
+	for i, index := range responders {
+		partialSignatures[i] = sign(index, 0, responders)
+	}
+
+	_, report, err := fhks_bbs_plus.NewThresholdSignature().FromPartialSignaturesRobust(
+		messages, pk, test.Threshold, partialSignatures, partyPublicKeys, nil)
+	assert.ErrorIs(t, err, fhks_bbs_plus.ErrNoValidSubset)
+	assert.Equal(t, []int{faulty}, report.FaultyParties)
+	assert.Equal(t, [][2]int{{1, 2}, {2, 3}, {2, 4}, {2, 5}, {2, 6}}, report.ConflictingParties)
+	assert.Equal(t, [][]int{responders}, report.FailedSubsets)
+
+	// The faulty parties are excluded by the checks, the unreachable one when asked for the first signer set.
+	signature, report, err := fhks_bbs_plus.NewThresholdSignature().FromPartialSignaturesRobust(
+		messages, pk, test.Threshold, partialSignatures, partyPublicKeys, request)
+	require.NoError(t, err)
+	assert.True(t, signature.Verify(messages, pk), "Signature verification failed")
+	assert.Equal(t, []int{1, 5, 6}, report.SignerSet)
+	assert.Equal(t, []int{faulty}, report.FaultyParties)
+	assert.Equal(t, []int{unreachable}, report.UnreachableParties)
+	assert.Equal(t, [][]int{responders, {1, 3, 5}}, report.FailedSubsets)
+	assert.Equal(t, [][]int{{1, 3, 5}, {1, 5, 6}}, requested)
+
+	// Without faulty parties, the given partial signatures are combined directly.
+	honest := []int{1, 3, 5, 6}
+	partialSignatures = make([]*fhks_bbs_plus.IndexedPartialSignature, len(honest))
+	for i, index := range honest {
+		partialSignatures[i] = sign(index, 0, honest)
+	}
+	signature, report, err = fhks_bbs_plus.NewThresholdSignature().FromPartialSignaturesRobust(
+		messages, pk, test.Threshold, partialSignatures, partyPublicKeys, nil)
+	require.NoError(t, err)
+	assert.True(t, signature.Verify(messages, pk), "Signature verification failed")
+	assert.Equal(t, honest, report.SignerSet)
+	assert.Empty(t, report.FaultyParties)
+	assert.Empty(t, report.ConflictingParties)
+	assert.Empty(t, report.FailedSubsets)
+}
+
 func TestSimpleSigningNOutOfN(t *testing.T) {
 
 	messages := helper.GetRandomMessagesFromSeed(test.SeedMessages, test.K, test.MessageCount)
@@ -104,11 +318,17 @@ func TestSimpleSigningNOutOfN(t *testing.T) {
 				DeltaShare: preComputation[iK][iT].DeltaShare,
 			}
 			preSig := emptyPreSig.FromPreSignatureShares(&ppPreSigSimple)
-			x := fhks_bbs_plus.NewPartialThresholdSignature().New(
+			keyShare := &fhks_bbs_plus.PartySecretKey{
+				SKeyShare: fhks_bbs_plus.SecretKey{Fr: preComputation[iK][iT].SkShare},
+				Index:     iT + 1,
+			}
+			x, err := fhks_bbs_plus.NewPartialThresholdSignature().New(
 				messages[iK],
 				pk,
+				keyShare,
 				preSig,
 			)
+			assert.NoError(t, err)
 			partialSignatures[iT] = x
 		}
 		signature := fhks_bbs_plus.NewThresholdSignature().FromPartialSignatures(partialSignatures)
@@ -118,3 +338,11 @@ func TestSimpleSigningNOutOfN(t *testing.T) {
 		}
 	}
 }
+
+// partySecretKey returns the secret key share of party index in the mocked precomputations, which belong to epoch 0.
+func partySecretKey(preComputation []*fhks_bbs_plus.PerPartyPrecomputations, index int) *fhks_bbs_plus.PartySecretKey {
+	return &fhks_bbs_plus.PartySecretKey{
+		SKeyShare: fhks_bbs_plus.SecretKey{Fr: preComputation[index-1].SkShare},
+		Index:     index,
+	}
+}
@@ -0,0 +1,24 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+// ErrInvalidEncoding is wrapped by the errors of all decoders for malformed, truncated or non-canonical input.
+var ErrInvalidEncoding = errors.New("invalid encoding")
+
+// FrFromCanonicalBytes reads a big-endian scalar of LenBytesFr bytes that is smaller than the group order. Unlike
+// bls12381.Fr.FromBytes, it does not accept several encodings of the same scalar.
+func FrFromCanonicalBytes(data []byte) (*bls12381.Fr, error) {
+	if len(data) != LenBytesFr {
+		return nil, fmt.Errorf("%w: expected %d bytes for scalar, got %d", ErrInvalidEncoding, LenBytesFr, len(data))
+	}
+	if new(big.Int).SetBytes(data).Cmp(bls12381.NewG1().Q()) >= 0 {
+		return nil, fmt.Errorf("%w: scalar is not reduced", ErrInvalidEncoding)
+	}
+	return bls12381.NewFr().FromBytes(data), nil
+}
@@ -0,0 +1,77 @@
+package helper
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// ExpandMessageXMD implements expand_message_xmd of RFC 9380 with SHA-256.
+func ExpandMessageXMD(msg, dst []byte, outLen int) ([]byte, error) {
+	const bInBytes = sha256.Size
+	const rInBytes = sha256.BlockSize
+
+	ell := (outLen + bInBytes - 1) / bInBytes
+	if ell > 255 || outLen > 65535 || outLen < 0 {
+		return nil, errors.New("requested output length is too large")
+	}
+	if len(dst) > 255 {
+		return nil, errors.New("domain separation tag is too long")
+	}
+
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+
+	h := sha256.New()
+	h.Write(make([]byte, rInBytes))
+	h.Write(msg)
+	h.Write([]byte{byte(outLen >> 8), byte(outLen)})
+	h.Write([]byte{0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	h.Reset()
+	h.Write(b0)
+	h.Write([]byte{1})
+	h.Write(dstPrime)
+	bi := h.Sum(nil)
+
+	out := make([]byte, 0, ell*bInBytes)
+	out = append(out, bi...)
+	for i := 2; i <= ell; i++ {
+		xored := make([]byte, bInBytes)
+		for j := range xored {
+			xored[j] = b0[j] ^ bi[j]
+		}
+		h.Reset()
+		h.Write(xored)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		bi = h.Sum(nil)
+		out = append(out, bi...)
+	}
+
+	return out[:outLen], nil
+}
+
+// ExpandMessageXOF implements expand_message_xof of RFC 9380 with SHAKE-256.
+func ExpandMessageXOF(msg, dst []byte, outLen int) ([]byte, error) {
+	if outLen > 65535 || outLen < 0 {
+		return nil, errors.New("requested output length is too large")
+	}
+	if len(dst) > 255 {
+		return nil, errors.New("domain separation tag is too long")
+	}
+
+	h := sha3.NewShake256()
+	h.Write(msg)
+	h.Write([]byte{byte(outLen >> 8), byte(outLen)})
+	h.Write(dst)
+	h.Write([]byte{byte(len(dst))})
+
+	out := make([]byte, outLen)
+	if _, err := h.Read(out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,57 @@
+package helper
+
+import (
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+// fixedBaseWindow is the window size in bits of G1FixedBaseTable.
+const fixedBaseWindow = 4
+
+// G1FixedBaseTable holds the multiples d * 2^(4*i) * P of a point P for all 4-bit digits d and windows i, so that
+// scalar multiplications with P need additions only.
+type G1FixedBaseTable struct {
+	windows [][]*bls12381.PointG1
+}
+
+// NewG1FixedBaseTable precomputes the fixed-base table of p.
+func NewG1FixedBaseTable(p *bls12381.PointG1) *G1FixedBaseTable {
+	g1 := bls12381.NewG1()
+	numWindows := LenBytesFr * 8 / fixedBaseWindow
+
+	windows := make([][]*bls12381.PointG1, numWindows)
+	base := g1.New().Set(p)
+	for i := range windows {
+		windows[i] = make([]*bls12381.PointG1, 1<<fixedBaseWindow)
+		windows[i][0] = g1.Zero()
+		for d := 1; d < len(windows[i]); d++ {
+			windows[i][d] = g1.New()
+			g1.Add(windows[i][d], windows[i][d-1], base)
+		}
+		// The base of the next window is 2^4 * base.
+		g1.Add(base, windows[i][len(windows[i])-1], base)
+	}
+	// Affine points make the additions in Mul cheaper.
+	for _, window := range windows {
+		for _, point := range window {
+			g1.Affine(point)
+		}
+	}
+
+	return &G1FixedBaseTable{windows: windows}
+}
+
+// Mul returns scalar * P for the point P of the table.
+func (t *G1FixedBaseTable) Mul(scalar *bls12381.Fr) *bls12381.PointG1 {
+	g1 := bls12381.NewG1()
+	result := g1.Zero()
+
+	scalarBytes := scalar.ToBytes() // big-endian
+	for i, window := range t.windows {
+		b := scalarBytes[len(scalarBytes)-1-i/2]
+		digit := (b >> (fixedBaseWindow * uint(i%2))) & (1<<fixedBaseWindow - 1)
+		if digit != 0 {
+			g1.Add(result, result, window[digit])
+		}
+	}
+	return result
+}
@@ -0,0 +1,35 @@
+package helper
+
+import (
+	"math/big"
+
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+// fieldModulus is the characteristic p of the base field of BLS12-381.
+var fieldModulus, _ = new(big.Int).SetString("1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab", 16)
+
+// HashToCurveG1XOF implements hash_to_curve of RFC 9380 for the suite BLS12381G1_XOF:SHAKE-256_SSWU_RO_.
+func HashToCurveG1XOF(msg, dst []byte) (*bls12381.PointG1, error) {
+	// hash_to_field with count 2 and L = 64.
+	uniformBytes, err := ExpandMessageXOF(msg, dst, 2*64)
+	if err != nil {
+		return nil, err
+	}
+
+	g1 := bls12381.NewG1()
+	point := g1.Zero()
+	for i := 0; i < 2; i++ {
+		u := new(big.Int).SetBytes(uniformBytes[i*64 : (i+1)*64])
+		u.Mod(u, fieldModulus)
+
+		// MapToCurve applies the isogeny and clears the cofactor of the single mapped point. Both are
+		// homomorphisms, so summing the mapped points matches the suite's map, add, then clear order.
+		q, err := g1.MapToCurve(u.FillBytes(make([]byte, 48)))
+		if err != nil {
+			return nil, err
+		}
+		g1.Add(point, point, q)
+	}
+	return g1.Affine(point), nil
+}
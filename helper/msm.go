@@ -0,0 +1,169 @@
+package helper
+
+import (
+	"crypto/subtle"
+	"math/big"
+	"math/bits"
+
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+// ctWindow is the window size in bits of MultiScalarMulG1.
+const ctWindow = 4
+
+// ctOffsetScalar is sum_w 2^(4*w) over all windows w of a scalar, reduced modulo the group order. MultiScalarMulG1
+// adds (digit + 1) * 2^(4*w) * P per window w to never add the identity and subtracts ctOffsetScalar * P at the end.
+var ctOffsetScalar = func() *bls12381.Fr {
+	offset := new(big.Int).Lsh(big.NewInt(1), LenBytesFr*8)
+	offset.Sub(offset, big.NewInt(1))
+	offset.Div(offset, big.NewInt(1<<ctWindow-1))
+	offset.Mod(offset, bls12381.NewG1().Q())
+	return bls12381.NewFr().FromBytes(offset.Bytes())
+}()
+
+// pippengerMinBases is the number of bases from which on MultiScalarMulVarTimeG1 is faster with buckets than with
+// the GLV scalar multiplication of the curve library.
+const pippengerMinBases = 8
+
+// MultiScalarMulG1 computes sum_i scalars[i] * bases[i] for secret scalars. The sequence of group operations and
+// memory accesses does not depend on the scalars: all windows of all scalars are processed and table entries are
+// selected in constant time. The bases are treated as public.
+func MultiScalarMulG1(bases []*bls12381.PointG1, scalars []*bls12381.Fr) *bls12381.PointG1 {
+	g1 := bls12381.NewG1()
+
+	// tables[i][d] = (d + 1) * bases[i], in affine form for cheaper additions.
+	tables := make([][]*bls12381.PointG1, len(bases))
+	entries := make([]*bls12381.PointG1, 0, len(bases)<<ctWindow)
+	baseSum := g1.Zero()
+	for i, base := range bases {
+		tables[i] = make([]*bls12381.PointG1, 1<<ctWindow)
+		tables[i][0] = g1.New().Set(base)
+		for d := 1; d < len(tables[i]); d++ {
+			tables[i][d] = g1.New()
+			g1.Add(tables[i][d], tables[i][d-1], base)
+		}
+		entries = append(entries, tables[i]...)
+		g1.Add(baseSum, baseSum, base)
+	}
+	g1.AffineBatch(entries)
+
+	scalarBytes := make([][]byte, len(scalars))
+	for i, scalar := range scalars {
+		scalarBytes[i] = scalar.ToBytes()
+	}
+
+	result := g1.Zero()
+	entry := g1.New()
+	for w := LenBytesFr*8/ctWindow - 1; w >= 0; w-- {
+		for k := 0; k < ctWindow; k++ {
+			g1.Double(result, result)
+		}
+		for i := range tables {
+			constantTimeLookupG1(entry, tables[i], scalarDigit(scalarBytes[i], w*ctWindow, ctWindow))
+			g1.Add(result, result, entry)
+		}
+	}
+
+	offset := g1.New()
+	g1.MulScalar(offset, baseSum, ctOffsetScalar)
+	return g1.Sub(result, result, offset)
+}
+
+// constantTimeLookupG1 sets r to table[index] reading every entry of table.
+func constantTimeLookupG1(r *bls12381.PointG1, table []*bls12381.PointG1, index uint) {
+	for c := range r {
+		for k := range r[c] {
+			r[c][k] = 0
+		}
+	}
+	for j, p := range table {
+		mask := -uint64(subtle.ConstantTimeEq(int32(j), int32(index)))
+		for c := range r {
+			for k := range r[c] {
+				r[c][k] |= p[c][k] & mask
+			}
+		}
+	}
+}
+
+// MultiScalarMulVarTimeG1 computes sum_i scalars[i] * bases[i] with Pippenger's bucket method. Its running time
+// depends on the scalars, so it must only be used with public data.
+func MultiScalarMulVarTimeG1(bases []*bls12381.PointG1, scalars []*bls12381.Fr) *bls12381.PointG1 {
+	g1 := bls12381.NewG1()
+	result := g1.Zero()
+	if len(bases) < pippengerMinBases {
+		tmp := g1.New()
+		for i, base := range bases {
+			g1.MulScalar(tmp, base, scalars[i])
+			g1.Add(result, result, tmp)
+		}
+		return result
+	}
+
+	// A window of about log2(n) bits balances bucket accumulation and bucket aggregation.
+	window := bits.Len(uint(len(bases))) - 1
+	if window < 2 {
+		window = 2
+	}
+
+	// Affine bases make the additions to the buckets cheaper.
+	affineBases := make([]*bls12381.PointG1, len(bases))
+	for i, base := range bases {
+		affineBases[i] = g1.New().Set(base)
+	}
+	g1.AffineBatch(affineBases)
+
+	scalarBytes := make([][]byte, len(scalars))
+	for i, scalar := range scalars {
+		scalarBytes[i] = scalar.ToBytes()
+	}
+
+	buckets := make([]*bls12381.PointG1, 1<<window-1)
+	numWindows := (LenBytesFr*8 + window - 1) / window
+	for w := numWindows - 1; w >= 0; w-- {
+		for k := 0; k < window; k++ {
+			g1.Double(result, result)
+		}
+
+		// buckets[d-1] accumulates all bases with digit d in this window.
+		for d := range buckets {
+			buckets[d] = nil
+		}
+		for i, base := range affineBases {
+			d := scalarDigit(scalarBytes[i], w*window, window)
+			if d == 0 {
+				continue
+			}
+			if buckets[d-1] == nil {
+				buckets[d-1] = g1.New().Set(base)
+			} else {
+				g1.Add(buckets[d-1], buckets[d-1], base)
+			}
+		}
+
+		// sum_d d * buckets[d-1] as a sum of running sums.
+		running, windowSum := g1.Zero(), g1.Zero()
+		for d := len(buckets) - 1; d >= 0; d-- {
+			if buckets[d] != nil {
+				g1.Add(running, running, buckets[d])
+			}
+			g1.Add(windowSum, windowSum, running)
+		}
+		g1.Add(result, result, windowSum)
+	}
+	return result
+}
+
+// scalarDigit returns the width bits of the big-endian scalar starting at bit start, counted from the least
+// significant bit.
+func scalarDigit(scalar []byte, start, width int) uint {
+	var digit uint
+	for b := start + width - 1; b >= start; b-- {
+		digit <<= 1
+		byteIndex := len(scalar) - 1 - b/8
+		if byteIndex >= 0 {
+			digit |= uint(scalar[byteIndex]>>(b%8)) & 1
+		}
+	}
+	return digit
+}
@@ -0,0 +1,78 @@
+package helper
+
+import (
+	"crypto/rand"
+	"testing"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/stretchr/testify/assert"
+)
+
+func naiveMultiScalarMulG1(bases []*bls12381.PointG1, scalars []*bls12381.Fr) *bls12381.PointG1 {
+	g1 := bls12381.NewG1()
+	result := g1.Zero()
+	for i := range bases {
+		tmp := g1.New()
+		g1.MulScalar(tmp, bases[i], scalars[i])
+		g1.Add(result, result, tmp)
+	}
+	return result
+}
+
+func randomMultiScalarMulInput(n int) ([]*bls12381.PointG1, []*bls12381.Fr) {
+	g1 := bls12381.NewG1()
+	bases := make([]*bls12381.PointG1, n)
+	scalars := make([]*bls12381.Fr, n)
+	for i := range bases {
+		exp, _ := bls12381.NewFr().Rand(rand.Reader)
+		bases[i] = g1.New()
+		g1.MulScalar(bases[i], g1.One(), exp)
+		scalars[i], _ = bls12381.NewFr().Rand(rand.Reader)
+	}
+	return bases, scalars
+}
+
+func TestMultiScalarMulG1(t *testing.T) {
+	g1 := bls12381.NewG1()
+	maxScalar := bls12381.NewFr().Zero()
+	maxScalar.Sub(maxScalar, bls12381.NewFr().One()) // r - 1
+
+	for _, n := range []int{0, 1, 2, 3, 10, 33, 100} {
+		bases, scalars := randomMultiScalarMulInput(n)
+		if n >= 3 {
+			scalars[0] = bls12381.NewFr().Zero()
+			scalars[1] = bls12381.NewFr().One()
+			scalars[2] = maxScalar
+		}
+		expected := naiveMultiScalarMulG1(bases, scalars)
+		assert.True(t, g1.Equal(expected, MultiScalarMulVarTimeG1(bases, scalars)), "variable-time MSM mismatch for n = %d", n)
+		assert.True(t, g1.Equal(expected, MultiScalarMulG1(bases, scalars)), "constant-time MSM mismatch for n = %d", n)
+	}
+}
+
+func benchmarkMultiScalarMulG1(b *testing.B, n int, msm func([]*bls12381.PointG1, []*bls12381.Fr) *bls12381.PointG1) {
+	bases, scalars := randomMultiScalarMulInput(n)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		msm(bases, scalars)
+	}
+}
+
+func BenchmarkMultiScalarMulG1Naive10(b *testing.B) {
+	benchmarkMultiScalarMulG1(b, 10, naiveMultiScalarMulG1)
+}
+func BenchmarkMultiScalarMulG1Naive100(b *testing.B) {
+	benchmarkMultiScalarMulG1(b, 100, naiveMultiScalarMulG1)
+}
+func BenchmarkMultiScalarMulG1VarTime10(b *testing.B) {
+	benchmarkMultiScalarMulG1(b, 10, MultiScalarMulVarTimeG1)
+}
+func BenchmarkMultiScalarMulG1VarTime100(b *testing.B) {
+	benchmarkMultiScalarMulG1(b, 100, MultiScalarMulVarTimeG1)
+}
+func BenchmarkMultiScalarMulG1ConstantTime10(b *testing.B) {
+	benchmarkMultiScalarMulG1(b, 10, MultiScalarMulG1)
+}
+func BenchmarkMultiScalarMulG1ConstantTime100(b *testing.B) {
+	benchmarkMultiScalarMulG1(b, 100, MultiScalarMulG1)
+}
@@ -56,8 +56,20 @@ func Get0LagrangeCoefficientSetFr(indices []int) []*bls12381.Fr {
 	return coefficients
 }
 
+// ShamirPositionFr returns the position in Fr at which the share of the party with the 1-based index is evaluated
+func ShamirPositionFr(index int) *bls12381.Fr {
+	return uint64ToFr(uint64(index))
+}
+
 // GetShamirSharedRandomElement generates a t-out-of-n shamir secret sharing of a random element
 func GetShamirSharedRandomElement(rng io.Reader, t, n int) (*bls12381.Fr, []*bls12381.Fr) {
+	secretKeyElement, shares, _ := GetShamirSharedRandomElementWithCommitments(rng, t, n)
+	return secretKeyElement, shares
+}
+
+// GetShamirSharedRandomElementWithCommitments generates a t-out-of-n shamir secret sharing of a random element along
+// with the Feldman commitments to the sharing polynomial
+func GetShamirSharedRandomElementWithCommitments(rng io.Reader, t, n int) (*bls12381.Fr, []*bls12381.Fr, []*bls12381.PointG2) {
 	// Generate the secret key element
 	secretKeyElement := bls12381.NewFr()
 	_, err := secretKeyElement.Rand(rng)
@@ -65,6 +77,26 @@ func GetShamirSharedRandomElement(rng io.Reader, t, n int) (*bls12381.Fr, []*bls
 		panic(err)
 	}
 
+	shares, commitments := shamirShareWithCommitments(rng, secretKeyElement, t, n)
+	return secretKeyElement, shares, commitments
+}
+
+// ShamirSharedSecretKey generates a t-out-of-n shamir secret sharing of the provided secret key
+func ShamirSharedSecretKey(skey *bls12381.Fr, t, n int) []*bls12381.Fr {
+	shares, _ := ShamirSharedSecretKeyWithCommitments(skey, t, n)
+	return shares
+}
+
+// ShamirSharedSecretKeyWithCommitments generates a t-out-of-n shamir secret sharing of the provided secret key along
+// with the Feldman commitments to the sharing polynomial
+func ShamirSharedSecretKeyWithCommitments(skey *bls12381.Fr, t, n int) ([]*bls12381.Fr, []*bls12381.PointG2) {
+	return shamirShareWithCommitments(rand.Reader, skey, t, n)
+}
+
+// shamirShareWithCommitments shares secret with a random polynomial f of degree t-1 with f(0) = secret. Party i gets
+// the share f(ShamirPositionFr(i+1)) and the commitments are g_2^(f_k) for the coefficients f_k of f, starting with
+// g_2^secret.
+func shamirShareWithCommitments(rng io.Reader, secret *bls12381.Fr, t, n int) ([]*bls12381.Fr, []*bls12381.PointG2) {
 	// Shamir Coefficients
 	coefficients := make([]*bls12381.Fr, t-1)
 	for i := 0; i < t-1; i++ {
@@ -79,12 +111,12 @@ func GetShamirSharedRandomElement(rng io.Reader, t, n int) (*bls12381.Fr, []*bls
 	shares := make([]*bls12381.Fr, n)
 	for i := 0; i < n; i++ {
 		share := bls12381.NewFr()
-		share.Set(secretKeyElement) // Share initialized with secret key element
+		share.Set(secret) // Share initialized with secret key element
 
 		incrExponentiation := bls12381.NewFr().One()
 
 		for j := 0; j < t-1; j++ {
-			incrExponentiation.Mul(incrExponentiation, uint64ToFr(uint64(i+1)))
+			incrExponentiation.Mul(incrExponentiation, ShamirPositionFr(i+1))
 			tmp := bls12381.NewFr().Set(coefficients[j])
 			tmp.Mul(tmp, incrExponentiation)
 			share.Add(share, tmp)
@@ -92,38 +124,28 @@ func GetShamirSharedRandomElement(rng io.Reader, t, n int) (*bls12381.Fr, []*bls
 
 		shares[i] = share
 	}
-	return secretKeyElement, shares
-}
-
-// ShamirSharedSecretKey generates a t-out-of-n shamir secret sharing of the provided secret key
-func ShamirSharedSecretKey(skey *bls12381.Fr, t, n int) []*bls12381.Fr {
-
-	coefficients := make([]*bls12381.Fr, t-1)
-	for i := 0; i < t-1; i++ {
-		coefficients[i] = bls12381.NewFr()
-		_, err := coefficients[i].Rand(rand.Reader)
 
-		if err != nil {
-			panic(err)
-		}
+	// Feldman commitments
+	g2 := bls12381.NewG2()
+	commitments := make([]*bls12381.PointG2, t)
+	commitments[0] = g2.New()
+	g2.MulScalar(commitments[0], g2.One(), secret)
+	for j, coefficient := range coefficients {
+		commitments[j+1] = g2.New()
+		g2.MulScalar(commitments[j+1], g2.One(), coefficient)
 	}
+	return shares, commitments
+}
 
-	// Shares
-	shares := make([]*bls12381.Fr, n)
-	for i := 0; i < n; i++ {
-		share := bls12381.NewFr()
-		share.Set(skey)
-
-		incrExponentiation := bls12381.NewFr().One()
-
-		for j := 0; j < t-1; j++ {
-			incrExponentiation.Mul(incrExponentiation, uint64ToFr(uint64(i+1)))
-			tmp := bls12381.NewFr().Set(coefficients[j])
-			tmp.Mul(tmp, incrExponentiation)
-			share.Add(share, tmp)
-		}
-
-		shares[i] = share
+// EvaluateFeldmanCommitments computes g_2^f(ShamirPositionFr(index)) from the Feldman commitments g_2^(f_k) to the
+// coefficients of f, which is the public counterpart of the share of the party with the 1-based index
+func EvaluateFeldmanCommitments(commitments []*bls12381.PointG2, index int) *bls12381.PointG2 {
+	g2 := bls12381.NewG2()
+	position := ShamirPositionFr(index)
+	result := g2.Zero()
+	for k := len(commitments) - 1; k >= 0; k-- {
+		g2.MulScalar(result, result, position)
+		g2.Add(result, result, commitments[k])
 	}
-	return shares
+	return result
 }
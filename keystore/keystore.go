@@ -0,0 +1,321 @@
+// Package keystore encrypts party key shares, PCG seeds and pre-signature batches at rest.
+//
+// Every entry is encrypted with a random data key under XChaCha20-Poly1305. The data key is wrapped with a key
+// derived from a password with argon2id, so the password can be rotated without re-encrypting the payload, see
+// Entry.Rewrap. The metadata of an entry is stored in plaintext and authenticated by both encryptions.
+package keystore
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
+)
+
+// ErrAuthentication is returned if an entry cannot be decrypted, i.e., if the password is wrong or the entry was
+// modified.
+var ErrAuthentication = errors.New("wrong password or corrupted keystore entry")
+
+// ErrKindMismatch is returned if an entry is opened as the wrong kind of material.
+var ErrKindMismatch = errors.New("keystore entry holds another kind of material")
+
+// Kind identifies the material in an entry.
+type Kind uint8
+
+const (
+	KindPartySecretKey Kind = iota + 1
+	KindPCGSeed
+	KindPreSignatures
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindPartySecretKey:
+		return "party secret key"
+	case KindPCGSeed:
+		return "PCG seed"
+	case KindPreSignatures:
+		return "pre-signatures"
+	default:
+		return fmt.Sprintf("unknown kind %d", uint8(k))
+	}
+}
+
+// Metadata describes the material in an entry. It is readable without the password, but cannot be changed without
+// invalidating the entry.
+type Metadata struct {
+	Kind       Kind
+	KeyID      string // Identifies the threshold key the material belongs to, at most 255 bytes.
+	PartyIndex int
+	Epoch      uint64 // Key epoch of the material.
+}
+
+// KDFParams are the argon2id parameters of the password-derived wrapping key.
+type KDFParams struct {
+	Time    uint32
+	Memory  uint32 // In KiB.
+	Threads uint8
+}
+
+// DefaultKDFParams are the argon2id parameters recommended by RFC 9106 for memory-constrained environments.
+var DefaultKDFParams = KDFParams{Time: 3, Memory: 64 * 1024, Threads: 4}
+
+// Bounds on the KDF parameters of decoded entries, so that hostile entries cannot exhaust memory or time. The
+// memory bound of 256 MiB is four times the default and fits the devices a wallet share is kept on.
+const (
+	maxKDFTime   = 16
+	maxKDFMemory = 256 * 1024
+)
+
+func (p KDFParams) validate() error {
+	if p.Time < 1 || p.Time > maxKDFTime {
+		return fmt.Errorf("invalid argon2id time parameter %d", p.Time)
+	}
+	if p.Threads < 1 {
+		return errors.New("invalid argon2id parallelism 0")
+	}
+	if p.Memory < 8*uint32(p.Threads) || p.Memory > maxKDFMemory {
+		return fmt.Errorf("invalid argon2id memory parameter %d KiB", p.Memory)
+	}
+	return nil
+}
+
+const (
+	saltLength       = 16
+	wrappedKeyLength = chacha20poly1305.NonceSizeX + chacha20poly1305.KeySize + chacha20poly1305.Overhead
+	entryVersion     = 1
+	maxKeyIDLength   = 255
+)
+
+var entryMagic = []byte("TBKS")
+
+// Entry is an encrypted piece of material.
+type Entry struct {
+	Metadata   Metadata
+	kdf        KDFParams
+	salt       []byte
+	wrappedKey []byte // nonce | wrapped data key.
+	ciphertext []byte // nonce | encrypted payload.
+}
+
+// Seal encrypts plaintext under password. The caller should Zeroize the plaintext afterwards.
+func Seal(password []byte, metadata Metadata, plaintext []byte, params KDFParams) (*Entry, error) {
+	if len(metadata.KeyID) > maxKeyIDLength {
+		return nil, errors.New("key ID is too long")
+	}
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+
+	dataKey := make([]byte, chacha20poly1305.KeySize)
+	defer Zeroize(dataKey)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("sample data key: %w", err)
+	}
+
+	e := &Entry{Metadata: metadata}
+	ciphertext, err := encrypt(dataKey, plaintext, e.associatedData())
+	if err != nil {
+		return nil, err
+	}
+	e.ciphertext = ciphertext
+	if err := e.wrap(password, dataKey, params); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Open decrypts the entry with password. The caller must Zeroize the returned plaintext after use.
+func (e *Entry) Open(password []byte) ([]byte, error) {
+	dataKey, err := e.unwrap(password)
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(dataKey)
+
+	return decrypt(dataKey, e.ciphertext, e.associatedData())
+}
+
+// Rewrap rotates the wrapping key of the entry: it unwraps the data key with oldPassword and wraps it with a key
+// derived from newPassword with a fresh salt. The payload is not re-encrypted. On error, the entry is unchanged.
+func (e *Entry) Rewrap(oldPassword, newPassword []byte, params KDFParams) error {
+	if err := params.validate(); err != nil {
+		return err
+	}
+	dataKey, err := e.unwrap(oldPassword)
+	if err != nil {
+		return err
+	}
+	defer Zeroize(dataKey)
+
+	return e.wrap(newPassword, dataKey, params)
+}
+
+// KDFParams returns the argon2id parameters the wrapping key of the entry is derived with.
+func (e *Entry) KDFParams() KDFParams {
+	return e.kdf
+}
+
+func (e *Entry) wrap(password, dataKey []byte, params KDFParams) error {
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("sample salt: %w", err)
+	}
+	wrappingKey := deriveKey(password, salt, params)
+	defer Zeroize(wrappingKey)
+
+	wrappedKey, err := encrypt(wrappingKey, dataKey, e.associatedData())
+	if err != nil {
+		return err
+	}
+	e.kdf, e.salt, e.wrappedKey = params, salt, wrappedKey
+	return nil
+}
+
+func (e *Entry) unwrap(password []byte) ([]byte, error) {
+	wrappingKey := deriveKey(password, e.salt, e.kdf)
+	defer Zeroize(wrappingKey)
+
+	return decrypt(wrappingKey, e.wrappedKey, e.associatedData())
+}
+
+func deriveKey(password, salt []byte, params KDFParams) []byte {
+	return argon2.IDKey(password, salt, params.Time, params.Memory, params.Threads, chacha20poly1305.KeySize)
+}
+
+// associatedData is the encoding of the metadata the encryptions are bound to.
+func (e *Entry) associatedData() []byte {
+	ad := append([]byte{}, entryMagic...)
+	ad = append(ad, entryVersion, byte(e.Metadata.Kind), byte(len(e.Metadata.KeyID)))
+	ad = append(ad, e.Metadata.KeyID...)
+	ad = binary.LittleEndian.AppendUint32(ad, uint32(e.Metadata.PartyIndex))
+	return binary.LittleEndian.AppendUint64(ad, e.Metadata.Epoch)
+}
+
+func encrypt(key, plaintext, associatedData []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("sample nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, associatedData), nil
+}
+
+func decrypt(key, ciphertext, associatedData []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrAuthentication
+	}
+	plaintext, err := aead.Open(nil, ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():], associatedData)
+	if err != nil {
+		return nil, ErrAuthentication
+	}
+	return plaintext, nil
+}
+
+// Marshal serializes the entry as
+// metadata | KDF time (uint32) | KDF memory (uint32) | KDF threads (uint8) | salt | wrapped key |
+// ciphertext length (uint32) | ciphertext,
+// where the metadata is encoded as magic | version (uint8) | kind (uint8) | key ID length (uint8) | key ID |
+// party index (uint32) | epoch (uint64).
+func (e *Entry) Marshal() ([]byte, error) {
+	ser := e.associatedData()
+	ser = binary.LittleEndian.AppendUint32(ser, e.kdf.Time)
+	ser = binary.LittleEndian.AppendUint32(ser, e.kdf.Memory)
+	ser = append(ser, e.kdf.Threads)
+	ser = append(ser, e.salt...)
+	ser = append(ser, e.wrappedKey...)
+	ser = binary.LittleEndian.AppendUint32(ser, uint32(len(e.ciphertext)))
+	return append(ser, e.ciphertext...), nil
+}
+
+// UnmarshalEntry deserializes an entry serialized with Entry.Marshal. Errors wrap helper.ErrInvalidEncoding.
+func UnmarshalEntry(data []byte) (*Entry, error) {
+	r := &reader{data: data}
+	if string(r.next(len(entryMagic))) != string(entryMagic) {
+		return nil, fmt.Errorf("%w: missing keystore magic number", helper.ErrInvalidEncoding)
+	}
+	if version := r.uint8(); r.err == nil && version != entryVersion {
+		return nil, fmt.Errorf("%w: unsupported keystore entry version %d", helper.ErrInvalidEncoding, version)
+	}
+
+	e := &Entry{}
+	e.Metadata.Kind = Kind(r.uint8())
+	e.Metadata.KeyID = string(r.next(int(r.uint8())))
+	e.Metadata.PartyIndex = int(r.uint32())
+	e.Metadata.Epoch = r.uint64()
+	e.kdf.Time = r.uint32()
+	e.kdf.Memory = r.uint32()
+	e.kdf.Threads = r.uint8()
+	e.salt = append([]byte{}, r.next(saltLength)...)
+	e.wrappedKey = append([]byte{}, r.next(wrappedKeyLength)...)
+	e.ciphertext = append([]byte{}, r.next(int(r.uint32()))...)
+	if r.err != nil {
+		return nil, r.err
+	}
+	if len(r.data) != 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes", helper.ErrInvalidEncoding, len(r.data))
+	}
+	if err := e.kdf.validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", helper.ErrInvalidEncoding, err)
+	}
+	return e, nil
+}
+
+// reader reads the fields of an entry. After the first error all reads return zero values.
+type reader struct {
+	data []byte
+	err  error
+}
+
+func (r *reader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.data) {
+		r.err = fmt.Errorf("%w: unexpected end of keystore entry", helper.ErrInvalidEncoding)
+		return nil
+	}
+	field := r.data[:n]
+	r.data = r.data[n:]
+	return field
+}
+
+func (r *reader) uint8() uint8 {
+	if field := r.next(1); field != nil {
+		return field[0]
+	}
+	return 0
+}
+
+func (r *reader) uint32() uint32 {
+	if field := r.next(4); field != nil {
+		return binary.LittleEndian.Uint32(field)
+	}
+	return 0
+}
+
+func (r *reader) uint64() uint64 {
+	if field := r.next(8); field != nil {
+		return binary.LittleEndian.Uint64(field)
+	}
+	return 0
+}
+
+// Zeroize overwrites b with zeros.
+func Zeroize(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,191 @@
+package keystore_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
+	"github.com/perun-network/bbs-plus-threshold-wallet/keystore"
+	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation/pcg"
+)
+
+// testParams are cheap argon2id parameters for testing only.
+var testParams = keystore.KDFParams{Time: 1, Memory: 64, Threads: 1}
+
+func testKey() *fhks_bbs_plus.PartySecretKey {
+	return &fhks_bbs_plus.PartySecretKey{
+		SKeyShare: fhks_bbs_plus.SecretKey{Fr: fhks_bbs_plus.GenerateRandomFr()},
+		PublicKey: bls12381.NewG2().One(),
+		Index:     3,
+		Epoch:     2,
+	}
+}
+
+func TestPartySecretKey(t *testing.T) {
+	key := testKey()
+	entry, err := keystore.SealPartySecretKey([]byte("password"), "wallet", key, testParams)
+	require.NoError(t, err)
+	assert.Equal(t, keystore.Metadata{Kind: keystore.KindPartySecretKey, KeyID: "wallet", PartyIndex: 3, Epoch: 2}, entry.Metadata)
+
+	data, err := entry.Marshal()
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), string(key.SKeyShare.Fr.ToBytes()))
+	entry, err = keystore.UnmarshalEntry(data)
+	require.NoError(t, err)
+
+	opened, err := entry.OpenPartySecretKey([]byte("password"))
+	require.NoError(t, err)
+	assert.True(t, key.SKeyShare.Fr.Equal(opened.SKeyShare.Fr))
+	assert.Equal(t, key.Index, opened.Index)
+	assert.Equal(t, key.Epoch, opened.Epoch)
+
+	opened.Zeroize()
+	assert.True(t, opened.SKeyShare.Fr.IsZero())
+
+	_, err = entry.OpenPartySecretKey([]byte("wrong"))
+	assert.ErrorIs(t, err, keystore.ErrAuthentication)
+	_, err = entry.OpenSeed([]byte("password"))
+	assert.ErrorIs(t, err, keystore.ErrKindMismatch)
+}
+
+func TestMetadataIsAuthenticated(t *testing.T) {
+	entry, err := keystore.SealPartySecretKey([]byte("password"), "wallet", testKey(), testParams)
+	require.NoError(t, err)
+
+	entry.Metadata.Epoch++
+	_, err = entry.OpenPartySecretKey([]byte("password"))
+	assert.ErrorIs(t, err, keystore.ErrAuthentication)
+
+	entry.Metadata.Epoch--
+	entry.Metadata.KeyID = "other"
+	_, err = entry.OpenPartySecretKey([]byte("password"))
+	assert.ErrorIs(t, err, keystore.ErrAuthentication)
+}
+
+func TestRewrap(t *testing.T) {
+	key := testKey()
+	entry, err := keystore.SealPartySecretKey([]byte("old"), "wallet", key, testParams)
+	require.NoError(t, err)
+
+	newParams := keystore.KDFParams{Time: 2, Memory: 128, Threads: 2}
+	assert.ErrorIs(t, entry.Rewrap([]byte("wrong"), []byte("new"), newParams), keystore.ErrAuthentication)
+	assert.Equal(t, testParams, entry.KDFParams())
+
+	require.NoError(t, entry.Rewrap([]byte("old"), []byte("new"), newParams))
+	assert.Equal(t, newParams, entry.KDFParams())
+
+	_, err = entry.OpenPartySecretKey([]byte("old"))
+	assert.ErrorIs(t, err, keystore.ErrAuthentication)
+	opened, err := entry.OpenPartySecretKey([]byte("new"))
+	require.NoError(t, err)
+	assert.True(t, key.SKeyShare.Fr.Equal(opened.SKeyShare.Fr))
+}
+
+func TestPreSignatures(t *testing.T) {
+	n := 3
+	randomTerms := func() []*bls12381.Fr {
+		return helper.GetRandomElements(1, n)[0]
+	}
+	ppp := &fhks_bbs_plus.PerPartyPrecomputationsWithPubKey{
+		Index:     1,
+		SkShare:   fhks_bbs_plus.GenerateRandomFr(),
+		PublicKey: bls12381.NewG2().One(),
+	}
+	for k := 0; k < 2; k++ {
+		ppp.PreSignatures = append(ppp.PreSignatures, &fhks_bbs_plus.PerPartyPreSignature{
+			AShare:     fhks_bbs_plus.GenerateRandomFr(),
+			EShare:     fhks_bbs_plus.GenerateRandomFr(),
+			SShare:     fhks_bbs_plus.GenerateRandomFr(),
+			AeTermOwn:  fhks_bbs_plus.GenerateRandomFr(),
+			AsTermOwn:  fhks_bbs_plus.GenerateRandomFr(),
+			AskTermOwn: fhks_bbs_plus.GenerateRandomFr(),
+			AeTermsA:   randomTerms(),
+			AeTermsE:   randomTerms(),
+			AsTermsA:   randomTerms(),
+			AsTermsS:   randomTerms(),
+			AskTermsA:  randomTerms(),
+			AskTermsSK: randomTerms(),
+			Epoch:      4,
+		})
+	}
+
+	entry, err := keystore.SealPreSignatures([]byte("password"), "wallet", ppp, testParams)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), entry.Metadata.Epoch)
+
+	opened, err := entry.OpenPreSignatures([]byte("password"))
+	require.NoError(t, err)
+	assert.Equal(t, ppp.Index, opened.Index)
+	assert.True(t, ppp.SkShare.Equal(opened.SkShare))
+	require.Len(t, opened.PreSignatures, 2)
+	for k := range ppp.PreSignatures {
+		assert.True(t, ppp.PreSignatures[k].AShare.Equal(opened.PreSignatures[k].AShare))
+		assert.Equal(t, ppp.PreSignatures[k].Epoch, opened.PreSignatures[k].Epoch)
+		for j := 0; j < n; j++ {
+			assert.True(t, ppp.PreSignatures[k].AskTermsSK[j].Equal(opened.PreSignatures[k].AskTermsSK[j]))
+		}
+	}
+
+	opened.Zeroize()
+	assert.True(t, opened.PreSignatures[1].AeTermsE[2].IsZero())
+}
+
+func TestSeed(t *testing.T) {
+	generator, err := pcg.NewPCG(128, 10, 2, 2, 2, 4) // Small lpn parameters for testing.
+	require.NoError(t, err)
+	generator.SetEpoch(1)
+	seeds, err := generator.TrustedSeedGen()
+	require.NoError(t, err)
+
+	entry, err := keystore.SealSeed([]byte("password"), "wallet", seeds[1], testParams)
+	require.NoError(t, err)
+	assert.Equal(t, keystore.Metadata{Kind: keystore.KindPCGSeed, KeyID: "wallet", PartyIndex: 1, Epoch: 1}, entry.Metadata)
+
+	opened, err := entry.OpenSeed([]byte("password"))
+	require.NoError(t, err)
+	assert.True(t, seeds[1].GetSki().Equal(opened.GetSki()))
+
+	opened.Zeroize()
+	assert.True(t, opened.GetSki().IsZero())
+
+	bbsSeeds, err := generator.TrustedSeedGenBBS()
+	require.NoError(t, err)
+	entry, err = keystore.SealSeed([]byte("password"), "wallet", bbsSeeds[0], testParams)
+	require.NoError(t, err)
+	opened, err = entry.OpenSeed([]byte("password"))
+	require.NoError(t, err)
+	assert.True(t, bbsSeeds[0].GetSki().Equal(opened.GetSki()))
+}
+
+func TestUnmarshalEntryRejectsMalformedInput(t *testing.T) {
+	entry, err := keystore.SealPartySecretKey([]byte("password"), "wallet", testKey(), testParams)
+	require.NoError(t, err)
+	data, err := entry.Marshal()
+	require.NoError(t, err)
+
+	_, err = keystore.UnmarshalEntry(data[:len(data)-1])
+	assert.ErrorIs(t, err, helper.ErrInvalidEncoding)
+	_, err = keystore.UnmarshalEntry(append(data, 0))
+	assert.ErrorIs(t, err, helper.ErrInvalidEncoding)
+
+	// Excessive KDF memory.
+	hostile, err := keystore.Seal([]byte("password"), entry.Metadata, []byte("secret"), testParams)
+	require.NoError(t, err)
+	data, err = hostile.Marshal()
+	require.NoError(t, err)
+	memoryOffset := 4 + 3 + len("wallet") + 4 + 8 + 4
+	data[memoryOffset+3] = 0xff
+	_, err = keystore.UnmarshalEntry(data)
+	assert.ErrorIs(t, err, helper.ErrInvalidEncoding)
+	binary.LittleEndian.PutUint32(data[memoryOffset:], 512*1024)
+	_, err = keystore.UnmarshalEntry(data)
+	assert.ErrorIs(t, err, helper.ErrInvalidEncoding)
+
+	_, err = keystore.Seal([]byte("password"), entry.Metadata, []byte("secret"), keystore.KDFParams{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,140 @@
+package keystore
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
+	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation/pcg"
+)
+
+// SealPartySecretKey encrypts the key share under password. The index and epoch of the key are stored as metadata.
+func SealPartySecretKey(password []byte, keyID string, key *fhks_bbs_plus.PartySecretKey, params KDFParams) (*Entry, error) {
+	plaintext, err := key.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(plaintext)
+
+	metadata := Metadata{Kind: KindPartySecretKey, KeyID: keyID, PartyIndex: key.Index, Epoch: key.Epoch}
+	return Seal(password, metadata, plaintext, params)
+}
+
+// OpenPartySecretKey decrypts a key share sealed with SealPartySecretKey. The caller should Zeroize the key after use.
+func (e *Entry) OpenPartySecretKey(password []byte) (*fhks_bbs_plus.PartySecretKey, error) {
+	plaintext, err := e.open(password, KindPartySecretKey)
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(plaintext)
+
+	key, err := fhks_bbs_plus.UnmarshalPartyPrivateKey(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.checkMetadata(key.Index, key.Epoch); err != nil {
+		key.Zeroize()
+		return nil, err
+	}
+	return key, nil
+}
+
+// SealSeed encrypts the PCG seed under password. The index and epoch of the seed are stored as metadata.
+func SealSeed(password []byte, keyID string, seed *pcg.Seed, params KDFParams) (*Entry, error) {
+	plaintext, err := seed.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(plaintext)
+
+	metadata := Metadata{Kind: KindPCGSeed, KeyID: keyID, PartyIndex: seed.GetIndex(), Epoch: seed.GetEpoch()}
+	return Seal(password, metadata, plaintext, params)
+}
+
+// OpenSeed decrypts a PCG seed sealed with SealSeed. The caller should Zeroize the seed after use.
+func (e *Entry) OpenSeed(password []byte) (*pcg.Seed, error) {
+	plaintext, err := e.open(password, KindPCGSeed)
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(plaintext)
+
+	seed := &pcg.Seed{}
+	if err := seed.Deserialize(plaintext); err != nil {
+		return nil, err
+	}
+	if err := e.checkMetadata(seed.GetIndex(), seed.GetEpoch()); err != nil {
+		seed.Zeroize()
+		return nil, err
+	}
+	return seed, nil
+}
+
+// SealPreSignatures encrypts a batch of pre-signatures under password. All pre-signatures must be of the same epoch,
+// which is recorded in the metadata.
+func SealPreSignatures(password []byte, keyID string, ppp *fhks_bbs_plus.PerPartyPrecomputationsWithPubKey,
+	params KDFParams) (*Entry, error) {
+	// The number of parties is not part of the encoding of the precomputations.
+	n := 1
+	var epoch uint64
+	if len(ppp.PreSignatures) > 0 {
+		n = len(ppp.PreSignatures[0].AeTermsA)
+		epoch = ppp.PreSignatures[0].Epoch
+	}
+	encoded, err := ppp.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(encoded)
+	plaintext := binary.LittleEndian.AppendUint32(make([]byte, 0, 4+len(encoded)), uint32(n))
+	plaintext = append(plaintext, encoded...)
+	defer Zeroize(plaintext)
+
+	metadata := Metadata{Kind: KindPreSignatures, KeyID: keyID, PartyIndex: ppp.Index, Epoch: epoch}
+	return Seal(password, metadata, plaintext, params)
+}
+
+// OpenPreSignatures decrypts a batch of pre-signatures sealed with SealPreSignatures and checks their epoch against
+// the metadata. The caller should Zeroize the precomputations after use.
+func (e *Entry) OpenPreSignatures(password []byte) (*fhks_bbs_plus.PerPartyPrecomputationsWithPubKey, error) {
+	plaintext, err := e.open(password, KindPreSignatures)
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(plaintext)
+
+	if len(plaintext) < 4 {
+		return nil, fmt.Errorf("%w: missing number of parties", fhks_bbs_plus.ErrInvalidEncoding)
+	}
+	ppp, err := fhks_bbs_plus.PerPartyPrecomputationsWithPubKeyFromBytes(plaintext[4:], int(binary.LittleEndian.Uint32(plaintext)))
+	if err != nil {
+		return nil, err
+	}
+	for _, preSignature := range ppp.PreSignatures {
+		if err := e.checkMetadata(ppp.Index, preSignature.Epoch); err != nil {
+			ppp.Zeroize()
+			return nil, err
+		}
+	}
+	if err := e.checkMetadata(ppp.Index, e.Metadata.Epoch); err != nil {
+		ppp.Zeroize()
+		return nil, err
+	}
+	return ppp, nil
+}
+
+func (e *Entry) open(password []byte, kind Kind) ([]byte, error) {
+	if e.Metadata.Kind != kind {
+		return nil, fmt.Errorf("%w: expected %s, got %s", ErrKindMismatch, kind, e.Metadata.Kind)
+	}
+	return e.Open(password)
+}
+
+// checkMetadata checks that the decrypted material matches the authenticated metadata of the entry.
+func (e *Entry) checkMetadata(index int, epoch uint64) error {
+	if index != e.Metadata.PartyIndex || epoch != e.Metadata.Epoch {
+		return fmt.Errorf("material of party %d in epoch %d does not match metadata of party %d in epoch %d",
+			index, epoch, e.Metadata.PartyIndex, e.Metadata.Epoch)
+	}
+	return nil
+}
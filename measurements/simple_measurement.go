@@ -50,6 +50,10 @@ func SimpleMeasurementWithCoefficientComputation() {
 		var partialSignatures []*fhks_bbs_plus.PartialThresholdSignature
 		for iT := 0; iT < t; iT++ {
 			ownIndex := indices[iK][iT]
+			keyShare := &fhks_bbs_plus.PartySecretKey{
+				SKeyShare: fhks_bbs_plus.SecretKey{Fr: preComputation[ownIndex-1].SkShare},
+				Index:     ownIndex,
+			}
 			start := time.Now()
 			livePresignature := fhks_bbs_plus.NewLivePreSignature().FromPreSignature(
 				ownIndex,
@@ -58,11 +62,15 @@ func SimpleMeasurementWithCoefficientComputation() {
 			)
 			makeLiveDurations = append(makeLiveDurations, time.Since(start))
 			start = time.Now()
-			partialThresholdSignature := fhks_bbs_plus.NewPartialThresholdSignature().New(
+			partialThresholdSignature, err := fhks_bbs_plus.NewPartialThresholdSignature().New(
 				messages[iK],
 				pk,
+				keyShare,
 				livePresignature,
 			)
+			if err != nil {
+				panic(err)
+			}
 			thresholdSignDurations = append(thresholdSignDurations, time.Since(start))
 			partialSignatures = append(partialSignatures, partialThresholdSignature)
 		}
@@ -0,0 +1,403 @@
+package optreedpf
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+
+	bls12381 "github.com/kilic/bls12-381"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation/dpf"
+	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation/ot"
+)
+
+// frBits is the bit length of the elements of Fr.
+const frBits = 255
+
+// node is a node of the evaluation tree of a key under generation.
+type node struct {
+	s []byte
+	t bool
+}
+
+// valueSharing describes how the non-zero element is split between the parties of the distributed key generation.
+type valueSharing int
+
+const (
+	multiplicativeValues valueSharing = iota // beta = values_0 * values_1
+	additiveValues                           // beta = values_0 + values_1
+)
+
+// DistributedGen generates DPF keys together with the other party over session, so that neither party learns the
+// special points or the non-zero elements. Party id (0 or 1) inputs points and values, and the k-th key has the
+// special point points_0[k] + points_1[k] and the non-zero element values_0[k] * values_1[k]. The sum of the points
+// must not exceed AlphaMax, and both parties must call DistributedGen with the same number of keys. The keys of party
+// id are returned; the other party obtains the matching keys with the other ID. The keys are standard keys, which
+// Eval and FullEvalFast accept like the ones of Gen.
+//
+// If party 0 holds the special point alpha and party 1 the non-zero element beta, party 0 inputs (alpha, 1) and
+// party 1 inputs (0, beta).
+//
+// The keys are generated with the protocol of Doerner and shelat ("Scaling ORAM for Secure Computation"), in which
+// both parties expand their whole trees: the bits of the special point are XOR-shared by a GMW adder, the correction
+// words of the tree levels are computed with one OT per party and level, and the final correction word with Gilboa's
+// OT-based multiplication. The protocol is only secure against semi-honest parties. Its cost is linear in the domain,
+// which makes it practical for the domains of the PCG only.
+func (d *OpTreeDPF) DistributedGen(session *ot.Session, id uint8, points []*big.Int, values []*bls12381.Fr) ([]*Key, error) {
+	return d.distributedGen(session, id, points, values, multiplicativeValues)
+}
+
+// DistributedGenAdditive is DistributedGen for non-zero elements that are additively shared: the k-th key has the
+// non-zero element values_0[k] + values_1[k].
+func (d *OpTreeDPF) DistributedGenAdditive(session *ot.Session, id uint8, points []*big.Int, values []*bls12381.Fr) ([]*Key, error) {
+	return d.distributedGen(session, id, points, values, additiveValues)
+}
+
+func (d *OpTreeDPF) distributedGen(session *ot.Session, id uint8, points []*big.Int, values []*bls12381.Fr, sharing valueSharing) ([]*Key, error) {
+	if id > 1 {
+		return nil, errors.New("the party ID can only be 0 or 1")
+	}
+	if len(points) != len(values) {
+		return nil, errors.New("the number of special points and non-zero elements must match")
+	}
+	for k, point := range points {
+		if point.Sign() < 0 || point.Cmp(d.AlphaMax) == 1 {
+			return nil, fmt.Errorf("special point %d is not within the domain of the DPF", k)
+		}
+	}
+
+	alpha, err := d.shareSpecialPoints(session, id, points)
+	if err != nil {
+		return nil, fmt.Errorf("share special points: %w", err)
+	}
+
+	// Step 2-3: Random seeds and the control bits 0 (ID 0) and 1 (ID 1) at the roots.
+	seedLength := d.Lambda / 8
+	keys := make([]*Key, len(points))
+	trees := make([][]node, len(points))
+	for k := range keys {
+		keys[k] = &Key{ID: id, S: dpf.RandomSeed(seedLength), CW: make(map[int]CorrectionWord)}
+		trees[k] = []node{{s: keys[k].S, t: id == 1}}
+	}
+
+	// Step 4-13: Correction words of the levels.
+	for i := 0; i < d.DomainBitLength; i++ {
+		bit := d.DomainBitLength - 1 - i // alpha is shared least significant bit first.
+		shares := make([]bool, len(keys))
+		for k := range keys {
+			shares[k] = alpha[k][bit]
+		}
+		cws, err := d.genLevel(session, trees, shares)
+		if err != nil {
+			return nil, fmt.Errorf("level %d: %w", i, err)
+		}
+		for k := range keys {
+			keys[k].CW[i] = cws[k]
+		}
+	}
+
+	// Step 15: Final correction word that hides beta.
+	finals, err := d.genFinal(session, id, trees, values, sharing)
+	if err != nil {
+		return nil, fmt.Errorf("final correction word: %w", err)
+	}
+	for k := range keys {
+		keys[k].CW[d.DomainBitLength] = CorrectionWord{S: finals[k]}
+	}
+	return keys, nil
+}
+
+// shareSpecialPoints adds the points of both parties with a GMW ripple-carry adder and returns XOR shares of the bits
+// of the sums, least significant bit first.
+func (d *OpTreeDPF) shareSpecialPoints(session *ot.Session, id uint8, points []*big.Int) ([][]bool, error) {
+	n := d.DomainBitLength
+	alpha := make([][]bool, len(points))
+	carry := make([]bool, len(points))
+	for k := range alpha {
+		alpha[k] = make([]bool, n)
+	}
+
+	for bit := 0; bit < n; bit++ {
+		for k, point := range points {
+			alpha[k][bit] = (point.Bit(bit) == 1) != carry[k]
+		}
+		if bit == n-1 {
+			break
+		}
+
+		// carry' = carry ^ ((a ^ carry) & (b ^ carry)), where a is the bit of party 0 and b the one of party 1.
+		x := make([]bool, len(points))
+		y := make([]bool, len(points))
+		for k, point := range points {
+			own := point.Bit(bit) == 1
+			if id == 0 {
+				x[k], y[k] = own != carry[k], carry[k]
+			} else {
+				x[k], y[k] = carry[k], own != carry[k]
+			}
+		}
+		z, err := and(session, x, y)
+		if err != nil {
+			return nil, fmt.Errorf("carry of bit %d: %w", bit, err)
+		}
+		for k := range carry {
+			carry[k] = carry[k] != z[k]
+		}
+	}
+	return alpha, nil
+}
+
+// and returns XOR shares of (x ^ x') & (y ^ y') for the shares x, y of this party and x', y' of the other party.
+// The cross terms x & y' and x' & y are computed with one OT in each direction.
+func and(session *ot.Session, x, y []bool) ([]bool, error) {
+	rho := ot.RandomBits(len(x))
+	msgs := make([][2][]byte, len(x))
+	for k := range msgs {
+		msgs[k] = [2][]byte{boolToByteSlice(rho[k]), boolToByteSlice(rho[k] != y[k])}
+	}
+	received, err := session.Transfer(msgs, x, 1)
+	if err != nil {
+		return nil, err
+	}
+	z := make([]bool, len(x))
+	for k := range z {
+		z[k] = (x[k] && y[k]) != rho[k] != (received[k][0]&1 == 1)
+	}
+	return z, nil
+}
+
+// genLevel expands the current level of the trees, computes the correction words of the level from the shares of the
+// bits of the special points at the level and replaces the trees by their corrected children.
+func (d *OpTreeDPF) genLevel(session *ot.Session, trees [][]node, shares []bool) ([]CorrectionWord, error) {
+	const L = 0
+	const R = 1
+	seedLength := d.Lambda / 8
+
+	// Step 5: Call PRG for all nodes and sum up the children, which only differ from the ones of the other party
+	// on the path to the special point.
+	children := make([][]node, len(trees))
+	sigma := make([][2][]byte, len(trees))
+	tau := make([][2]bool, len(trees))
+	for k, tree := range trees {
+		children[k] = make([]node, 0, 2*len(tree))
+		sigma[k] = [2][]byte{make([]byte, seedLength), make([]byte, seedLength)}
+		for _, parent := range tree {
+			sl, tl, sr, tr, err := splitPRGOutput(dpf.PRG(parent.s, d.prgOutputLength), d.Lambda)
+			if err != nil {
+				return nil, err
+			}
+			children[k] = append(children[k], node{s: sl, t: tl}, node{s: sr, t: tr})
+			sigma[k][L] = dpf.XORBytes(sigma[k][L], sl)
+			sigma[k][R] = dpf.XORBytes(sigma[k][R], sr)
+			tau[k][L] = tau[k][L] != tl
+			tau[k][R] = tau[k][R] != tr
+		}
+	}
+
+	// Step 6-11: The seed correction word is the sum of the loose children of both parties. Each party obtains the
+	// other party's loose sum masked with rho by an OT with its share of the bit as choice.
+	rho := make([][]byte, len(trees))
+	msgs := make([][2][]byte, len(trees))
+	for k := range trees {
+		rho[k] = dpf.RandomSeed(seedLength)
+		for c := 0; c < 2; c++ {
+			loose := R // Loose is L if the bit alpha = c ^ share is 1.
+			if (c == 1) != shares[k] {
+				loose = L
+			}
+			msgs[k][c] = dpf.XORBytes(rho[k], sigma[k][loose])
+		}
+	}
+	received, err := session.Transfer(msgs, shares, seedLength)
+	if err != nil {
+		return nil, err
+	}
+
+	// The masked sums and the control bit sums, which are masked with the shares of the bit, are exchanged.
+	reveal := make([]byte, 0, len(trees)*(seedLength+2))
+	for k := range trees {
+		reveal = append(reveal, dpf.XORBytes(rho[k], received[k])...)
+		reveal = append(reveal, boolToByteSlice(tau[k][L] != shares[k])...)
+		reveal = append(reveal, boolToByteSlice(tau[k][R] != shares[k])...)
+	}
+	peerReveal, err := session.Exchange(reveal)
+	if err != nil {
+		return nil, err
+	}
+	if len(peerReveal) != len(reveal) {
+		return nil, fmt.Errorf("correction word shares have %d bytes, expected %d", len(peerReveal), len(reveal))
+	}
+
+	cws := make([]CorrectionWord, len(trees))
+	for k := range trees {
+		own := reveal[k*(seedLength+2) : (k+1)*(seedLength+2)]
+		peer := peerReveal[k*(seedLength+2) : (k+1)*(seedLength+2)]
+		cws[k] = CorrectionWord{
+			S:  dpf.XORBytes(own[:seedLength], peer[:seedLength]),
+			Tl: !(own[seedLength] != peer[seedLength]),
+			Tr: own[seedLength+1] != peer[seedLength+1],
+		}
+
+		// Step 12-13: Correct the children of nodes with control bit 1.
+		for x, parent := range trees[k] {
+			if parent.t {
+				left, right := &children[k][2*x], &children[k][2*x+1]
+				left.s = dpf.XORBytes(left.s, cws[k].S)
+				left.t = left.t != cws[k].Tl
+				right.s = dpf.XORBytes(right.s, cws[k].S)
+				right.t = right.t != cws[k].Tr
+			}
+		}
+		trees[k] = children[k]
+	}
+	return cws, nil
+}
+
+// genFinal computes the final correction words (-1)^t_1 * (beta - Convert(s_0) + Convert(s_1)) at the special points
+// from the leaves of the trees.
+//
+// The sums S of the converted leaves and T of (-1)^t over the leaves of both parties only differ at the special point,
+// so that S_0 - S_1 = Convert(s_0) - Convert(s_1) and the sign is shared by (T_1 - T_0) / 2. The products of the shares
+// of the two parties are computed with Gilboa's multiplication, in which party 0 chooses with the bits of its factors.
+func (d *OpTreeDPF) genFinal(session *ot.Session, id uint8, trees [][]node, values []*bls12381.Fr, sharing valueSharing) ([][]byte, error) {
+	half := bls12381.NewFr()
+	half.Inverse(bls12381.NewFr().FromBytes([]byte{2}))
+
+	// The correction word is the sum of the local terms and of the products of the factors of party 0 and 1:
+	//  - multiplicative: -sign_0 * S_0 + sign_1 * S_1 + (sign_0 * v_0) * v_1 + v_0 * (sign_1 * v_1) + sign_0 * S_1 +
+	//    S_0 * (-sign_1),
+	//  - additive: sign_0 * (v_0 - S_0) + sign_1 * (v_1 + S_1) + sign_0 * (v_1 + S_1) + (v_0 - S_0) * sign_1.
+	factors := 4
+	if sharing == additiveValues {
+		factors = 2
+	}
+	local := make([]*bls12381.Fr, len(trees))
+	ownFactors := make([]*bls12381.Fr, 0, factors*len(trees))
+	for k, tree := range trees {
+		S := bls12381.NewFr().Zero()
+		T := bls12381.NewFr().Zero()
+		one := bls12381.NewFr().One()
+		for _, leaf := range tree {
+			converted, err := d.convert(new(big.Int).SetBytes(leaf.s))
+			if err != nil {
+				return nil, err
+			}
+			S.Add(S, converted)
+			if leaf.t {
+				T.Sub(T, one)
+			} else {
+				T.Add(T, one)
+			}
+		}
+
+		sign := bls12381.NewFr()
+		sign.Mul(T, half)
+		if id == 0 {
+			sign.Neg(sign)
+		}
+		local[k] = bls12381.NewFr()
+		switch {
+		case sharing == additiveValues:
+			value := bls12381.NewFr()
+			if id == 0 {
+				value.Sub(values[k], S)
+				ownFactors = append(ownFactors, sign, value)
+			} else {
+				value.Add(values[k], S)
+				ownFactors = append(ownFactors, value, sign)
+			}
+			local[k].Mul(sign, value)
+		case id == 0:
+			local[k].Mul(sign, S)
+			local[k].Neg(local[k])
+
+			signValue := bls12381.NewFr()
+			signValue.Mul(sign, values[k])
+			ownFactors = append(ownFactors, signValue, values[k], sign, S)
+		default:
+			local[k].Mul(sign, S)
+
+			signValue := bls12381.NewFr()
+			signValue.Mul(sign, values[k])
+			negSign := bls12381.NewFr()
+			negSign.Neg(sign)
+			ownFactors = append(ownFactors, values[k], signValue, S, negSign)
+		}
+	}
+
+	products, err := gilboa(session, id, ownFactors)
+	if err != nil {
+		return nil, err
+	}
+
+	shares := make([]byte, 0, len(trees)*32)
+	for k := range trees {
+		for f := 0; f < factors; f++ {
+			local[k].Add(local[k], products[k*factors+f])
+		}
+		shares = append(shares, local[k].ToBytes()...)
+	}
+	peerShares, err := session.Exchange(shares)
+	if err != nil {
+		return nil, err
+	}
+	if len(peerShares) != len(shares) {
+		return nil, fmt.Errorf("final correction word shares have %d bytes, expected %d", len(peerShares), len(shares))
+	}
+
+	finals := make([][]byte, len(trees))
+	for k := range trees {
+		cw := bls12381.NewFr().FromBytes(peerShares[k*32 : (k+1)*32])
+		cw.Add(cw, local[k])
+		finals[k] = cw.ToBytes()
+	}
+	return finals, nil
+}
+
+// gilboa returns additive shares of the products of the factors of party 0 and the ones of party 1. For every bit
+// of a factor u of party 0, party 1 with factor v offers (r, r + 2^bit * v) for a random r.
+func gilboa(session *ot.Session, id uint8, factors []*bls12381.Fr) ([]*bls12381.Fr, error) {
+	products := make([]*bls12381.Fr, len(factors))
+	if id == 0 {
+		choices := make([]bool, 0, len(factors)*frBits)
+		for _, u := range factors {
+			uBig := u.ToBig()
+			for bit := 0; bit < frBits; bit++ {
+				choices = append(choices, uBig.Bit(bit) == 1)
+			}
+		}
+		received, err := session.Transfer(nil, choices, 32)
+		if err != nil {
+			return nil, err
+		}
+		for f := range factors {
+			products[f] = bls12381.NewFr().Zero()
+			for bit := 0; bit < frBits; bit++ {
+				products[f].Add(products[f], bls12381.NewFr().FromBytes(received[f*frBits+bit]))
+			}
+		}
+		return products, nil
+	}
+
+	msgs := make([][2][]byte, 0, len(factors)*frBits)
+	for f, v := range factors {
+		products[f] = bls12381.NewFr().Zero()
+		shifted := bls12381.NewFr().Set(v)
+		for bit := 0; bit < frBits; bit++ {
+			r, err := bls12381.NewFr().Rand(rand.Reader)
+			if err != nil {
+				return nil, err
+			}
+			rShifted := bls12381.NewFr()
+			rShifted.Add(r, shifted)
+			msgs = append(msgs, [2][]byte{r.ToBytes(), rShifted.ToBytes()})
+			products[f].Sub(products[f], r)
+			shifted.Double(shifted)
+		}
+	}
+	if _, err := session.Transfer(msgs, nil, 32); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
@@ -0,0 +1,174 @@
+package optreedpf_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation/dpf/optreedpf"
+	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation/ot"
+)
+
+func TestOpTreeDPFDistributedGen128(t *testing.T) {
+	testOpTreeDPFDistributedGen(t, 128, 8)
+}
+
+func TestOpTreeDPFDistributedGen256(t *testing.T) {
+	testOpTreeDPFDistributedGen(t, 256, 5)
+}
+
+func testOpTreeDPFDistributedGen(t *testing.T, lambda int, domain int) {
+	d, err := optreedpf.InitFactory(lambda, domain)
+	require.NoError(t, err)
+
+	// Points of both parties whose sums stay within the domain, including the edge cases 0 and AlphaMax.
+	half := new(big.Int).Rsh(d.AlphaMax, 1)
+	points := [2][]*big.Int{{big.NewInt(0), new(big.Int).Set(d.AlphaMax), half}, {big.NewInt(0), big.NewInt(0), new(big.Int).Add(half, big.NewInt(1))}}
+	for k := 0; k < 5; k++ {
+		a, _ := rand.Int(rand.Reader, new(big.Int).Add(half, big.NewInt(1)))
+		b, _ := rand.Int(rand.Reader, new(big.Int).Add(half, big.NewInt(1)))
+		points[0] = append(points[0], a)
+		points[1] = append(points[1], b)
+	}
+	var values [2][]*bls12381.Fr
+	for id := range values {
+		for range points[id] {
+			v, _ := bls12381.NewFr().Rand(rand.Reader)
+			values[id] = append(values[id], v)
+		}
+	}
+
+	keys := runDistributedGen(t, d, points, values, false)
+
+	for k := range points[0] {
+		alpha := new(big.Int).Add(points[0][k], points[1][k])
+		beta := bls12381.NewFr()
+		beta.Mul(values[0][k], values[1][k])
+		assertPointFunction(t, d, keys[0][k], keys[1][k], alpha, beta)
+	}
+}
+
+func TestOpTreeDPFDistributedGenPointAndValue(t *testing.T) {
+	d, err := optreedpf.InitFactory(128, 10)
+	require.NoError(t, err)
+
+	// Party 0 holds the special point and party 1 the non-zero element.
+	alpha := big.NewInt(777)
+	beta, _ := bls12381.NewFr().Rand(rand.Reader)
+	points := [2][]*big.Int{{alpha}, {big.NewInt(0)}}
+	values := [2][]*bls12381.Fr{{bls12381.NewFr().One()}, {beta}}
+	keys := runDistributedGen(t, d, points, values, false)
+	assertPointFunction(t, d, keys[0][0], keys[1][0], alpha, beta)
+
+	// The same with an additively shared non-zero element.
+	values = [2][]*bls12381.Fr{{bls12381.NewFr().Zero()}, {beta}}
+	keys = runDistributedGen(t, d, points, values, true)
+	assertPointFunction(t, d, keys[0][0], keys[1][0], alpha, beta)
+}
+
+func TestOpTreeDPFDistributedGenAdditive(t *testing.T) {
+	d, err := optreedpf.InitFactory(192, 7)
+	require.NoError(t, err)
+
+	// Both the special points and the non-zero elements are secret-shared.
+	var points [2][]*big.Int
+	var values [2][]*bls12381.Fr
+	for k := 0; k < 6; k++ {
+		a, _ := rand.Int(rand.Reader, big.NewInt(64))
+		b, _ := rand.Int(rand.Reader, big.NewInt(64))
+		v0, _ := bls12381.NewFr().Rand(rand.Reader)
+		v1, _ := bls12381.NewFr().Rand(rand.Reader)
+		points[0], points[1] = append(points[0], a), append(points[1], b)
+		values[0], values[1] = append(values[0], v0), append(values[1], v1)
+	}
+	// A zero non-zero element yields keys of the all-zero function.
+	points[0], points[1] = append(points[0], big.NewInt(3)), append(points[1], big.NewInt(4))
+	minusOne := bls12381.NewFr()
+	minusOne.Neg(bls12381.NewFr().One())
+	values[0], values[1] = append(values[0], bls12381.NewFr().One()), append(values[1], minusOne)
+
+	keys := runDistributedGen(t, d, points, values, true)
+
+	for k := range points[0] {
+		alpha := new(big.Int).Add(points[0][k], points[1][k])
+		beta := bls12381.NewFr()
+		beta.Add(values[0][k], values[1][k])
+		assertPointFunction(t, d, keys[0][k], keys[1][k], alpha, beta)
+	}
+}
+
+// assertPointFunction checks that the keys are standard keys of the point function with alpha and beta for Eval and
+// FullEvalFast.
+func assertPointFunction(t *testing.T, d *optreedpf.OpTreeDPF, key0, key1 *optreedpf.Key, alpha *big.Int, beta *bls12381.Fr) {
+	assert.Equal(t, uint8(0), key0.ID)
+	assert.Equal(t, uint8(1), key1.ID)
+	assert.NoError(t, key0.Validate())
+	assert.NoError(t, key1.Validate())
+
+	y0, err := d.Eval(key0, alpha)
+	require.NoError(t, err)
+	y1, err := d.Eval(key1, alpha)
+	require.NoError(t, err)
+	assert.Equal(t, 0, beta.ToBig().Cmp(d.CombineResults(y0, y1)))
+
+	res0, err := d.FullEvalFast(key0)
+	require.NoError(t, err)
+	res1, err := d.FullEvalFast(key1)
+	require.NoError(t, err)
+	combined, err := d.CombineMultipleResults(res0, res1)
+	require.NoError(t, err)
+	for x, y := range combined {
+		if int64(x) == alpha.Int64() {
+			assert.Equal(t, 0, beta.ToBig().Cmp(y), "special point %d", x)
+		} else {
+			assert.Equal(t, 0, y.Sign(), "point %d", x)
+		}
+	}
+}
+
+func TestOpTreeDPFDistributedGenInvalidInput(t *testing.T) {
+	d, err := optreedpf.InitFactory(128, 4)
+	require.NoError(t, err)
+
+	_, err = d.DistributedGen(nil, 2, nil, nil)
+	assert.Error(t, err)
+	_, err = d.DistributedGen(nil, 0, []*big.Int{big.NewInt(1)}, nil)
+	assert.Error(t, err)
+	_, err = d.DistributedGen(nil, 0, []*big.Int{big.NewInt(16)}, []*bls12381.Fr{bls12381.NewFr().One()})
+	assert.Error(t, err)
+	_, err = d.DistributedGenAdditive(nil, 0, []*big.Int{big.NewInt(-1)}, []*bls12381.Fr{bls12381.NewFr().One()})
+	assert.Error(t, err)
+}
+
+func runDistributedGen(t *testing.T, d *optreedpf.OpTreeDPF, points [2][]*big.Int, values [2][]*bls12381.Fr, additive bool) [2][]*optreedpf.Key {
+	connA, connB := ot.Pipe()
+	conns := [2]ot.Conn{connA, connB}
+
+	var keys [2][]*optreedpf.Key
+	var errs [2]error
+	done := make(chan struct{})
+	for id := range conns {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			session, err := ot.NewSession(conns[id])
+			if err != nil {
+				errs[id] = err
+				return
+			}
+			if additive {
+				keys[id], errs[id] = d.DistributedGenAdditive(session, uint8(id), points[id], values[id])
+			} else {
+				keys[id], errs[id] = d.DistributedGen(session, uint8(id), points[id], values[id])
+			}
+		}()
+	}
+	<-done
+	<-done
+	require.NoError(t, errs[0])
+	require.NoError(t, errs[1])
+	return keys
+}
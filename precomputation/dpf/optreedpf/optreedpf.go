@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"encoding/gob"
 	"errors"
+	"fmt"
 	bls12381 "github.com/kilic/bls12-381"
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
 	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation/dpf"
 	"math/big"
 )
@@ -29,14 +31,56 @@ func (k *Key) Serialize() ([]byte, error) {
 }
 
 // Deserialize takes a byte slice and populates the Key with the serialized data.
+// The Key is only changed if the data holds a well-formed key, see Validate.
 func (k *Key) Deserialize(data []byte) error {
 	buffer := bytes.NewBuffer(data)
 	decoder := gob.NewDecoder(buffer)
 
-	if err := decoder.Decode(k); err != nil {
-		return err
+	var key Key
+	if err := decoder.Decode(&key); err != nil {
+		return fmt.Errorf("%w: %w", helper.ErrInvalidEncoding, err)
+	}
+	if buffer.Len() != 0 {
+		return fmt.Errorf("%w: %d trailing bytes", helper.ErrInvalidEncoding, buffer.Len())
+	}
+	if err := key.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", helper.ErrInvalidEncoding, err)
 	}
 
+	*k = key
+	return nil
+}
+
+// Validate checks that the Key is well-formed: its ID is 0 or 1, its seed has a length of 128, 192 or 256 bits, it
+// has correction words for all levels 0, ..., n-1 of the tree with seeds as long as the initial seed and a final
+// correction word at level n that encodes a group element.
+func (k *Key) Validate() error {
+	if k.ID > 1 {
+		return errors.New("the key ID can only be 0 or 1")
+	}
+	if len(k.S) != 16 && len(k.S) != 24 && len(k.S) != 32 {
+		return fmt.Errorf("invalid seed length %d", len(k.S))
+	}
+	n := len(k.CW) - 1
+	if n < 0 {
+		return errors.New("the key has no correction words")
+	}
+	for i := 0; i < n; i++ {
+		cw, ok := k.CW[i]
+		if !ok {
+			return fmt.Errorf("missing correction word at level %d", i)
+		}
+		if len(cw.S) != len(k.S) {
+			return fmt.Errorf("invalid seed length %d of correction word at level %d", len(cw.S), i)
+		}
+	}
+	final, ok := k.CW[n]
+	if !ok {
+		return fmt.Errorf("missing final correction word at level %d", n)
+	}
+	if _, err := helper.FrFromCanonicalBytes(final.S); err != nil {
+		return fmt.Errorf("final correction word: %w", err)
+	}
 	return nil
 }
 
@@ -222,8 +266,8 @@ func (d *OpTreeDPF) Eval(key dpf.Key, x *big.Int) (*big.Int, error) {
 	if !ok {
 		return nil, errors.New("the given key is not a tree-based DPF key")
 	}
-	if tkey.ID > 1 {
-		return nil, errors.New("the given key is invalid as its ID can only be 0 or 1")
+	if err := d.checkKey(tkey); err != nil {
+		return nil, err
 	}
 
 	n := d.DomainBitLength
@@ -281,6 +325,20 @@ func (d *OpTreeDPF) Eval(key dpf.Key, x *big.Int) (*big.Int, error) {
 	return partialResult, nil
 }
 
+// checkKey checks that the key is well-formed and matches the security parameter and domain of the DPF.
+func (d *OpTreeDPF) checkKey(key *Key) error {
+	if err := key.Validate(); err != nil {
+		return fmt.Errorf("the given key is invalid: %w", err)
+	}
+	if len(key.S)*8 != d.Lambda {
+		return fmt.Errorf("the given key has a seed of %d bits, expected %d", len(key.S)*8, d.Lambda)
+	}
+	if len(key.CW) != d.DomainBitLength+1 {
+		return fmt.Errorf("the given key has %d correction words, expected %d", len(key.CW), d.DomainBitLength+1)
+	}
+	return nil
+}
+
 func (d *OpTreeDPF) GetDomain() int {
 	return d.DomainBitLength
 }
@@ -319,8 +377,8 @@ func (d *OpTreeDPF) FullEval(key dpf.Key) ([]*big.Int, error) {
 	if !ok {
 		return nil, errors.New("the given key is not a tree-based DPF key")
 	}
-	if tkey.ID > 1 {
-		return nil, errors.New("the given key is invalid as its ID can only be 0 or 1")
+	if err := d.checkKey(tkey); err != nil {
+		return nil, err
 	}
 
 	initT := tkey.ID != 0 // Interpret ID as boolean
@@ -342,8 +400,8 @@ func (d *OpTreeDPF) FullEvalFast(key dpf.Key) ([]*big.Int, error) {
 	if !ok {
 		return nil, errors.New("the given key is not a tree-based DPF key")
 	}
-	if tkey.ID > 1 {
-		return nil, errors.New("the given key is invalid as its ID can only be 0 or 1")
+	if err := d.checkKey(tkey); err != nil {
+		return nil, err
 	}
 
 	initT := tkey.ID != 0 // Interpret ID as boolean
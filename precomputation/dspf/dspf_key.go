@@ -3,6 +3,8 @@ package dspf
 import (
 	"bytes"
 	"encoding/gob"
+	"fmt"
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
 	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation/dpf"
 	"io"
 )
@@ -38,12 +40,12 @@ func (k *Key) SerializeKeys() ([]byte, error) {
 }
 
 // DeserializeKeys deserializes the byte slice into DPFKeys.
+// The Key is only changed if all DPF keys in the data are well-formed.
 func (k *Key) DeserializeKeys(data []byte) error {
 	buf := bytes.NewBuffer(data)
 	decoder := gob.NewDecoder(buf)
 
-	k.DPFKeys = nil // Clear existing keys
-
+	var keys []dpf.Key
 	for {
 		var typeID dpf.KeyType
 		err := decoder.Decode(&typeID) // First, decode the type identifier
@@ -51,28 +53,29 @@ func (k *Key) DeserializeKeys(data []byte) error {
 			if err == io.EOF {
 				break // We've reached the end of the data stream
 			}
-			return err
+			return fmt.Errorf("%w: decode key type: %w", helper.ErrInvalidEncoding, err)
 		}
 
 		var keyData []byte
 		err = decoder.Decode(&keyData)
 		if err != nil {
-			return err
+			return fmt.Errorf("%w: decode key %d: %w", helper.ErrInvalidEncoding, len(keys), err)
 		}
 
 		key, err := CreateKeyFromTypeID(typeID) // Instantiate the key based on the typeID
 		if err != nil {
-			return err
+			return fmt.Errorf("%w: %w", helper.ErrInvalidEncoding, err)
 		}
 
 		err = key.Deserialize(keyData)
 		if err != nil {
-			return err
+			return fmt.Errorf("deserialize key %d: %w", len(keys), err)
 		}
 
-		k.DPFKeys = append(k.DPFKeys, key)
+		keys = append(keys, key)
 	}
 
+	k.DPFKeys = keys
 	return nil
 }
 
@@ -0,0 +1,37 @@
+package dspf
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
+	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation/dpf/optreedpf"
+)
+
+// FuzzDeserializeKeys checks that deserializing never panics and that accepted keys can be evaluated.
+func FuzzDeserializeKeys(f *testing.F) {
+	treedpf, err := optreedpf.InitFactory(128, 4)
+	require.NoError(f, err)
+	dspf := NewDSPFFactory(treedpf)
+	keyAlice, keyBob, err := dspf.Gen([]*big.Int{big.NewInt(1), big.NewInt(5)}, []*big.Int{big.NewInt(3), big.NewInt(61)})
+	require.NoError(f, err)
+	for _, key := range []Key{keyAlice, keyBob} {
+		data, err := key.SerializeKeys()
+		require.NoError(f, err)
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var key Key
+		if err := key.DeserializeKeys(data); err != nil {
+			require.ErrorIs(t, err, helper.ErrInvalidEncoding)
+			return
+		}
+		for _, dpfKey := range key.DPFKeys {
+			// Keys of other parameters are rejected by the evaluation, but must not make it panic.
+			_, _ = treedpf.Eval(dpfKey, big.NewInt(5))
+		}
+	})
+}
@@ -0,0 +1,319 @@
+// Package ot provides 1-out-of-2 oblivious transfer between two parties for the distributed generation of PCG seeds.
+//
+// A Session runs kappa base OTs in both directions with the protocol of Chou and Orlandi ("The Simplest Protocol for
+// Oblivious Transfer") over G1 of BLS12-381 and extends them to any number of OTs with the extension of Ishai, Kilian,
+// Nissim and Petrank ("Extending Oblivious Transfers Efficiently"). Both protocols are secure against semi-honest
+// (passive) adversaries only.
+package ot
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	bls12381 "github.com/kilic/bls12-381"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
+)
+
+// kappa is the computational security parameter in bits and the number of base OTs in each direction.
+const kappa = 128
+
+// kappaBytes is the length of a row of the extension matrix and of the keys of the base OTs.
+const kappaBytes = kappa / 8
+
+// Conn is an ordered and reliable channel to the other party, which must be private and authenticated.
+// Send must not wait until the other party receives the payload.
+type Conn interface {
+	Send(payload []byte) error
+	Receive() ([]byte, error)
+}
+
+// Session holds the base OTs between two parties. Both parties have to call the methods of their Session in the same
+// order, as every method sends messages to the other party and waits for its messages.
+type Session struct {
+	conn Conn
+
+	// Extension in which this party is the sender: delta holds the choices of the party in the base OTs and
+	// deltaKeys the keys it received.
+	delta     []byte
+	deltaKeys [kappa][]byte
+
+	// Extension in which this party is the receiver: the keys it sent in the base OTs.
+	keys0, keys1 [kappa][]byte
+
+	batch uint64 // Number of Transfer calls so far, used to separate the pseudorandom bits of the calls.
+}
+
+// NewSession runs the base OTs with the other party over conn.
+func NewSession(conn Conn) (*Session, error) {
+	g1 := bls12381.NewG1()
+	s := &Session{conn: conn}
+
+	// Base OTs in which this party is the sender, with secret a and public A = a*G.
+	a, err := bls12381.NewFr().Rand(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("sample base OT secret: %w", err)
+	}
+	A := g1.New()
+	g1.MulScalar(A, g1.One(), a)
+	peerAData, err := s.Exchange(g1.ToCompressed(A))
+	if err != nil {
+		return nil, fmt.Errorf("exchange base OT public keys: %w", err)
+	}
+	peerA, err := g1.FromCompressed(peerAData)
+	if err != nil {
+		return nil, fmt.Errorf("decode base OT public key: %w", err)
+	}
+
+	// Base OTs in which this party is the receiver: B = b*G + choice*A' and key H(b*A').
+	s.delta = randomBytes(kappaBytes)
+	B := make([]byte, 0, kappa*helper.LenBytesG1Compressed)
+	for i := 0; i < kappa; i++ {
+		b, err := bls12381.NewFr().Rand(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("sample base OT choice secret: %w", err)
+		}
+		Bi := g1.New()
+		g1.MulScalar(Bi, g1.One(), b)
+		if bit(s.delta, i) {
+			g1.Add(Bi, Bi, peerA)
+		}
+		B = append(B, g1.ToCompressed(Bi)...)
+
+		bA := g1.New()
+		g1.MulScalar(bA, peerA, b)
+		s.deltaKeys[i] = baseKey(g1, i, bA)
+	}
+	peerB, err := s.Exchange(B)
+	if err != nil {
+		return nil, fmt.Errorf("exchange base OT choices: %w", err)
+	}
+	if len(peerB) != kappa*helper.LenBytesG1Compressed {
+		return nil, fmt.Errorf("base OT choices have %d bytes, expected %d", len(peerB), kappa*helper.LenBytesG1Compressed)
+	}
+
+	// Keys of the sender: H(a*B) and H(a*(B - A)).
+	for i := 0; i < kappa; i++ {
+		Bi, err := g1.FromCompressed(peerB[i*helper.LenBytesG1Compressed : (i+1)*helper.LenBytesG1Compressed])
+		if err != nil {
+			return nil, fmt.Errorf("decode base OT choice %d: %w", i, err)
+		}
+		aB := g1.New()
+		g1.MulScalar(aB, Bi, a)
+		s.keys0[i] = baseKey(g1, i, aB)
+
+		g1.Sub(Bi, Bi, A)
+		g1.MulScalar(aB, Bi, a)
+		s.keys1[i] = baseKey(g1, i, aB)
+	}
+
+	return s, nil
+}
+
+// Exchange sends payload to the other party and returns the payload the other party sent in its matching call.
+func (s *Session) Exchange(payload []byte) ([]byte, error) {
+	if err := s.conn.Send(payload); err != nil {
+		return nil, err
+	}
+	return s.conn.Receive()
+}
+
+// Transfer runs a batch of OTs in both directions. In the OTs of this party, the other party obtains msgs[k][c] for its
+// k-th choice c; in the OTs of the other party, this party obtains the messages selected by choices, which are
+// returned. All messages in both directions must have a length of msgLen bytes, and the number of choices of each
+// party must match the number of message pairs of the other party.
+func (s *Session) Transfer(msgs [][2][]byte, choices []bool, msgLen int) ([][]byte, error) {
+	for k := range msgs {
+		if len(msgs[k][0]) != msgLen || len(msgs[k][1]) != msgLen {
+			return nil, fmt.Errorf("message pair %d does not have a length of %d bytes", k, msgLen)
+		}
+	}
+	batch := s.batch
+	s.batch++
+
+	// As receiver: t_i = G(k0_i) and u_i = t_i ^ G(k1_i) ^ r for the columns i of the extension matrix.
+	colBytes := (len(choices) + 7) / 8
+	r := packBits(choices)
+	t := make([][]byte, kappa)
+	u := make([]byte, 0, kappa*colBytes)
+	for i := 0; i < kappa; i++ {
+		t[i] = prg(s.keys0[i], batch, colBytes)
+		ui := prg(s.keys1[i], batch, colBytes)
+		xorInto(ui, t[i])
+		xorInto(ui, r)
+		u = append(u, ui...)
+	}
+	peerU, err := s.Exchange(u)
+	if err != nil {
+		return nil, fmt.Errorf("exchange extension matrix: %w", err)
+	}
+
+	// As sender: q_i = G(k_i) ^ delta_i * u_i, whose rows are q_k = t_k ^ r_k * delta.
+	peerColBytes := (len(msgs) + 7) / 8
+	if len(peerU) != kappa*peerColBytes {
+		return nil, fmt.Errorf("extension matrix has %d bytes, expected %d", len(peerU), kappa*peerColBytes)
+	}
+	q := make([][]byte, kappa)
+	for i := 0; i < kappa; i++ {
+		q[i] = prg(s.deltaKeys[i], batch, peerColBytes)
+		if bit(s.delta, i) {
+			xorInto(q[i], peerU[i*peerColBytes:(i+1)*peerColBytes])
+		}
+	}
+	qRows := transpose(q, len(msgs))
+	y := make([]byte, 0, 2*len(msgs)*msgLen)
+	for k := range msgs {
+		y0 := hashRow(batch, k, qRows[k], msgLen)
+		xorInto(y0, msgs[k][0])
+		xorInto(qRows[k], s.delta)
+		y1 := hashRow(batch, k, qRows[k], msgLen)
+		xorInto(y1, msgs[k][1])
+		y = append(append(y, y0...), y1...)
+	}
+	peerY, err := s.Exchange(y)
+	if err != nil {
+		return nil, fmt.Errorf("exchange masked messages: %w", err)
+	}
+
+	// As receiver: unmask the chosen messages with H(t_k).
+	if len(peerY) != 2*len(choices)*msgLen {
+		return nil, fmt.Errorf("masked messages have %d bytes, expected %d", len(peerY), 2*len(choices)*msgLen)
+	}
+	tRows := transpose(t, len(choices))
+	out := make([][]byte, len(choices))
+	for k, c := range choices {
+		offset := 2 * k * msgLen
+		if c {
+			offset += msgLen
+		}
+		out[k] = hashRow(batch, k, tRows[k], msgLen)
+		xorInto(out[k], peerY[offset:offset+msgLen])
+	}
+	return out, nil
+}
+
+// RandomBits returns n uniformly random bits.
+func RandomBits(n int) []bool {
+	data := randomBytes((n + 7) / 8)
+	bits := make([]bool, n)
+	for k := range bits {
+		bits[k] = bit(data, k)
+	}
+	return bits
+}
+
+// baseKey derives the key of base OT i from the shared point.
+func baseKey(g1 *bls12381.G1, i int, point *bls12381.PointG1) []byte {
+	h := sha256.New()
+	h.Write([]byte("ot-base"))
+	_ = binary.Write(h, binary.BigEndian, uint32(i))
+	h.Write(g1.ToCompressed(point))
+	return h.Sum(nil)[:kappaBytes]
+}
+
+// prg expands key to n pseudorandom bytes for the given batch with AES-CTR.
+func prg(key []byte, batch uint64, n int) []byte {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		panic(err) // The keys are always kappaBytes long.
+	}
+	iv := make([]byte, aes.BlockSize)
+	binary.BigEndian.PutUint64(iv, batch)
+	out := make([]byte, n)
+	cipher.NewCTR(block, iv).XORKeyStream(out, out)
+	return out
+}
+
+// hashRow hashes row k of the extension matrix of a batch to n bytes.
+func hashRow(batch uint64, k int, row []byte, n int) []byte {
+	out := make([]byte, 0, n+sha256.Size)
+	for block := uint32(0); len(out) < n; block++ {
+		h := sha256.New()
+		var prefix [16]byte
+		binary.BigEndian.PutUint64(prefix[:], batch)
+		binary.BigEndian.PutUint32(prefix[8:], uint32(k))
+		binary.BigEndian.PutUint32(prefix[12:], block)
+		h.Write(prefix[:])
+		h.Write(row)
+		out = h.Sum(out)
+	}
+	return out[:n]
+}
+
+// transpose returns the rows 0, ..., n-1 of the matrix with the given kappa columns.
+func transpose(cols [][]byte, n int) [][]byte {
+	rows := make([][]byte, n)
+	for k := range rows {
+		rows[k] = make([]byte, kappaBytes)
+	}
+	for i, col := range cols {
+		for k := 0; k < n; k++ {
+			if bit(col, k) {
+				rows[k][i/8] |= 1 << (i % 8)
+			}
+		}
+	}
+	return rows
+}
+
+func packBits(bits []bool) []byte {
+	data := make([]byte, (len(bits)+7)/8)
+	for k, b := range bits {
+		if b {
+			data[k/8] |= 1 << (k % 8)
+		}
+	}
+	return data
+}
+
+func bit(data []byte, k int) bool {
+	return data[k/8]>>(k%8)&1 == 1
+}
+
+func xorInto(dst, src []byte) {
+	for k := range dst {
+		dst[k] ^= src[k]
+	}
+}
+
+func randomBytes(n int) []byte {
+	data := make([]byte, n)
+	if _, err := rand.Read(data); err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// Pipe returns the two ends of a Conn within the same process. It is intended for tests.
+func Pipe() (Conn, Conn) {
+	ab := make(chan []byte, pipeBuffer)
+	ba := make(chan []byte, pipeBuffer)
+	return &pipeConn{send: ab, receive: ba}, &pipeConn{send: ba, receive: ab}
+}
+
+// pipeBuffer is the number of messages a pipe holds, which is more than the parties of the protocols in this
+// repository send before waiting for an answer.
+const pipeBuffer = 16
+
+type pipeConn struct {
+	send    chan<- []byte
+	receive <-chan []byte
+}
+
+func (c *pipeConn) Send(payload []byte) error {
+	c.send <- append([]byte(nil), payload...)
+	return nil
+}
+
+func (c *pipeConn) Receive() ([]byte, error) {
+	payload, ok := <-c.receive
+	if !ok {
+		return nil, errors.New("pipe closed")
+	}
+	return payload, nil
+}
@@ -0,0 +1,78 @@
+package ot
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newSessions(t *testing.T) (*Session, *Session) {
+	connA, connB := Pipe()
+	var sessionB *Session
+	var errB error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		sessionB, errB = NewSession(connB)
+	}()
+	sessionA, err := NewSession(connA)
+	<-done
+	require.NoError(t, err)
+	require.NoError(t, errB)
+	return sessionA, sessionB
+}
+
+func randomPairs(n, msgLen int) [][2][]byte {
+	msgs := make([][2][]byte, n)
+	for k := range msgs {
+		msgs[k] = [2][]byte{randomBytes(msgLen), randomBytes(msgLen)}
+	}
+	return msgs
+}
+
+func TestTransfer(t *testing.T) {
+	sessionA, sessionB := newSessions(t)
+
+	// Several batches with different sizes in both directions, including empty ones.
+	for _, sizes := range [][2]int{{1, 1}, {130, 7}, {0, 300}, {1000, 1000}} {
+		msgsA, msgsB := randomPairs(sizes[0], 32), randomPairs(sizes[1], 32)
+		choicesA, choicesB := RandomBits(sizes[1]), RandomBits(sizes[0])
+
+		var outB [][]byte
+		var errB error
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			outB, errB = sessionB.Transfer(msgsB, choicesB, 32)
+		}()
+		outA, err := sessionA.Transfer(msgsA, choicesA, 32)
+		<-done
+		require.NoError(t, err)
+		require.NoError(t, errB)
+
+		require.Len(t, outA, len(choicesA))
+		for k, c := range choicesA {
+			require.True(t, bytes.Equal(outA[k], msgsB[k][boolIndex(c)]), "OT %d of B", k)
+		}
+		require.Len(t, outB, len(choicesB))
+		for k, c := range choicesB {
+			require.True(t, bytes.Equal(outB[k], msgsA[k][boolIndex(c)]), "OT %d of A", k)
+		}
+	}
+}
+
+func TestTransferMessageLength(t *testing.T) {
+	sessionA, _ := newSessions(t)
+
+	// Messages of the wrong length are rejected before anything is sent.
+	_, err := sessionA.Transfer([][2][]byte{{make([]byte, 3), make([]byte, 4)}}, nil, 3)
+	require.Error(t, err)
+}
+
+func boolIndex(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
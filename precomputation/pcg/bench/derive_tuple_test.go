@@ -78,6 +78,101 @@ func benchmarkDeriveTuple(b *testing.B, N int) {
 	}
 }
 
+// Deriving all 2^N tuples, once root by root and once with a single transform per polynomial.
+func BenchmarkDeriveAllTuplesPerRoot_N10(b *testing.B) {
+	benchmarkDeriveAllTuplesPerRoot(b, 10)
+}
+func BenchmarkDeriveAllTuplesPerRoot_N11(b *testing.B) {
+	benchmarkDeriveAllTuplesPerRoot(b, 11)
+}
+func BenchmarkDeriveAllTuplesPerRoot_N12(b *testing.B) {
+	benchmarkDeriveAllTuplesPerRoot(b, 12)
+}
+func BenchmarkDeriveAllTuplesPerRoot_N13(b *testing.B) {
+	benchmarkDeriveAllTuplesPerRoot(b, 13)
+}
+func BenchmarkDeriveAllTuplesPerRoot_N14(b *testing.B) {
+	benchmarkDeriveAllTuplesPerRoot(b, 14)
+}
+
+func BenchmarkDeriveAllTuples_N10(b *testing.B) {
+	benchmarkDeriveAllTuples(b, 10)
+}
+func BenchmarkDeriveAllTuples_N11(b *testing.B) {
+	benchmarkDeriveAllTuples(b, 11)
+}
+func BenchmarkDeriveAllTuples_N12(b *testing.B) {
+	benchmarkDeriveAllTuples(b, 12)
+}
+func BenchmarkDeriveAllTuples_N13(b *testing.B) {
+	benchmarkDeriveAllTuples(b, 13)
+}
+func BenchmarkDeriveAllTuples_N14(b *testing.B) {
+	benchmarkDeriveAllTuples(b, 14)
+}
+func BenchmarkDeriveAllTuples_N15(b *testing.B) {
+	benchmarkDeriveAllTuples(b, 15)
+}
+func BenchmarkDeriveAllTuples_N16(b *testing.B) {
+	benchmarkDeriveAllTuples(b, 16)
+}
+func BenchmarkDeriveAllTuples_N17(b *testing.B) {
+	benchmarkDeriveAllTuples(b, 17)
+}
+func BenchmarkDeriveAllTuples_N18(b *testing.B) {
+	benchmarkDeriveAllTuples(b, 18)
+}
+func BenchmarkDeriveAllTuples_N19(b *testing.B) {
+	benchmarkDeriveAllTuples(b, 19)
+}
+func BenchmarkDeriveAllTuples_N20(b *testing.B) {
+	benchmarkDeriveAllTuples(b, 20)
+}
+
+func benchmarkDeriveAllTuplesPerRoot(b *testing.B, N int) {
+	tupleGenerator, ring := randomTupleGenerator(b, N)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, root := range ring.Roots {
+			_ = tupleGenerator.GenBBSPlusTuple(root)
+		}
+	}
+}
+
+func benchmarkDeriveAllTuples(b *testing.B, N int) {
+	tupleGenerator, _ := randomTupleGenerator(b, N)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := tupleGenerator.GenAllBBSPlusTuples()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// randomTupleGenerator returns a tuple generator with random polynomials of 2^N coefficients together with the ring.
+func randomTupleGenerator(b *testing.B, N int) (*pcg.BBSPlusTupleGenerator, *pcg.Ring) {
+	pcgenerator, err := pcg.NewPCG(128, N, 2, 2, 4, 16)
+	if err != nil {
+		b.Fatal(err)
+	}
+	ring, err := pcgenerator.GetRing(true)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	rng := rand.New(rand.NewSource(rand.Int63()))
+	sk, _ := bls12381.NewFr().Rand(rng)
+	pow2N := big.NewInt(0)
+	pow2N.Exp(big.NewInt(2), big.NewInt(int64(N)), nil)
+
+	// We can use random polynomials here, since we are only interested in the runtime of the tuple generation.
+	tupleGenerator := pcg.NewBBSPlusTupleGenerator(sk, randomPoly(pow2N), randomPoly(pow2N), randomPoly(pow2N), randomPoly(pow2N), randomPoly(pow2N), randomPoly(pow2N))
+	return tupleGenerator, ring
+}
+
 func randomPoly(n *big.Int) *poly.Polynomial {
 	slice := make([]*bls12381.Fr, n.Int64())
 
@@ -0,0 +1,220 @@
+package pcg
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	bls12381 "github.com/kilic/bls12-381"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation/dpf"
+	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation/dpf/optreedpf"
+	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation/dspf"
+	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation/ot"
+)
+
+// In a correlation embedded for the pair (i, j), party i holds the first and party j the second DPF key.
+const (
+	firstParty  uint8 = 0
+	secondParty uint8 = 1
+)
+
+// DistributedSeedGen generates the seed with the given index together with the other parties over transport, in
+// which the party is index+1 (see Transport), without a central dealer. skShare is the share of the secret key of the
+// party, e.g., its PartySecretKey from the dkg package. The seed is tagged with the epoch of p, which must be the epoch of the shares.
+//
+// Each party samples its own exponents and coefficients. For every pair of parties, the DSPF keys of the cross terms
+// (see embedVOLECorrelations and embedOLECorrelations) are generated by the two parties with the two-party DPF key
+// generation of optreedpf.DistributedGen, so that neither party learns the exponents, coefficients or key share of
+// the other. The protocol is secure against semi-honest parties only. All parties have to run DistributedSeedGen at
+// the same time with the same PCG parameters; p must not be used concurrently.
+func (p *PCG) DistributedSeedGen(transport Transport, index int, skShare *bls12381.Fr) (*Seed, error) {
+	return p.distributedSeedGen(transport, index, skShare, false)
+}
+
+// DistributedSeedGenBBS is the counterpart of DistributedSeedGen for BBS seeds, see SeedGenWithSkBBS.
+func (p *PCG) DistributedSeedGenBBS(transport Transport, index int, skShare *bls12381.Fr) (*Seed, error) {
+	return p.distributedSeedGen(transport, index, skShare, true)
+}
+
+func (p *PCG) distributedSeedGen(transport Transport, index int, skShare *bls12381.Fr, bbs bool) (*Seed, error) {
+	if index < 0 || index >= p.n {
+		return nil, fmt.Errorf("party index %d is not within [0, %d)", index, p.n)
+	}
+	if skShare == nil {
+		return nil, errors.New("missing secret key share")
+	}
+
+	// 1. Sample the own exponents and coefficients
+	seed := &Seed{
+		index: index,
+		epoch: p.epoch,
+		ski:   bls12381.NewFr().Set(skShare),
+		exponents: seedExponents{
+			aOmega: p.sampleOwnExponents(),
+			eEta:   p.sampleOwnExponents(),
+		},
+		coefficients: seedCoefficients{
+			aBeta:  p.sampleOwnCoefficients(),
+			eGamma: p.sampleOwnCoefficients(),
+		},
+		U: make([][]*DSPFKeyPair, p.n),
+		V: make([][][]*DSPFKeyPair, p.n),
+	}
+	if !bbs {
+		seed.exponents.sPhi = p.sampleOwnExponents()
+		seed.coefficients.sEpsilon = p.sampleOwnCoefficients()
+		seed.C = make([][][]*DSPFKeyPair, p.n)
+	}
+
+	dpfN, err := optreedpf.InitFactory(p.lambda, p.N)
+	if err != nil {
+		return nil, err
+	}
+	dpf2N, err := optreedpf.InitFactory(p.lambda, p.N+1)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Generate the DSPF keys with every other party
+	var wg sync.WaitGroup
+	errs := make([]error, p.n)
+	for j := 0; j < p.n; j++ {
+		if j == index {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := p.genPairSeed(&peerConn{transport: transport, peer: j + 1}, seed, j, dpfN, dpf2N, bbs); err != nil {
+				errs[j] = fmt.Errorf("party %d: %w", j, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		seed.Zeroize()
+		return nil, err
+	}
+
+	return seed, nil
+}
+
+// genPairSeed generates the DSPF keys of seed for the correlations of the party with party j: first the ones embedded
+// for the pair (min, max) of both indices, then the ones for (max, min).
+func (p *PCG) genPairSeed(conn ot.Conn, seed *Seed, j int, dpfN, dpf2N *optreedpf.OpTreeDPF, bbs bool) error {
+	session, err := ot.NewSession(conn)
+	if err != nil {
+		return fmt.Errorf("base OTs: %w", err)
+	}
+
+	roles := []uint8{firstParty, secondParty}
+	if seed.index > j {
+		roles = []uint8{secondParty, firstParty}
+	}
+
+	seed.U[j] = make([]*DSPFKeyPair, p.c)
+	for r := range seed.U[j] {
+		seed.U[j][r] = &DSPFKeyPair{}
+	}
+	seed.V[j] = newPairKeyMatrix(p.c)
+	if !bbs {
+		seed.C[j] = newPairKeyMatrix(p.c)
+	}
+
+	for _, role := range roles {
+		// Own key of the pair (index, j) in Key0, of the pair (j, index) in Key1.
+		own := func(pair *DSPFKeyPair) *dspf.Key {
+			if role == firstParty {
+				return &pair.Key0
+			}
+			return &pair.Key1
+		}
+
+		// VOLE (sk * a): special points omega_i and non-zero elements beta_i * sk_j.
+		points := make([]*big.Int, 0, p.c*p.t)
+		values := make([]*bls12381.Fr, 0, p.c*p.t)
+		for r := 0; r < p.c; r++ {
+			for k := 0; k < p.t; k++ {
+				if role == firstParty {
+					points = append(points, seed.exponents.aOmega[r][k])
+					values = append(values, seed.coefficients.aBeta[r][k])
+				} else {
+					points = append(points, big.NewInt(0))
+					values = append(values, seed.ski)
+				}
+			}
+		}
+		keys, err := dpfN.DistributedGen(session, role, points, values)
+		if err != nil {
+			return fmt.Errorf("VOLE keys: %w", err)
+		}
+		for r := 0; r < p.c; r++ {
+			*own(seed.U[j][r]) = dspfKey(keys[r*p.t : (r+1)*p.t])
+		}
+
+		// OLEs (a * s and a * e): special points omega_i[r] + o_j[s] and non-zero elements beta_i[r] * b_j[s] in the
+		// order of outerSumBigInt and outerProductFr.
+		type ole struct {
+			keys      [][]*DSPFKeyPair
+			exponents [][]*big.Int
+			coeffs    [][]*bls12381.Fr
+		}
+		oles := []ole{{seed.V[j], seed.exponents.eEta, seed.coefficients.eGamma}}
+		if !bbs {
+			oles = append(oles, ole{seed.C[j], seed.exponents.sPhi, seed.coefficients.sEpsilon})
+		}
+		points = points[:0]
+		values = values[:0]
+		for _, o := range oles {
+			for r := 0; r < p.c; r++ {
+				for s := 0; s < p.c; s++ {
+					for k := 0; k < p.t; k++ {
+						for l := 0; l < p.t; l++ {
+							if role == firstParty {
+								points = append(points, seed.exponents.aOmega[r][k])
+								values = append(values, seed.coefficients.aBeta[r][k])
+							} else {
+								points = append(points, o.exponents[s][l])
+								values = append(values, o.coeffs[s][l])
+							}
+						}
+					}
+				}
+			}
+		}
+		keys, err = dpf2N.DistributedGen(session, role, points, values)
+		if err != nil {
+			return fmt.Errorf("OLE keys: %w", err)
+		}
+		for _, o := range oles {
+			for r := 0; r < p.c; r++ {
+				for s := 0; s < p.c; s++ {
+					*own(o.keys[r][s]) = dspfKey(keys[:p.t*p.t])
+					keys = keys[p.t*p.t:]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func newPairKeyMatrix(c int) [][]*DSPFKeyPair {
+	m := make([][]*DSPFKeyPair, c)
+	for r := range m {
+		m[r] = make([]*DSPFKeyPair, c)
+		for s := range m[r] {
+			m[r][s] = &DSPFKeyPair{}
+		}
+	}
+	return m
+}
+
+func dspfKey(keys []*optreedpf.Key) dspf.Key {
+	dpfKeys := make([]dpf.Key, len(keys))
+	for k, key := range keys {
+		dpfKeys[k] = key
+	}
+	return dspf.Key{DPFKeys: dpfKeys}
+}
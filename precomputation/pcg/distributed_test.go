@@ -0,0 +1,171 @@
+package pcg
+
+import (
+	"testing"
+	"time"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/dkg"
+	fhksbbsplus "github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
+	"github.com/perun-network/bbs-plus-threshold-wallet/test"
+)
+
+// distributedSeeds runs the distributed seed generation for all parties, each with its own PCG, and returns the shared
+// secret key with the seeds.
+func distributedSeeds(t *testing.T, N, n, tau int, bbs bool) (*bls12381.Fr, []*Seed) {
+	dealer, err := NewPCG(128, N, n, tau, 2, 2) // Small lpn parameters for testing.
+	require.NoError(t, err)
+	sk, skShares := getShamirSharedRandomElement(dealer.rng, tau, n)
+
+	net := dkg.NewMemoryNetwork(n, time.Minute)
+	seeds := make([]*Seed, n)
+	errs := make([]error, n)
+	done := make(chan struct{})
+	for i := 0; i < n; i++ {
+		p, err := NewPCG(128, N, n, tau, 2, 2)
+		require.NoError(t, err)
+		p.SetEpoch(5)
+		go func() {
+			defer func() { done <- struct{}{} }()
+			if bbs {
+				seeds[i], errs[i] = p.DistributedSeedGenBBS(net.Direct(i+1), i, skShares[i])
+			} else {
+				seeds[i], errs[i] = p.DistributedSeedGen(net.Direct(i+1), i, skShares[i])
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		<-done
+	}
+	for i := 0; i < n; i++ {
+		require.NoError(t, errs[i])
+		assert.Equal(t, i, seeds[i].GetIndex())
+		assert.Equal(t, uint64(5), seeds[i].GetEpoch())
+		assert.Nil(t, seeds[i].U[i])
+	}
+	return sk, seeds
+}
+
+func TestDistributedSeedGenTau3N3(t *testing.T) {
+	sk, seeds := distributedSeeds(t, 8, 3, 3, false)
+
+	pcg, err := NewPCG(128, 8, 3, 3, 2, 2)
+	require.NoError(t, err)
+	randPolys, err := pcg.PickRandomPolynomials()
+	require.NoError(t, err)
+	ring, err := pcg.GetRing(false)
+	require.NoError(t, err)
+
+	evals := make([]*BBSPlusTupleGenerator, len(seeds))
+	for i, seed := range seeds {
+		evals[i], err = pcg.EvalCombined(seed, randPolys, ring.Div)
+		require.NoError(t, err)
+	}
+
+	for _, root := range []*bls12381.Fr{ring.Roots[0], ring.Roots[9], ring.Roots[len(ring.Roots)-1]} {
+		a, s, e := bls12381.NewFr().Zero(), bls12381.NewFr().Zero(), bls12381.NewFr().Zero()
+		alpha, delta, skSum := bls12381.NewFr().Zero(), bls12381.NewFr().Zero(), bls12381.NewFr().Zero()
+		for _, eval := range evals {
+			tuple := eval.GenBBSPlusTuple(root)
+			skSum.Add(skSum, tuple.SkShare)
+			a.Add(a, tuple.AShare)
+			s.Add(s, tuple.SShare)
+			e.Add(e, tuple.EShare)
+			alpha.Add(alpha, tuple.AlphaShare)
+			delta.Add(delta, tuple.DeltaShare)
+		}
+		assert.Equal(t, 0, sk.Cmp(skSum))
+
+		// Check if alpha = a*s and delta = a(sk + e) hold
+		as := bls12381.NewFr()
+		as.Mul(a, s)
+		assert.Equal(t, 0, alpha.Cmp(as))
+
+		skPe := bls12381.NewFr()
+		skPe.Add(sk, e)
+		askPae := bls12381.NewFr()
+		askPae.Mul(a, skPe)
+		assert.Equal(t, 0, delta.Cmp(askPae))
+	}
+}
+
+func TestDistributedSeedGenSignerTau2N3(t *testing.T) {
+	sk, seeds := distributedSeeds(t, 8, 3, 2, false)
+
+	pcg, err := NewPCG(128, 8, 3, 2, 2, 2)
+	require.NoError(t, err)
+	randPolys, err := pcg.PickRandomPolynomials()
+	require.NoError(t, err)
+	ring, err := pcg.GetRing(false)
+	require.NoError(t, err)
+
+	signerSet := []int{1, 2}
+	signers := make([]*Signer, len(signerSet))
+	for i, index := range signerSet {
+		signers[i], err = NewSigner(pcg, seeds[index], randPolys, ring)
+		require.NoError(t, err)
+	}
+
+	pk := fhksbbsplus.GeneratePublicKey(test.SeedKeys, sk, test.MessageCount)
+	messages := helper.GetRandomMessagesFromSeed(test.SeedMessages, 2, test.MessageCount)
+	for iK, message := range messages {
+		partialSignatures := make([]*fhksbbsplus.PartialThresholdSignature, len(signers))
+		for i, signer := range signers {
+			partialSignatures[i], err = signer.PartialSignature(fhksbbsplus.NewMemoryPreSignatureStore(), seedKeyShare(seeds[signerSet[i]]), iK, signerSet, message, pk)
+			require.NoError(t, err)
+		}
+		signature := fhksbbsplus.NewThresholdSignature().FromPartialSignatures(partialSignatures)
+		assert.True(t, signature.Verify(message, pk))
+	}
+}
+
+func TestDistributedSeedGenBBS(t *testing.T) {
+	sk, seeds := distributedSeeds(t, 8, 2, 2, true)
+	assert.Nil(t, seeds[0].C)
+
+	pcg, err := NewPCG(128, 8, 2, 2, 2, 2)
+	require.NoError(t, err)
+	randPolys, err := pcg.PickRandomPolynomials()
+	require.NoError(t, err)
+	ring, err := pcg.GetRing(false)
+	require.NoError(t, err)
+
+	eval0, err := pcg.EvalCombinedBBS(seeds[0], randPolys, ring.Div)
+	require.NoError(t, err)
+	eval1, err := pcg.EvalCombinedBBS(seeds[1], randPolys, ring.Div)
+	require.NoError(t, err)
+
+	tuple0 := eval0.GenBBSTuple(ring.Roots[3])
+	tuple1 := eval1.GenBBSTuple(ring.Roots[3])
+	a := bls12381.NewFr()
+	a.Add(tuple0.AShare, tuple1.AShare)
+	e := bls12381.NewFr()
+	e.Add(tuple0.EShare, tuple1.EShare)
+	delta := bls12381.NewFr()
+	delta.Add(tuple0.DeltaShare, tuple1.DeltaShare)
+
+	// Check if delta = a(sk + e) holds
+	skPe := bls12381.NewFr()
+	skPe.Add(sk, e)
+	expected := bls12381.NewFr()
+	expected.Mul(a, skPe)
+	assert.Equal(t, 0, delta.Cmp(expected))
+}
+
+func TestDistributedSeedGenMissingParty(t *testing.T) {
+	p, err := NewPCG(128, 8, 2, 2, 2, 2)
+	require.NoError(t, err)
+
+	net := dkg.NewMemoryNetwork(2, 100*time.Millisecond)
+	_, err = p.DistributedSeedGen(net.Direct(1), 0, bls12381.NewFr().One())
+	assert.ErrorIs(t, err, dkg.ErrTimeout)
+
+	_, err = p.DistributedSeedGen(net.Direct(1), 2, bls12381.NewFr().One())
+	assert.Error(t, err)
+	_, err = p.DistributedSeedGen(net.Direct(1), 0, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,40 @@
+package pcg
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
+)
+
+// FuzzSeedDeserialize checks that Deserialize never panics, only fails with ErrInvalidEncoding and only accepts seeds
+// whose dimensions fit together. The DSPF keys are gob encoded, so accepted inputs need not be canonical.
+func FuzzSeedDeserialize(f *testing.F) {
+	pcg, err := NewPCG(128, 10, 2, 2, 1, 1) // Smallest lpn parameters, large inputs slow down the fuzzer.
+	require.NoError(f, err)
+	seeds, err := pcg.TrustedSeedGen()
+	require.NoError(f, err)
+	data, err := seeds[1].Serialize()
+	require.NoError(f, err)
+	f.Add(data)
+	index := append([]byte{}, data...)
+	binary.LittleEndian.PutUint32(index[len(seedMagic)+2:], 0)
+	f.Add(index)
+	f.Add(data[:len(data)/2])
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		seed := &Seed{}
+		if err := seed.Deserialize(data); err != nil {
+			require.ErrorIs(t, err, helper.ErrInvalidEncoding)
+			return
+		}
+		require.NoError(t, seed.checkDimensions())
+		encoded, err := seed.Serialize()
+		require.NoError(t, err)
+		decoded := &Seed{}
+		require.NoError(t, decoded.Deserialize(encoded))
+		assertSeedsEqual(t, seed, decoded)
+	})
+}
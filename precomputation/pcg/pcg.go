@@ -26,6 +26,7 @@ type PCG struct {
 	dspfN  *dspf.DSPF // dpfN is the Distributed Sum of Point Function used to construct the PCG with domain N
 	dspf2N *dspf.DSPF // dpf2N is the Distributed Sum of Point Function used to construct the PCG with domain 2N
 	rng    *rand.Rand // rng is the random number generator used to sample the PCG seeds
+	epoch  uint64     // epoch is the key epoch the seeds are tagged with
 }
 
 // NewPCG creates a new BBS+ PCG with the given parameters.
@@ -60,6 +61,12 @@ func NewPCG(lambda, N, n, tau, c, t int) (*PCG, error) {
 	}, nil
 }
 
+// SetEpoch sets the key epoch the seeds generated by p are tagged with. Seeds have to be generated anew for every
+// epoch, as a refresh of the secret key shares invalidates the shares embedded in older seeds.
+func (p *PCG) SetEpoch(epoch uint64) {
+	p.epoch = epoch
+}
+
 // Define the ring we are working with.
 // The cyclotomic polynomial defined here is F(x)= x^((2^(N+1))/2) + 1
 // s.t. we can calculate N roots of unity r s.t. F(r) = 0
@@ -146,7 +153,7 @@ func (p *PCG) GetRing(fast bool) (*Ring, error) {
 }
 
 // TrustedSeedGen generates a seed for each party via a central dealer.
-// The goal is to realize a distributed generation.
+// The dealer learns all pre-signatures; DistributedSeedGen generates the seeds without a dealer.
 func (p *PCG) TrustedSeedGen() ([]*Seed, error) {
 	// Notation of the variables analogue to the notation from the formal definition of PCG
 	// 1. Generate key shares for each party
@@ -188,6 +195,7 @@ func (p *PCG) TrustedSeedGen() ([]*Seed, error) {
 		keyIndex := i
 		seeds[i] = &Seed{
 			index: i,
+			epoch: p.epoch,
 			ski:   skShares[keyIndex],
 			exponents: seedExponents{
 				aOmega: aOmega[i],
@@ -199,9 +207,9 @@ func (p *PCG) TrustedSeedGen() ([]*Seed, error) {
 				eGamma:   eGamma[i],
 				sEpsilon: sEpsilon[i],
 			},
-			U: U,
-			C: C,
-			V: V,
+			U: ownVOLEKeys(U, i),
+			C: ownOLEKeys(C, i),
+			V: ownOLEKeys(V, i),
 		}
 	}
 
@@ -263,6 +271,7 @@ func (p *PCG) SeedGenWithSk() (*bls12381.Fr, []*Seed, error) {
 		//}
 		seeds[i] = &Seed{
 			index: i,
+			epoch: p.epoch,
 			ski:   skShares[keyIndex],
 			exponents: seedExponents{
 				aOmega: aOmega[i],
@@ -274,9 +283,9 @@ func (p *PCG) SeedGenWithSk() (*bls12381.Fr, []*Seed, error) {
 				eGamma:   eGamma[i],
 				sEpsilon: sEpsilon[i],
 			},
-			U: U,
-			C: C,
-			V: V,
+			U: ownVOLEKeys(U, i),
+			C: ownOLEKeys(C, i),
+			V: ownOLEKeys(V, i),
 		}
 	}
 
@@ -383,7 +392,7 @@ func (p *PCG) EvalCombined(seed *Seed, rand []*poly.Polynomial, div *poly.Polyno
 	duration = endFinalShareVOLE.Sub(startFinalShareVOLE)
 	log.Println("Calculated final share polynomials for VOLE (delta0i) (in s): ", duration.Seconds())
 
-	oprand, err := outerProductPoly(rand, rand)
+	oprand, err := outerProductPoly(rand, rand, div)
 	if err != nil {
 		return nil, err
 	}
@@ -410,7 +419,10 @@ func (p *PCG) EvalCombined(seed *Seed, rand []*poly.Polynomial, div *poly.Polyno
 	duration = endTimeTotal.Sub(startTimeTotal)
 	log.Println("Total time for EVAL (in s): ", duration.Seconds())
 
-	return NewBBSPlusTupleGenerator(seed.ski, ai, ei, si, alphai, delta0i, delta1i), nil
+	generator := NewBBSPlusTupleGenerator(seed.ski, ai, ei, si, alphai, delta0i, delta1i)
+	generator.epoch = seed.epoch
+	generator.ringDegree = 1 << p.N
+	return generator, nil
 }
 
 // EvalSeparate evaluates the PCG for a tau-out-of-n setting.
@@ -534,7 +546,7 @@ func (p *PCG) EvalSeparate(seed *Seed, rand []*poly.Polynomial, div *poly.Polyno
 	duration = endFinalShareVOLE.Sub(startFinalShareVOLE)
 	log.Println("Calculated final share polynomials for VOLE (delta0i) (in s): ", duration.Seconds())
 
-	oprand, err := outerProductPoly(rand, rand)
+	oprand, err := outerProductPoly(rand, rand, div)
 	if err != nil {
 		return nil, err
 	}
@@ -579,7 +591,11 @@ func (p *PCG) EvalSeparate(seed *Seed, rand []*poly.Polynomial, div *poly.Polyno
 	duration = endTimeTotal.Sub(startTimeTotal)
 	log.Println("Total time for EVAL (in s): ", duration.Seconds())
 
-	return NewSeparateBBSPlusTupleGenerator(uskEval, ukEval, uvEval, seed.ski, ai, ei, si, delta0i, alphai, delta1i), nil
+	generator := NewSeparateBBSPlusTupleGenerator(uskEval, ukEval, uvEval, seed.ski, ai, ei, si, delta0i, alphai, delta1i)
+	generator.epoch = seed.epoch
+	generator.tau = p.tau
+	generator.ringDegree = 1 << p.N
+	return generator, nil
 }
 
 // PickRandomPolynomials picks c random polynomials of degree N. The last polynomial is not random and always 1.
@@ -0,0 +1,228 @@
+package pcg
+
+import (
+	"fmt"
+	"math/big"
+
+	bls12381 "github.com/kilic/bls12-381"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation/pcg/poly"
+)
+
+// The functions in this file are the counterparts of TrustedSeedGen, SeedGenWithSk, EvalCombined and EvalSeparate
+// for BBS signatures without s. As there is no alpha = a*s correlation, the seeds hold no s exponents, s coefficients
+// and C keys, and only the VOLE (a*sk) and a single OLE (a*e) are evaluated.
+
+// TrustedSeedGenBBS generates a BBS seed for each party via a central dealer.
+func (p *PCG) TrustedSeedGenBBS() ([]*Seed, error) {
+	_, seeds, err := p.SeedGenWithSkBBS()
+	return seeds, err
+}
+
+// SeedGenWithSkBBS generates a BBS seed for each party and returns the shared secret key along with them.
+func (p *PCG) SeedGenWithSkBBS() (*bls12381.Fr, []*Seed, error) {
+	// 1. Generate key shares for each party
+	sk, skShares := getShamirSharedRandomElement(p.rng, p.tau, p.n)
+
+	// 2a. Initialize aOmega and eEta by sampling at random from N
+	aOmega := p.sampleExponents() // a
+	eEta := p.sampleExponents()   // e
+
+	// 2b. Initialize aBeta and eGamma by sampling at random from F_q (via bls12381.Fr)
+	aBeta := p.sampleCoefficients()  // a
+	eGamma := p.sampleCoefficients() // e
+
+	// 3. Embed first part of delta (delta0) correlation (sk*a)
+	U, err := p.embedVOLECorrelations(aOmega, aBeta, skShares)
+	if err != nil {
+		return nil, nil, fmt.Errorf("step 3: failed to generate DSPF keys for first part of delta VOLE correlation (sk * a): %w", err)
+	}
+
+	// 4. Embed second part of delta (delta1) correlation (a*e)
+	V, err := p.embedOLECorrelations(aOmega, eEta, aBeta, eGamma)
+	if err != nil {
+		return nil, nil, fmt.Errorf("step 4: failed to generate DSPF keys for second part of delta OLE correlation (a * e): %w", err)
+	}
+
+	// 5. Generate seed for each party
+	seeds := make([]*Seed, p.n)
+	for i := 0; i < p.n; i++ {
+		seeds[i] = &Seed{
+			index: i,
+			epoch: p.epoch,
+			ski:   skShares[i],
+			exponents: seedExponents{
+				aOmega: aOmega[i],
+				eEta:   eEta[i],
+			},
+			coefficients: seedCoefficients{
+				aBeta:  aBeta[i],
+				eGamma: eGamma[i],
+			},
+			U: ownVOLEKeys(U, i),
+			V: ownOLEKeys(V, i),
+		}
+	}
+
+	return sk, seeds, nil
+}
+
+// EvalCombinedBBS evaluates a BBS seed for an n-out-of-n setting.
+func (p *PCG) EvalCombinedBBS(seed *Seed, rand []*poly.Polynomial, div *poly.Polynomial) (*BBSTupleGenerator, error) {
+	if p.tau != p.n {
+		return nil, fmt.Errorf("EvalCombinedBBS can only be used for an n-out-of-n setting")
+	}
+
+	if err := p.checkRandomPolynomials(rand); err != nil {
+		return nil, err
+	}
+
+	// 1. Generate the polynomials for a and e
+	u, v, err := p.constructBBSPolys(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Process VOLE (u) with seed / delta0 = ask
+	utilde, err := p.evalVOLEwithSeed(u, seed.ski, seed.U, seed.index)
+	if err != nil {
+		return nil, fmt.Errorf("step 2: failed to evaluate VOLE (utilde): %w", err)
+	}
+
+	// 3. Process OLE correlation (u, v) with seed / delta1 = ae
+	m, err := p.evalOLEwithSeed(u, v, seed.V, seed.index)
+	if err != nil {
+		return nil, fmt.Errorf("step 3: failed to evaluate OLE (m): %w", err)
+	}
+
+	// 4. Calculate final shares
+	ai, err := p.evalFinalShare(u, rand, div)
+	if err != nil {
+		return nil, fmt.Errorf("step 4: failed to evaluate final share ai: %w", err)
+	}
+	ei, err := p.evalFinalShare(v, rand, div)
+	if err != nil {
+		return nil, fmt.Errorf("step 4: failed to evaluate final share ei: %w", err)
+	}
+	delta0i, err := p.evalFinalShare(utilde, rand, div)
+	if err != nil {
+		return nil, fmt.Errorf("step 4: failed to evaluate final share delta0i: %w", err)
+	}
+
+	oprand, err := outerProductPoly(rand, rand, div)
+	if err != nil {
+		return nil, err
+	}
+	delta1i, err := p.evalFinalShare2D(m, oprand, div)
+	if err != nil {
+		return nil, fmt.Errorf("step 4: failed to evaluate final share delta1i: %w", err)
+	}
+
+	generator := NewBBSTupleGenerator(seed.ski, ai, ei, delta0i, delta1i)
+	generator.epoch = seed.epoch
+	return generator, nil
+}
+
+// EvalSeparateBBS evaluates a BBS seed for a tau-out-of-n setting.
+func (p *PCG) EvalSeparateBBS(seed *Seed, rand []*poly.Polynomial, div *poly.Polynomial) (*SeparateBBSTupleGenerator, error) {
+	if err := p.checkRandomPolynomials(rand); err != nil {
+		return nil, err
+	}
+
+	// 1. Generate the polynomials for a and e
+	u, v, err := p.constructBBSPolys(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Process VOLE (u) with seed / delta0 = ask, separately per counterparty and direction
+	utilde, err := p.evalVOLEwithSeedSeparate(seed.U, seed.index) // utilde[seedIndex] is nil!
+	if err != nil {
+		return nil, fmt.Errorf("step 2: failed to evaluate VOLE (utilde): %w", err)
+	}
+	usk := make([]*poly.Polynomial, p.c)
+	for r := 0; r < p.c; r++ {
+		usk[r] = u[r].DeepCopy()
+		usk[r].MulByConstant(seed.ski)
+	}
+
+	// 3. Process OLE correlation (u, v) with seed / delta1 = ae, separately per counterparty
+	m, uv, err := p.evalOLEwithSeedSeparate(u, v, seed.V, seed.index) // m[seedIndex] is nil!
+	if err != nil {
+		return nil, fmt.Errorf("step 3: failed to evaluate OLE (m): %w", err)
+	}
+
+	// 4. Calculate final shares
+	ai, err := p.evalFinalShare(u, rand, div)
+	if err != nil {
+		return nil, fmt.Errorf("step 4: failed to evaluate final share ai: %w", err)
+	}
+	ei, err := p.evalFinalShare(v, rand, div)
+	if err != nil {
+		return nil, fmt.Errorf("step 4: failed to evaluate final share ei: %w", err)
+	}
+
+	delta0i := make([][]*poly.Polynomial, p.n) // delta0i[seedIndex] is nil!
+	for j := 0; j < p.n; j++ {
+		if j != seed.index { // only for counterparties
+			delta0i[j] = make([]*poly.Polynomial, 2)
+			for _, direction := range []int{forwardDirection, backwardDirection} {
+				delta0i[j][direction], err = p.evalFinalShare(utilde[j][direction], rand, div)
+				if err != nil {
+					return nil, fmt.Errorf("step 4: failed to evaluate final share delta0i: %w", err)
+				}
+			}
+		}
+	}
+	uskEval, err := p.evalFinalShare(usk, rand, div)
+	if err != nil {
+		return nil, fmt.Errorf("step 4: failed to evaluate final share usk: %w", err)
+	}
+
+	oprand, err := outerProductPoly(rand, rand, div)
+	if err != nil {
+		return nil, err
+	}
+	delta1i := make([]*poly.Polynomial, p.n) // delta1i[seedIndex] is nil!
+	for j := 0; j < p.n; j++ {
+		if j != seed.index { // only for counterparties
+			delta1i[j], err = p.evalFinalShare2D(m[j], oprand, div)
+			if err != nil {
+				return nil, fmt.Errorf("step 4: failed to evaluate final share delta1i: %w", err)
+			}
+		}
+	}
+	uvEval, err := p.evalFinalShare2D(uv, oprand, div)
+	if err != nil {
+		return nil, fmt.Errorf("step 4: failed to evaluate final share uv: %w", err)
+	}
+
+	generator := NewSeparateBBSTupleGenerator(seed.index, p.tau, uskEval, uvEval, seed.ski, ai, ei, delta0i, delta1i)
+	generator.epoch = seed.epoch
+	return generator, nil
+}
+
+// checkRandomPolynomials checks that rand holds c polynomials of which the last one is 1.
+func (p *PCG) checkRandomPolynomials(rand []*poly.Polynomial) error {
+	if len(rand) != p.c {
+		return fmt.Errorf("rand must hold c=%d polynomials but contains %d", p.c, len(rand))
+	}
+	one, _ := poly.NewSparse([]*bls12381.Fr{bls12381.NewFr().One()}, []*big.Int{big.NewInt(0)}) // = 1
+	if !rand[p.c-1].Equal(one) {
+		return fmt.Errorf("rand must be a slice of polynomials with polynomial of the the last index rand[c-1] equal to 1")
+	}
+	return nil
+}
+
+// constructBBSPolys constructs the polynomials u for a and v for e from a BBS seed.
+func (p *PCG) constructBBSPolys(seed *Seed) ([]*poly.Polynomial, []*poly.Polynomial, error) {
+	u, err := p.constructPolys(seed.coefficients.aBeta, seed.exponents.aOmega)
+	if err != nil {
+		return nil, nil, fmt.Errorf("step 1: failed to generate polynomials for u from aBeta and aOmega: %w", err)
+	}
+	v, err := p.constructPolys(seed.coefficients.eGamma, seed.exponents.eEta)
+	if err != nil {
+		return nil, nil, fmt.Errorf("step 1: failed to generate polynomials for v from eGamma and eEta: %w", err)
+	}
+	return u, v, nil
+}
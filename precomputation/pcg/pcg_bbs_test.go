@@ -0,0 +1,125 @@
+package pcg
+
+import (
+	"testing"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
+)
+
+func TestPCGBBSCombinedEnd2End(t *testing.T) {
+	pcg, err := NewPCG(128, 10, 2, 2, 2, 4) // Small lpn parameters for testing.
+	assert.Nil(t, err)
+	pcg.SetEpoch(3)
+
+	sk, seeds, err := pcg.SeedGenWithSkBBS()
+	assert.Nil(t, err)
+	assert.Nil(t, seeds[0].C)
+	assert.Equal(t, uint64(3), seeds[0].GetEpoch())
+
+	randPolys, err := pcg.PickRandomPolynomials()
+	assert.Nil(t, err)
+
+	ring, err := pcg.GetRing(false)
+	assert.Nil(t, err)
+
+	eval0, err := pcg.EvalCombinedBBS(seeds[0], randPolys, ring.Div)
+	assert.Nil(t, err)
+	eval1, err := pcg.EvalCombinedBBS(seeds[1], randPolys, ring.Div)
+	assert.Nil(t, err)
+
+	root := ring.Roots[9]
+	tuple0 := eval0.GenBBSTuple(root)
+	tuple1 := eval1.GenBBSTuple(root)
+	assert.Equal(t, uint64(3), tuple0.Epoch)
+
+	skSum := bls12381.NewFr()
+	skSum.Add(tuple0.SkShare, tuple1.SkShare)
+	assert.Equal(t, 0, sk.Cmp(skSum))
+
+	a := bls12381.NewFr()
+	a.Add(tuple0.AShare, tuple1.AShare)
+	e := bls12381.NewFr()
+	e.Add(tuple0.EShare, tuple1.EShare)
+	delta := bls12381.NewFr()
+	delta.Add(tuple0.DeltaShare, tuple1.DeltaShare)
+
+	// Check if delta = a(sk + e) holds
+	skPe := bls12381.NewFr()
+	skPe.Add(sk, e)
+	expected := bls12381.NewFr()
+	expected.Mul(a, skPe)
+	assert.Equal(t, 0, delta.Cmp(expected))
+}
+
+func TestPCGBBSSeparateTau2N3(t *testing.T) {
+	pcg, err := NewPCG(128, 10, 3, 2, 2, 4) // Small lpn parameters for testing.
+	assert.Nil(t, err)
+
+	sk, seeds, err := pcg.SeedGenWithSkBBS()
+	assert.Nil(t, err)
+
+	randPolys, err := pcg.PickRandomPolynomials()
+	assert.Nil(t, err)
+
+	ring, err := pcg.GetRing(false)
+	assert.Nil(t, err)
+
+	generators := make([]*SeparateBBSTupleGenerator, len(seeds))
+	for i, seed := range seeds {
+		generators[i], err = pcg.EvalSeparateBBS(seed, randPolys, ring.Div)
+		assert.Nil(t, err)
+		assert.Equal(t, i, generators[i].OwnIndex())
+	}
+
+	root := ring.Roots[9]
+	for _, signerSet := range [][]int{{0, 1}, {0, 2}, {2, 1}, {0, 1, 2}} {
+		a := bls12381.NewFr().Zero()
+		e := bls12381.NewFr().Zero()
+		delta := bls12381.NewFr().Zero()
+		shamirIndices := make([]int, len(signerSet))
+		skShares := make([]*bls12381.Fr, len(signerSet))
+		for k, signer := range signerSet {
+			tuple := generators[signer].GenBBSTuple(root, signerSet)
+			a.Add(a, tuple.AShare)
+			e.Add(e, tuple.EShare)
+			delta.Add(delta, tuple.DeltaShare)
+			shamirIndices[k] = signer + 1
+			skShares[k] = tuple.SkShare
+		}
+
+		// The secret key is reconstructed from the Shamir shares of the signers.
+		lagrangeCoeff := helper.Get0LagrangeCoefficientSetFr(shamirIndices)
+		skSum := bls12381.NewFr().Zero()
+		for k := range signerSet {
+			tmp := bls12381.NewFr()
+			tmp.Mul(skShares[k], lagrangeCoeff[k])
+			skSum.Add(skSum, tmp)
+		}
+		assert.Equal(t, 0, sk.Cmp(skSum), "signer set %v", signerSet)
+
+		// Check if delta = a(sk + e) holds
+		skPe := bls12381.NewFr()
+		skPe.Add(sk, e)
+		expected := bls12381.NewFr()
+		expected.Mul(a, skPe)
+		assert.Equal(t, 0, delta.Cmp(expected), "signer set %v", signerSet)
+	}
+
+	assert.Nil(t, generators[0].GenBBSTuple(root, []int{1, 2}))
+}
+
+func TestBBSTupleSerialization(t *testing.T) {
+	elements := helper.GetRandomElements(4, 1)
+	tuple := NewBBSTuple(elements[0][0], elements[1][0], elements[2][0], elements[3][0])
+	tuple.Epoch = 5
+
+	data, err := tuple.Serialize()
+	assert.Nil(t, err)
+
+	decoded := &BBSTuple{}
+	assert.Nil(t, decoded.Deserialize(data))
+	assert.Equal(t, tuple, decoded)
+}
@@ -0,0 +1,123 @@
+package poly
+
+import (
+	"fmt"
+
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+// Dense represents a polynomial by the slice of all its coefficients up to a fixed length: index -> coefficient.
+// In contrast to Polynomial, the coefficients are stored by value and in Montgomery form, s.t. they can be multiplied
+// with RedMul without any conversion or allocation.
+type Dense struct {
+	Coefficients []bls12381.Fr // Coefficients of the polynomial in Montgomery form, the index is the exponent.
+}
+
+// NewDense creates the zero polynomial with n coefficients.
+func NewDense(n int) *Dense {
+	return &Dense{Coefficients: make([]bls12381.Fr, n)}
+}
+
+// NewDenseFromPolynomial converts the polynomial p into a dense polynomial with n coefficients.
+// The degree of p must be smaller than n.
+func NewDenseFromPolynomial(p *Polynomial, n int) (*Dense, error) {
+	d := NewDense(n)
+	for exp, coeff := range p.Coefficients {
+		if exp >= n {
+			return nil, fmt.Errorf("degree %d of polynomial exceeds %d coefficients", exp, n)
+		}
+		d.Coefficients[exp].Set(coeff)
+		d.Coefficients[exp].ToRed()
+	}
+	return d, nil
+}
+
+// Len returns the number of coefficients of the polynomial.
+func (d *Dense) Len() int {
+	return len(d.Coefficients)
+}
+
+// DeepCopy returns a copy of the polynomial the function is being called on.
+func (d *Dense) DeepCopy() *Dense {
+	c := NewDense(len(d.Coefficients))
+	copy(c.Coefficients, d.Coefficients)
+	return c
+}
+
+// Add adds q to the polynomial the function is being called on. If q has more coefficients, the polynomial is
+// extended accordingly.
+func (d *Dense) Add(q *Dense) {
+	if len(q.Coefficients) > len(d.Coefficients) {
+		d.Coefficients = append(d.Coefficients, make([]bls12381.Fr, len(q.Coefficients)-len(d.Coefficients))...)
+	}
+	for i := range q.Coefficients {
+		d.Coefficients[i].Add(&d.Coefficients[i], &q.Coefficients[i])
+	}
+}
+
+// MulByConstant multiplies the polynomial the function is being called on with the constant, which is given in the
+// usual form of bls12381.Fr and not in Montgomery form.
+func (d *Dense) MulByConstant(constant *bls12381.Fr) {
+	c := bls12381.NewFr().Set(constant)
+	c.ToRed()
+	for i := range d.Coefficients {
+		d.Coefficients[i].RedMul(&d.Coefficients[i], c)
+	}
+}
+
+// MulPointwise multiplies the polynomial the function is being called on coefficient-wise with q.
+// For polynomials in evaluation form (see NTT and NegacyclicRing) this is their product.
+func (d *Dense) MulPointwise(q *Dense) error {
+	if len(d.Coefficients) != len(q.Coefficients) {
+		return fmt.Errorf("polynomials have %d and %d coefficients", len(d.Coefficients), len(q.Coefficients))
+	}
+	for i := range d.Coefficients {
+		d.Coefficients[i].RedMul(&d.Coefficients[i], &q.Coefficients[i])
+	}
+	return nil
+}
+
+// ToPolynomial converts the dense polynomial into the map representation, leaving out zero coefficients.
+func (d *Dense) ToPolynomial() *Polynomial {
+	coefficients := make(map[int]*bls12381.Fr)
+	for i := range d.Coefficients {
+		if !d.Coefficients[i].IsZero() {
+			coeff := bls12381.NewFr().Set(&d.Coefficients[i])
+			coeff.FromRed()
+			coefficients[i] = coeff
+		}
+	}
+	return &Polynomial{Coefficients: coefficients}
+}
+
+// MulDense returns the product of two dense polynomials, computed with the NTT in O(nlogn).
+func MulDense(a, b *Dense) (*Dense, error) {
+	if a.Len() == 0 || b.Len() == 0 {
+		return NewDense(0), nil
+	}
+	resultLen := a.Len() + b.Len() - 1
+	ntt, err := NewNTT(nextPowerOf2(resultLen))
+	if err != nil {
+		return nil, err
+	}
+
+	x := NewDense(ntt.n)
+	copy(x.Coefficients, a.Coefficients)
+	y := NewDense(ntt.n)
+	copy(y.Coefficients, b.Coefficients)
+	if err := ntt.Forward(x.Coefficients); err != nil {
+		return nil, err
+	}
+	if err := ntt.Forward(y.Coefficients); err != nil {
+		return nil, err
+	}
+	if err := x.MulPointwise(y); err != nil {
+		return nil, err
+	}
+	if err := ntt.Inverse(x.Coefficients); err != nil {
+		return nil, err
+	}
+
+	x.Coefficients = x.Coefficients[:resultLen]
+	return x, nil
+}
@@ -0,0 +1,34 @@
+package poly
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
+)
+
+// FuzzDeserialize checks that deserializing never panics and that accepted polynomials survive a round trip.
+func FuzzDeserialize(f *testing.F) {
+	p, err := NewRandomPolynomial(rand.New(rand.NewSource(1)), 4)
+	require.NoError(f, err)
+	data, err := p.Serialize()
+	require.NoError(f, err)
+	f.Add(data)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		p := NewEmpty()
+		if err := p.Deserialize(data); err != nil {
+			require.ErrorIs(t, err, helper.ErrInvalidEncoding)
+			return
+		}
+		encoded, err := p.Serialize()
+		require.NoError(t, err)
+		require.Len(t, encoded, len(data))
+
+		q := NewEmpty()
+		require.NoError(t, q.Deserialize(encoded))
+		require.True(t, p.Equal(q))
+	})
+}
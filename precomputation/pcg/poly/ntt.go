@@ -0,0 +1,250 @@
+package poly
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+	"sync"
+
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+// frTwoAdicity is the largest k s.t. 2^k divides the order of the multiplicative group of Fr, i.e., 2^k is the
+// largest size of an NTT over Fr.
+const frTwoAdicity = 32
+
+// NTTs and negacyclic rings only depend on their size, hence they are precomputed once per size.
+var (
+	nttCache        sync.Map // int -> *NTT
+	negacyclicCache sync.Map // int -> *NegacyclicRing
+)
+
+// NTT is the number theoretic transform of size n over Fr, i.e., the FFT with the 2^n-th roots of unity in Fr.
+// It works in place on the Montgomery form of the coefficients, see Dense.
+type NTT struct {
+	n        int
+	roots    []bls12381.Fr // roots[i] = w^i for the n-th root of unity w and i < n/2, in Montgomery form.
+	invRoots []bls12381.Fr // invRoots[i] = w^-i for i < n/2, in Montgomery form.
+	nInv     bls12381.Fr   // n^-1 in Montgomery form.
+}
+
+// NewNTT returns the NTT of size n, which must be a power of two of at most 2^32.
+func NewNTT(n int) (*NTT, error) {
+	if ntt, ok := nttCache.Load(n); ok {
+		return ntt.(*NTT), nil
+	}
+	if n <= 0 || n&(n-1) != 0 || bits.TrailingZeros(uint(n)) > frTwoAdicity {
+		return nil, fmt.Errorf("NTT size %d is not a power of two of at most 2^%d", n, frTwoAdicity)
+	}
+
+	w := rootOfUnity(n)
+	wInv := new(bls12381.Fr)
+	wInv.RedInverse(w)
+	ntt := &NTT{
+		n:        n,
+		roots:    powers(w, n/2),
+		invRoots: powers(wInv, n/2),
+	}
+	ntt.nInv.RedFromBytes(big.NewInt(int64(n)).Bytes())
+	ntt.nInv.RedInverse(&ntt.nInv)
+
+	actual, _ := nttCache.LoadOrStore(n, ntt)
+	return actual.(*NTT), nil
+}
+
+// Size returns the size n of the NTT.
+func (f *NTT) Size() int {
+	return f.n
+}
+
+// Forward replaces the coefficients in a by the evaluations of the polynomial at w^0, ..., w^(n-1).
+func (f *NTT) Forward(a []bls12381.Fr) error {
+	if len(a) != f.n {
+		return fmt.Errorf("%d coefficients given for NTT of size %d", len(a), f.n)
+	}
+	f.transform(a, f.roots)
+	return nil
+}
+
+// Inverse replaces the evaluations at w^0, ..., w^(n-1) in a by the coefficients of the polynomial.
+func (f *NTT) Inverse(a []bls12381.Fr) error {
+	if len(a) != f.n {
+		return fmt.Errorf("%d evaluations given for NTT of size %d", len(a), f.n)
+	}
+	f.transform(a, f.invRoots)
+	for i := range a {
+		a[i].RedMul(&a[i], &f.nInv)
+	}
+	return nil
+}
+
+// transform is the iterative radix-2 Cooley-Tukey FFT with the given twiddle factors. It works in place by first
+// permuting a into bit-reversed order.
+func (f *NTT) transform(a []bls12381.Fr, twiddles []bls12381.Fr) {
+	bitReverse(a)
+	t := new(bls12381.Fr)
+	for size := 2; size <= f.n; size <<= 1 {
+		half := size >> 1
+		step := f.n / size
+		for start := 0; start < f.n; start += size {
+			for j := 0; j < half; j++ {
+				u, v := &a[start+j], &a[start+j+half]
+				t.RedMul(v, &twiddles[j*step])
+				v.Sub(u, t)
+				u.Add(u, t)
+			}
+		}
+	}
+}
+
+// NegacyclicRing is the ring Fr[x]/(x^n + 1) for a power of two n, in which the product of two polynomials is
+// computed with a single NTT of size n by weighting the coefficients with the powers of a 2n-th root of unity psi.
+type NegacyclicRing struct {
+	ntt    *NTT
+	psi    []bls12381.Fr // psi[i] = psi^i in Montgomery form.
+	psiInv []bls12381.Fr // psiInv[i] = psi^-i * n^-1 in Montgomery form.
+}
+
+// NewNegacyclicRing returns the ring Fr[x]/(x^n + 1), n must be a power of two of at most 2^31.
+func NewNegacyclicRing(n int) (*NegacyclicRing, error) {
+	if ring, ok := negacyclicCache.Load(n); ok {
+		return ring.(*NegacyclicRing), nil
+	}
+	if n <= 0 || n&(n-1) != 0 || bits.TrailingZeros(uint(n)) >= frTwoAdicity {
+		return nil, fmt.Errorf("ring degree %d is not a power of two of at most 2^%d", n, frTwoAdicity-1)
+	}
+	ntt, err := NewNTT(n)
+	if err != nil {
+		return nil, err
+	}
+
+	psi := rootOfUnity(2 * n)
+	psiInv := new(bls12381.Fr)
+	psiInv.RedInverse(psi)
+	ring := &NegacyclicRing{
+		ntt:    ntt,
+		psi:    powers(psi, n),
+		psiInv: powers(psiInv, n),
+	}
+	for i := range ring.psiInv {
+		ring.psiInv[i].RedMul(&ring.psiInv[i], &ntt.nInv)
+	}
+
+	actual, _ := negacyclicCache.LoadOrStore(n, ring)
+	return actual.(*NegacyclicRing), nil
+}
+
+// NewNegacyclicRingFromDivisor returns the ring Fr[x]/(div), where div must be x^n + 1 for a power of two n,
+// e.g., a polynomial of NewCyclotomicPolynomial.
+func NewNegacyclicRingFromDivisor(div *Polynomial) (*NegacyclicRing, error) {
+	n, ok := cyclotomicDegree(div)
+	if !ok {
+		return nil, fmt.Errorf("divisor is not of the form x^n + 1")
+	}
+	return NewNegacyclicRing(n)
+}
+
+// Degree returns the degree n of the ring Fr[x]/(x^n + 1).
+func (r *NegacyclicRing) Degree() int {
+	return r.ntt.n
+}
+
+// Reduce returns p mod x^n + 1 as dense polynomial with n coefficients.
+func (r *NegacyclicRing) Reduce(p *Polynomial) *Dense {
+	n := r.ntt.n
+	d := NewDense(n)
+	coeff := new(bls12381.Fr)
+	for exp, c := range p.Coefficients {
+		coeff.Set(c)
+		coeff.ToRed()
+		// x^exp = (-1)^(exp/n) * x^(exp mod n) since x^n = -1
+		if (exp/n)%2 == 0 {
+			d.Coefficients[exp%n].Add(&d.Coefficients[exp%n], coeff)
+		} else {
+			d.Coefficients[exp%n].Sub(&d.Coefficients[exp%n], coeff)
+		}
+	}
+	return d
+}
+
+// Forward transforms the element a of the ring in place into evaluation form, in which the product of two ring
+// elements is their pointwise product (see Dense.MulPointwise) and the sum their sum.
+func (r *NegacyclicRing) Forward(a *Dense) error {
+	if a.Len() != r.ntt.n {
+		return fmt.Errorf("%d coefficients given for ring of degree %d", a.Len(), r.ntt.n)
+	}
+	for i := range a.Coefficients {
+		a.Coefficients[i].RedMul(&a.Coefficients[i], &r.psi[i])
+	}
+	r.ntt.transform(a.Coefficients, r.ntt.roots)
+	return nil
+}
+
+// Inverse transforms the element a of the ring in place from evaluation form back into its coefficients.
+func (r *NegacyclicRing) Inverse(a *Dense) error {
+	if a.Len() != r.ntt.n {
+		return fmt.Errorf("%d evaluations given for ring of degree %d", a.Len(), r.ntt.n)
+	}
+	r.ntt.transform(a.Coefficients, r.ntt.invRoots)
+	for i := range a.Coefficients {
+		a.Coefficients[i].RedMul(&a.Coefficients[i], &r.psiInv[i])
+	}
+	return nil
+}
+
+// Mul returns the product a * b mod x^n + 1 of two elements of the ring without modifying them.
+func (r *NegacyclicRing) Mul(a, b *Dense) (*Dense, error) {
+	x, y := a.DeepCopy(), b.DeepCopy()
+	if err := r.Forward(x); err != nil {
+		return nil, err
+	}
+	if err := r.Forward(y); err != nil {
+		return nil, err
+	}
+	if err := x.MulPointwise(y); err != nil {
+		return nil, err
+	}
+	if err := r.Inverse(x); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// rootOfUnity returns a primitive n-th root of unity in Fr in Montgomery form for a power of two n <= 2^32.
+func rootOfUnity(n int) *bls12381.Fr {
+	modulus, _ := new(big.Int).SetString(FrModulus, 16)
+	generator, _ := new(big.Int).SetString(FrPrimitiveRootOfUnity, 10)
+
+	exp := new(big.Int).Sub(modulus, ONE)
+	exp.Div(exp, big.NewInt(int64(n)))
+	root := new(big.Int).Exp(generator, exp, modulus)
+	return bls12381.NewFr().RedFromBytes(root.Bytes())
+}
+
+// powers returns x^0, ..., x^(n-1) for x in Montgomery form.
+func powers(x *bls12381.Fr, n int) []bls12381.Fr {
+	res := make([]bls12381.Fr, n)
+	if n == 0 {
+		return res
+	}
+	res[0].RedOne()
+	for i := 1; i < n; i++ {
+		res[i].RedMul(&res[i-1], x)
+	}
+	return res
+}
+
+// bitReverse permutes a, whose length is a power of two, into bit-reversed order.
+func bitReverse(a []bls12381.Fr) {
+	n := len(a)
+	if n <= 2 {
+		return
+	}
+	shift := bits.UintSize - bits.TrailingZeros(uint(n))
+	for i := range a {
+		j := int(bits.Reverse(uint(i)) >> shift)
+		if i < j {
+			a[i], a[j] = a[j], a[i]
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package poly
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNTTForwardInverse(t *testing.T) {
+	for _, n := range []int{1, 2, 4, 64, 1024} {
+		ntt, err := NewNTT(n)
+		require.NoError(t, err)
+
+		p := NewFromFr(randomFrSlice(n))
+		d, err := NewDenseFromPolynomial(p, n)
+		require.NoError(t, err)
+
+		// The NTT evaluates the polynomial at the powers of the root of unity.
+		evals := d.DeepCopy()
+		require.NoError(t, ntt.Forward(evals.Coefficients))
+		w := rootOfUnity(n)
+		w.FromRed()
+		x := bls12381.NewFr().One()
+		for i := 0; i < n; i++ {
+			expected := p.Evaluate(x)
+			expected.ToRed()
+			assert.True(t, expected.Equal(&evals.Coefficients[i]), "evaluation %d of size %d", i, n)
+			x.Mul(x, w)
+		}
+
+		require.NoError(t, ntt.Inverse(evals.Coefficients))
+		assert.True(t, p.Equal(evals.ToPolynomial()))
+	}
+}
+
+func TestNTTInvalidSize(t *testing.T) {
+	for _, n := range []int{0, -4, 3, 1 << 33} {
+		_, err := NewNTT(n)
+		assert.Error(t, err)
+	}
+	ntt, err := NewNTT(8)
+	require.NoError(t, err)
+	assert.Error(t, ntt.Forward(make([]bls12381.Fr, 4)))
+	assert.Error(t, ntt.Inverse(make([]bls12381.Fr, 16)))
+}
+
+func TestMulDenseEqual(t *testing.T) {
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	a, err := NewRandomPolynomial(rng, 300)
+	require.NoError(t, err)
+	b := randomSparsePoly(20, 700)
+
+	expected, err := Mul(a, b)
+	require.NoError(t, err)
+
+	denseA, err := NewDenseFromPolynomial(a, 300)
+	require.NoError(t, err)
+	denseB, err := NewDenseFromPolynomial(b, 701)
+	require.NoError(t, err)
+	product, err := MulDense(denseA, denseB)
+	require.NoError(t, err)
+	assert.Equal(t, 1000, product.Len())
+	assert.True(t, expected.Equal(product.ToPolynomial()))
+
+	_, err = NewDenseFromPolynomial(b, 700)
+	assert.Error(t, err)
+}
+
+func TestNegacyclicMul(t *testing.T) {
+	for _, n := range []int{1, 2, 16, 256} {
+		div, err := NewCyclotomicPolynomial(big.NewInt(int64(2 * n)))
+		require.NoError(t, err)
+		ring, err := NewNegacyclicRingFromDivisor(div)
+		require.NoError(t, err)
+		assert.Equal(t, n, ring.Degree())
+
+		// Polynomials of a larger degree than the ring are reduced first.
+		a := NewFromFr(randomFrSlice(2*n + 3))
+		b := randomSparsePoly(2, 2*n+1)
+		product, err := Mul(a, b)
+		require.NoError(t, err)
+		expected, err := product.Mod(div)
+		require.NoError(t, err)
+
+		reducedA, err := a.Mod(div)
+		require.NoError(t, err)
+		assert.True(t, reducedA.Equal(ring.Reduce(a).ToPolynomial()))
+
+		result, err := ring.Mul(ring.Reduce(a), ring.Reduce(b))
+		require.NoError(t, err)
+		assert.True(t, expected.Equal(result.ToPolynomial()), "ring degree %d", n)
+	}
+}
+
+func TestNegacyclicSumOfProducts(t *testing.T) {
+	n := 64
+	div, err := NewCyclotomicPolynomial(big.NewInt(int64(2 * n)))
+	require.NoError(t, err)
+	ring, err := NewNegacyclicRing(n)
+	require.NoError(t, err)
+
+	// Sums and products in evaluation form need only a single inverse transform.
+	expected := NewEmpty()
+	sum := NewDense(n)
+	for i := 0; i < 3; i++ {
+		a := NewFromFr(randomFrSlice(n))
+		b := NewFromFr(randomFrSlice(n))
+		product, err := Mul(a, b)
+		require.NoError(t, err)
+		expected.Add(product)
+
+		x, y := ring.Reduce(a), ring.Reduce(b)
+		require.NoError(t, ring.Forward(x))
+		require.NoError(t, ring.Forward(y))
+		require.NoError(t, x.MulPointwise(y))
+		sum.Add(x)
+	}
+	require.NoError(t, ring.Inverse(sum))
+
+	expected, err = expected.Mod(div)
+	require.NoError(t, err)
+	assert.True(t, expected.Equal(sum.ToPolynomial()))
+}
+
+func TestNegacyclicRingInvalidDivisor(t *testing.T) {
+	for _, values := range [][]int64{{1, 0, 0, 1}, {1, 0, 0, 0, 2}, {0, 0, 0, 0, 1}, {1, 1, 0, 0, 1}} {
+		bigValues := make([]*big.Int, len(values))
+		for i, v := range values {
+			bigValues[i] = big.NewInt(v)
+		}
+		_, err := NewNegacyclicRingFromDivisor(NewFromBig(bigValues))
+		assert.Error(t, err, "divisor %v", values)
+	}
+	_, err := NewNegacyclicRing(12)
+	assert.Error(t, err)
+
+	ring, err := NewNegacyclicRing(8)
+	require.NoError(t, err)
+	assert.Error(t, ring.Forward(NewDense(16)))
+	assert.Error(t, ring.Inverse(NewDense(4)))
+}
+
+func BenchmarkNegacyclicMulN10(b *testing.B) { benchmarkNegacyclicMul(b, 1024) }
+func BenchmarkNegacyclicMulN12(b *testing.B) { benchmarkNegacyclicMul(b, 4096) }
+func BenchmarkNegacyclicMulN14(b *testing.B) { benchmarkNegacyclicMul(b, 16384) }
+func BenchmarkNegacyclicMulN16(b *testing.B) { benchmarkNegacyclicMul(b, 65536) }
+
+func benchmarkNegacyclicMul(b *testing.B, n int) {
+	ring, err := NewNegacyclicRing(n)
+	if err != nil {
+		b.Fatal(err)
+	}
+	x := ring.Reduce(NewFromFr(randomFrSlice(n)))
+	y := ring.Reduce(NewFromFr(randomFrSlice(n)))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ring.Mul(x, y); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
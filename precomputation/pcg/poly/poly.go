@@ -5,7 +5,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	bls12381 "github.com/kilic/bls12-381"
-	"math"
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
 	"math/big"
 	"math/rand"
 	"runtime"
@@ -37,26 +37,30 @@ func (p *Polynomial) Serialize() ([]byte, error) {
 }
 
 // Deserialize deserializes the byte representation of a polynomial and sets the polynomial the function is being called on.
+// The data must consist of entries of a non-negative exponent and a canonically encoded coefficient, where every
+// exponent occurs at most once.
 func (p *Polynomial) Deserialize(data []byte) error {
-	buffer := bytes.NewBuffer(data)
-	var exponent int32
-	newPolynomial := &Polynomial{Coefficients: make(map[int]*bls12381.Fr)}
+	const entryLength = 4 + helper.LenBytesFr
+	if len(data)%entryLength != 0 {
+		return fmt.Errorf("%w: polynomial length %d is not a multiple of %d", helper.ErrInvalidEncoding, len(data), entryLength)
+	}
 
-	for buffer.Len() > 0 {
+	newPolynomial := &Polynomial{Coefficients: make(map[int]*bls12381.Fr, len(data)/entryLength)}
+	for offset := 0; offset < len(data); offset += entryLength {
 		// Read the exponent
-		err := binary.Read(buffer, binary.BigEndian, &exponent)
-		if err != nil {
-			return err
+		exponent := int32(binary.BigEndian.Uint32(data[offset : offset+4]))
+		if exponent < 0 {
+			return fmt.Errorf("%w: negative exponent %d", helper.ErrInvalidEncoding, exponent)
+		}
+		if _, ok := newPolynomial.Coefficients[int(exponent)]; ok {
+			return fmt.Errorf("%w: duplicate exponent %d", helper.ErrInvalidEncoding, exponent)
 		}
 
 		// Read the coefficient
-		coeffBytes := make([]byte, 32) // size of bls12381.Fr in bytes is 32
-		_, err = buffer.Read(coeffBytes)
+		coefficient, err := helper.FrFromCanonicalBytes(data[offset+4 : offset+entryLength])
 		if err != nil {
-			return err
+			return fmt.Errorf("coefficient of exponent %d: %w", exponent, err)
 		}
-		coefficient := bls12381.NewFr()
-		coefficient.FromBytes(coeffBytes)
 
 		newPolynomial.Coefficients[int(exponent)] = coefficient
 	}
@@ -294,11 +298,15 @@ func (p *Polynomial) MulByConstant(constant *bls12381.Fr) {
 }
 
 // Mul multiplies two polynomials and stores the result in the polynomial the function is being called on.
-// The function will choose the most efficient method of multiplication depending on the structure of the polynomials.
+// The function will choose the most efficient method of multiplication depending on the structure of the polynomials:
+// mulSparse if the product has fewer terms than its length (e.g., for two t-sparse polynomials), mulSparseDense if
+// multiplying all pairs of Coefficients is still cheaper than the NTT (e.g., for a t-sparse and a dense polynomial),
+// and mulFFT otherwise.
 func (p *Polynomial) Mul(q *Polynomial) error {
 	maxComplexity := len(p.Coefficients) * len(q.Coefficients)
-	if maxComplexity < 1024 {
-		return p.mulNaive(q)
+	if maxComplexity == 0 {
+		p.Coefficients = make(map[int]*bls12381.Fr)
+		return nil
 	}
 
 	// Calculate the degrees of the polynomials
@@ -312,23 +320,28 @@ func (p *Polynomial) Mul(q *Polynomial) error {
 	}
 
 	// Calculate the size for FFT, which is the next power of 2 greater than degP + degQ
-	nFFT := nextPowerOf2(degP + degQ + 1)
+	resultLen := degP + degQ + 1
+	nFFT := nextPowerOf2(resultLen)
 
-	// Compare the product of non-zero coefficients with nFFT * log2(nFFT)
-	if maxComplexity > nFFT*log2(nFFT) {
+	// Compare the product of non-zero coefficients with nFFT * log2(nFFT) and the length of the product
+	switch {
+	case maxComplexity > nFFT*log2(nFFT):
 		return p.mulFFT(q)
-	} else {
-		return p.mulNaive(q)
+	case maxComplexity < resultLen:
+		return p.mulSparse(q)
+	default:
+		return p.mulSparseDense(q, resultLen)
 	}
 }
 
 // Mul returns the product of two polynomials without modifying the original polynomials.
 func Mul(p, q *Polynomial) (*Polynomial, error) {
-	copyP := p.DeepCopy() // Ensure that the original polynomials are not modified
-	copyQ := q.DeepCopy()
+	// The multiplication replaces the Coefficients of the result instead of modifying them, hence there is no need to
+	// copy them.
+	result := &Polynomial{Coefficients: p.Coefficients}
 
-	err := copyP.Mul(copyQ)
-	return copyP, err
+	err := result.Mul(q)
+	return result, err
 }
 
 // Add returns the sum of two polynomials without modifying the original polynomials.
@@ -441,10 +454,47 @@ func (p *Polynomial) evaluateParallel(x *bls12381.Fr) *bls12381.Fr {
 }
 
 // Mod returns the remainder of the polynomial divided by another polynomial.
+// If the divisor is of the form x^m + 1, the remainder is computed with ModCyclotomic.
 func (p *Polynomial) Mod(divisor *Polynomial) (*Polynomial, error) {
+	if m, ok := cyclotomicDegree(divisor); ok {
+		return p.ModCyclotomic(m)
+	}
 	return p.modNaive(divisor)
 }
 
+// ModCyclotomic returns the remainder of the polynomial divided by x^m + 1, e.g., a polynomial of
+// NewCyclotomicPolynomial, in O(t) for t Coefficients.
+// Since x^m = -1, the coefficient of x^(k*m + i) is added to the one of x^i with the sign (-1)^k.
+func (p *Polynomial) ModCyclotomic(m int) (*Polynomial, error) {
+	if m <= 0 {
+		return nil, fmt.Errorf("degree of divisor must be greater than zero")
+	}
+
+	remainder := &Polynomial{Coefficients: make(map[int]*bls12381.Fr, min(len(p.Coefficients), m))}
+	for exp, coeff := range p.Coefficients {
+		i := exp % m
+		negate := (exp/m)%2 == 1
+		if val, ok := remainder.Coefficients[i]; ok {
+			if negate {
+				val.Sub(val, coeff)
+			} else {
+				val.Add(val, coeff)
+			}
+			if val.IsZero() {
+				delete(remainder.Coefficients, i)
+			}
+		} else {
+			val = bls12381.NewFr().Set(coeff) // DeepCopy coefficient
+			if negate {
+				val.Neg(val)
+			}
+			remainder.Coefficients[i] = val
+		}
+	}
+
+	return remainder, nil
+}
+
 // modNaive returns the remainder of the polynomial divided by another polynomial.
 // This is the naive method of modulo using polynomial division.
 func (p *Polynomial) modNaive(divisor *Polynomial) (*Polynomial, error) {
@@ -515,43 +565,86 @@ func (p *Polynomial) mulNaive(q *Polynomial) error {
 	return nil
 }
 
-// mulFFT multiplies two polynomials using the FFT  in O(nlogn).
+// mulSparse multiplies two sparse polynomials in O(t_p * t_q) for t_p and t_q Coefficients.
+// It is faster than mulNaive, since the Coefficients of q are converted into Montgomery form once, s.t. every product
+// takes a single Montgomery multiplication.
+func (p *Polynomial) mulSparse(q *Polynomial) error {
+	expsQ, coeffsQ := montgomeryTerms(q)
+
+	// The Coefficients of the result are allocated at once, there are at most t_p * t_q of them.
+	resultCoeffs := make(map[int]*bls12381.Fr, len(p.Coefficients)*len(expsQ))
+	values := make([]bls12381.Fr, len(p.Coefficients)*len(expsQ))
+	used := 0
+	product := bls12381.NewFr()
+	for expP, coeffP := range p.Coefficients {
+		for k, expQ := range expsQ {
+			product.RedMul(coeffP, &coeffsQ[k]) // coeffP * (coeffQ * R) * R^-1 = coeffP * coeffQ
+			if val, ok := resultCoeffs[expP+expQ]; ok {
+				val.Add(val, product)
+			} else {
+				values[used].Set(product)
+				resultCoeffs[expP+expQ] = &values[used]
+				used++
+			}
+		}
+	}
+
+	for exp, coeff := range resultCoeffs {
+		if coeff.IsZero() {
+			delete(resultCoeffs, exp)
+		}
+	}
+	p.Coefficients = resultCoeffs
+	return nil
+}
+
+// mulSparseDense multiplies two polynomials by accumulating the products of all pairs of Coefficients in a dense
+// slice of length resultLen, in O(t_p * t_q + resultLen). This avoids the map operations of mulSparse if the product is
+// dense, e.g., for a t-sparse and a dense polynomial.
+func (p *Polynomial) mulSparseDense(q *Polynomial, resultLen int) error {
+	expsP, coeffsP := montgomeryTerms(p)
+	expsQ, coeffsQ := montgomeryTerms(q)
+
+	result := NewDense(resultLen)
+	product := bls12381.NewFr()
+	for i, expP := range expsP {
+		for k, expQ := range expsQ {
+			product.RedMul(&coeffsP[i], &coeffsQ[k])
+			result.Coefficients[expP+expQ].Add(&result.Coefficients[expP+expQ], product)
+		}
+	}
+
+	p.Coefficients = result.ToPolynomial().Coefficients
+	return nil
+}
+
+// mulFFT multiplies two polynomials using the NTT in O(nlogn).
 // note that this can be faster for polynomials with a very large number of Coefficients.
 func (p *Polynomial) mulFFT(q *Polynomial) error {
-	coeffsP := polyAsCoefficientsBigInt(p)
-	coeffsQ := polyAsCoefficientsBigInt(q)
-	coeffsP, coeffsQ = extendSliceWithZeros(coeffsP, coeffsQ)
-
-	n := math.Ceil(math.Log2(float64(len(coeffsP))))
-	fft, err := NewBLS12381FFT(int(n))
+	degP, err := p.Degree()
 	if err != nil {
 		return err
 	}
-	resultBig, err := fft.MulPolysFFT(coeffsP, coeffsQ)
+	degQ, err := q.Degree()
+	if err != nil {
+		return err
+	}
+	denseP, err := NewDenseFromPolynomial(p, degP+1)
+	if err != nil {
+		return err
+	}
+	denseQ, err := NewDenseFromPolynomial(q, degQ+1)
 	if err != nil {
 		return err
 	}
 
-	p.Coefficients = NewFromBig(resultBig).Coefficients
-	return nil
-}
-
-// polyAsCoefficientsBigInt returns the Coefficients of the polynomial in the form of a slice.
-// The index of the element will be its exponent.
-// Zero Coefficients are also represented s.t. the full polynomial is represented by the slice.
-func polyAsCoefficientsBigInt(p *Polynomial) []*big.Int {
-	degree, _ := p.Degree()
-	coefficients := make([]*big.Int, degree+1)
-	for i := 0; i < degree+1; i++ {
-		val, ok := p.Coefficients[i]
-		if ok {
-			coefficients[i] = val.ToBig()
-		} else {
-			coefficients[i] = big.NewInt(0)
-		}
+	result, err := MulDense(denseP, denseQ)
+	if err != nil {
+		return err
 	}
 
-	return coefficients
+	p.Coefficients = result.ToPolynomial().Coefficients
+	return nil
 }
 
 // parallelEvaluateChunk evaluates a chunk of the polynomial using Horner's method.
@@ -581,27 +674,6 @@ func precomputeXPowers(x *bls12381.Fr, chunkSize, numChunks int) []*bls12381.Fr
 	return xPowers
 }
 
-// extendSliceWithZeros extends a slice of *big.Int to match the length of another slice.
-// It appends zeros to the end of the shorter slice.
-func extendSliceWithZeros(a, b []*big.Int) ([]*big.Int, []*big.Int) {
-	lenA, lenB := len(a), len(b)
-
-	// Determine which slice is shorter
-	if lenA < lenB {
-		// Extend slice a
-		for i := lenA; i < lenB; i++ {
-			a = append(a, big.NewInt(0))
-		}
-	} else if lenB < lenA {
-		// Extend slice b
-		for i := lenB; i < lenA; i++ {
-			b = append(b, big.NewInt(0))
-		}
-	}
-
-	return a, b
-}
-
 // hasDuplicates checks if there are duplicates in a slice of *big.Int.
 func hasDuplicates(slice []*big.Int) bool {
 	seen := make(map[string]struct{})
@@ -617,6 +689,35 @@ func hasDuplicates(slice []*big.Int) bool {
 	return false
 }
 
+// montgomeryTerms returns the exponents of the non-zero Coefficients of p together with the Coefficients in
+// Montgomery form.
+func montgomeryTerms(p *Polynomial) ([]int, []bls12381.Fr) {
+	exps := make([]int, 0, len(p.Coefficients))
+	coeffs := make([]bls12381.Fr, 0, len(p.Coefficients))
+	for exp, coeff := range p.Coefficients {
+		if coeff.IsZero() {
+			continue
+		}
+		exps = append(exps, exp)
+		coeffs = append(coeffs, *coeff)
+		coeffs[len(coeffs)-1].ToRed()
+	}
+	return exps, coeffs
+}
+
+// cyclotomicDegree returns m if the polynomial is of the form x^m + 1 for m > 0.
+func cyclotomicDegree(p *Polynomial) (int, bool) {
+	m, found := maxKey(p.Coefficients)
+	if !found || m == 0 || len(p.Coefficients) != 2 {
+		return 0, false
+	}
+	constant, ok := p.Coefficients[0]
+	if !ok || !constant.IsOne() || !p.Coefficients[m].IsOne() {
+		return 0, false
+	}
+	return m, true
+}
+
 // maxKey returns the maximum key in a map of int -> *bls12381.Fr.
 func maxKey(m map[int]*bls12381.Fr) (int, bool) {
 	var maxEx int
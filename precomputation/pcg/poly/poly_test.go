@@ -411,6 +411,75 @@ func TestModLarge(t *testing.T) {
 	assert.True(t, deg < degB)
 }
 
+func TestModCyclotomic(t *testing.T) {
+	for _, m := range []int{1, 4, 64, 100} {
+		aPoly := NewFromFr(randomFrSlice(3*m + 2))
+		one := bls12381.NewFr().One()
+		divisor, err := NewSparse([]*bls12381.Fr{one, one}, []*big.Int{big.NewInt(0), big.NewInt(int64(m))}) // x^m + 1
+		assert.Nil(t, err)
+
+		expected, err := aPoly.modNaive(divisor)
+		assert.Nil(t, err)
+		remainder, err := aPoly.ModCyclotomic(m)
+		assert.Nil(t, err)
+		assert.True(t, expected.Equal(remainder), "m = %d", m)
+
+		// Mod chooses ModCyclotomic for divisors of the form x^m + 1.
+		remainder, err = aPoly.Mod(divisor)
+		assert.Nil(t, err)
+		assert.True(t, expected.Equal(remainder), "m = %d", m)
+	}
+
+	// The coefficients of x^1 and x^5 cancel out modulo x^4 + 1.
+	one := bls12381.NewFr().One()
+	aPoly, err := NewSparse([]*bls12381.Fr{one, one, one}, []*big.Int{big.NewInt(1), big.NewInt(5), big.NewInt(2)})
+	assert.Nil(t, err)
+	remainder, err := aPoly.ModCyclotomic(4)
+	assert.Nil(t, err)
+	expected, err := NewSparse([]*bls12381.Fr{one}, []*big.Int{big.NewInt(2)})
+	assert.Nil(t, err)
+	assert.True(t, expected.Equal(remainder))
+
+	remainder, err = NewEmpty().ModCyclotomic(4)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, remainder.AmountOfCoefficients())
+	_, err = aPoly.ModCyclotomic(0)
+	assert.NotNil(t, err)
+}
+
+func TestMulSparseKernelsEqual(t *testing.T) {
+	sparseA := randomSparsePoly(16, 1<<12)
+	sparseB := randomSparsePoly(20, 1<<11)
+	dense := NewFromFr(randomFrSlice(1 << 10))
+
+	for _, operands := range [][2]*Polynomial{{sparseA, sparseB}, {sparseA, dense}, {dense, sparseB}} {
+		expected := operands[0].DeepCopy()
+		assert.Nil(t, expected.mulNaive(operands[1]))
+
+		result := operands[0].DeepCopy()
+		assert.Nil(t, result.mulSparse(operands[1]))
+		assert.True(t, expected.Equal(result))
+
+		degA, _ := operands[0].Degree()
+		degB, _ := operands[1].Degree()
+		result = operands[0].DeepCopy()
+		assert.Nil(t, result.mulSparseDense(operands[1], degA+degB+1))
+		assert.True(t, expected.Equal(result))
+
+		// Mul chooses one of the kernels automatically and does not modify its operands.
+		copyA, copyB := operands[0].DeepCopy(), operands[1].DeepCopy()
+		result, err := Mul(operands[0], operands[1])
+		assert.Nil(t, err)
+		assert.True(t, expected.Equal(result))
+		assert.True(t, copyA.Equal(operands[0]))
+		assert.True(t, copyB.Equal(operands[1]))
+	}
+
+	result, err := Mul(sparseA, NewEmpty())
+	assert.Nil(t, err)
+	assert.Equal(t, 0, result.AmountOfCoefficients())
+}
+
 func BenchmarkMulNaiveN10(b *testing.B) { benchmarkMulNaive(b, 1024) }
 func BenchmarkMulNaiveN11(b *testing.B) { benchmarkMulNaive(b, 2048) }
 func BenchmarkMulNaiveN12(b *testing.B) { benchmarkMulNaive(b, 4096) }
@@ -525,6 +594,41 @@ func BenchmarkMulSparseFFTD262144T4096(t *testing.B) { benchmarkMulSparseFFT(t,
 func BenchmarkMulSparseFFTD262144T4609(t *testing.B) { benchmarkMulSparseFFT(t, 262144, 4609) }
 func BenchmarkMulSparseFFTD262144T5120(t *testing.B) { benchmarkMulSparseFFT(t, 262144, 5120) }
 
+// Realistic LPN parameters: t-sparse noise polynomials of degree 2^N, multiplied with each other (c^2 OLE cross terms),
+// with dense polynomials and reduced modulo x^(2^N) + 1.
+func BenchmarkMulSparseSparseN16T16(b *testing.B) { benchmarkMulSparseSparse(b, 16, 16) }
+func BenchmarkMulSparseSparseN16T76(b *testing.B) { benchmarkMulSparseSparse(b, 16, 76) }
+func BenchmarkMulSparseSparseN18T16(b *testing.B) { benchmarkMulSparseSparse(b, 18, 16) }
+func BenchmarkMulSparseSparseN18T76(b *testing.B) { benchmarkMulSparseSparse(b, 18, 76) }
+func BenchmarkMulSparseSparseN20T16(b *testing.B) { benchmarkMulSparseSparse(b, 20, 16) }
+func BenchmarkMulSparseSparseN20T76(b *testing.B) { benchmarkMulSparseSparse(b, 20, 76) }
+
+func BenchmarkMulSparseSparseNaiveN16T16(b *testing.B) { benchmarkMulSparseNaive(b, 1<<16, 16) }
+func BenchmarkMulSparseSparseNaiveN16T76(b *testing.B) { benchmarkMulSparseNaive(b, 1<<16, 76) }
+func BenchmarkMulSparseSparseNaiveN20T16(b *testing.B) { benchmarkMulSparseNaive(b, 1<<20, 16) }
+func BenchmarkMulSparseSparseNaiveN20T76(b *testing.B) { benchmarkMulSparseNaive(b, 1<<20, 76) }
+
+func BenchmarkMulSparseDenseN16T16(b *testing.B) { benchmarkMulSparseDense(b, 16, 16) }
+func BenchmarkMulSparseDenseN16T76(b *testing.B) { benchmarkMulSparseDense(b, 16, 76) }
+func BenchmarkMulSparseDenseN18T16(b *testing.B) { benchmarkMulSparseDense(b, 18, 16) }
+func BenchmarkMulSparseDenseN18T76(b *testing.B) { benchmarkMulSparseDense(b, 18, 76) }
+func BenchmarkMulSparseDenseN20T16(b *testing.B) { benchmarkMulSparseDense(b, 20, 16) }
+func BenchmarkMulSparseDenseN20T76(b *testing.B) { benchmarkMulSparseDense(b, 20, 76) }
+
+func BenchmarkOLECrossTermsN16C2T76(b *testing.B) { benchmarkOLECrossTerms(b, 16, 2, 76) }
+func BenchmarkOLECrossTermsN16C4T16(b *testing.B) { benchmarkOLECrossTerms(b, 16, 4, 16) }
+func BenchmarkOLECrossTermsN18C2T76(b *testing.B) { benchmarkOLECrossTerms(b, 18, 2, 76) }
+func BenchmarkOLECrossTermsN18C4T16(b *testing.B) { benchmarkOLECrossTerms(b, 18, 4, 16) }
+func BenchmarkOLECrossTermsN20C2T76(b *testing.B) { benchmarkOLECrossTerms(b, 20, 2, 76) }
+func BenchmarkOLECrossTermsN20C4T16(b *testing.B) { benchmarkOLECrossTerms(b, 20, 4, 16) }
+func BenchmarkOLECrossTermsN20C4T76(b *testing.B) { benchmarkOLECrossTerms(b, 20, 4, 76) }
+
+func BenchmarkModCyclotomicN16(b *testing.B) { benchmarkModCyclotomic(b, 16) }
+func BenchmarkModCyclotomicN17(b *testing.B) { benchmarkModCyclotomic(b, 17) }
+func BenchmarkModCyclotomicN18(b *testing.B) { benchmarkModCyclotomic(b, 18) }
+func BenchmarkModCyclotomicN19(b *testing.B) { benchmarkModCyclotomic(b, 19) }
+func BenchmarkModCyclotomicN20(b *testing.B) { benchmarkModCyclotomic(b, 20) }
+
 func benchmarkMulNaive(b *testing.B, n int) {
 	slice1 := randomFrSlice(n)
 	poly1 := NewFromFr(slice1)
@@ -587,6 +691,72 @@ func benchmarkMulSparseFFT(b *testing.B, degree, sparseness int) {
 	}
 }
 
+func benchmarkMulSparseSparse(b *testing.B, N, sparseness int) {
+	poly1 := randomSparsePoly(sparseness, 1<<N)
+	poly2 := randomSparsePoly(sparseness, 1<<N)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Mul(poly1, poly2); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkMulSparseDense(b *testing.B, N, sparseness int) {
+	poly1 := randomSparsePoly(sparseness, 1<<N)
+	poly2 := NewFromFr(randomFrSlice(1 << N))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Mul(poly1, poly2); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkOLECrossTerms multiplies c t-sparse polynomials with c other ones and reduces the c^2 products modulo
+// x^(2^N) + 1, as for the OLE correlations of the PCG.
+func benchmarkOLECrossTerms(b *testing.B, N, c, sparseness int) {
+	u := make([]*Polynomial, c)
+	v := make([]*Polynomial, c)
+	for r := 0; r < c; r++ {
+		u[r] = randomSparsePoly(sparseness, 1<<N)
+		v[r] = randomSparsePoly(sparseness, 1<<N)
+	}
+	div, err := NewCyclotomicPolynomial(big.NewInt(1 << (N + 1)))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for r := 0; r < c; r++ {
+			for s := 0; s < c; s++ {
+				prod, err := Mul(u[r], v[s])
+				if err != nil {
+					b.Fatal(err)
+				}
+				if _, err := prod.Mod(div); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	}
+}
+
+func benchmarkModCyclotomic(b *testing.B, N int) {
+	// A dense product of two polynomials of degree 2^N.
+	p := NewFromFr(randomFrSlice(1 << (N + 1)))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := p.ModCyclotomic(1 << N); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func benchmarkEvaluationHornerParallel(b *testing.B, n int) {
 	slice1 := randomFrSlice(n)
 	poly1 := NewFromFr(slice1)
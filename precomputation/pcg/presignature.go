@@ -0,0 +1,114 @@
+package pcg
+
+import (
+	"errors"
+	"fmt"
+
+	bls12381 "github.com/kilic/bls12-381"
+
+	fhksbbsplus "github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
+	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation/pcg/poly"
+)
+
+// LivePreSignature returns the pre-signature of the online phase held in the tuple. The tuple must be generated for
+// the signer set of the signature, which holds for all tuples of EvalCombined and for the tuples of
+// SeparateBBSPlusTupleGenerator.GenBBSPlusTuple.
+func (t *BBSPlusTuple) LivePreSignature() *fhksbbsplus.LivePreSignature {
+	livePreSignature := fhksbbsplus.NewLivePreSignature()
+	livePreSignature.AShare.Set(t.AShare)
+	livePreSignature.EShare.Set(t.EShare)
+	livePreSignature.SShare.Set(t.SShare)
+	livePreSignature.DeltaShare.Set(t.DeltaShare)
+	livePreSignature.AlphaShare.Set(t.AlphaShare)
+	livePreSignature.Epoch = t.Epoch
+	return livePreSignature
+}
+
+// LivePreSignatureSk returns the pre-signature of the tuple together with the secret key share.
+func (t *BBSPlusTuple) LivePreSignatureSk() *fhksbbsplus.LivePreSignatureSk {
+	livePreSignature := t.LivePreSignature()
+	return &fhksbbsplus.LivePreSignatureSk{
+		SkShare:    bls12381.NewFr().Set(t.SkShare),
+		AShare:     livePreSignature.AShare,
+		EShare:     livePreSignature.EShare,
+		SShare:     livePreSignature.SShare,
+		DeltaShare: livePreSignature.DeltaShare,
+		AlphaShare: livePreSignature.AlphaShare,
+		Epoch:      livePreSignature.Epoch,
+	}
+}
+
+// LiveBBSPreSignature returns the BBS pre-signature of the online phase held in the tuple.
+func (t *BBSTuple) LiveBBSPreSignature() *fhksbbsplus.LiveBBSPreSignature {
+	livePreSignature := fhksbbsplus.NewLiveBBSPreSignatureFromValues(
+		bls12381.NewFr().Set(t.AShare),
+		bls12381.NewFr().Set(t.EShare),
+		bls12381.NewFr().Set(t.DeltaShare),
+	)
+	livePreSignature.Epoch = t.Epoch
+	return livePreSignature
+}
+
+// Signer computes the partial signatures of a party directly from its seed. The pre-signature with index k is derived
+// at the k-th root of the ring, so all parties must evaluate their seeds with the same random polynomials and ring.
+// Signer sets are given as the 0-based indices of the seeds.
+type Signer struct {
+	ring      *Ring
+	generator *SeparateBBSPlusTupleGenerator
+}
+
+// NewSigner evaluates seed with p.
+func NewSigner(p *PCG, seed *Seed, rand []*poly.Polynomial, ring *Ring) (*Signer, error) {
+	generator, err := p.EvalSeparate(seed, rand, ring.Div)
+	if err != nil {
+		return nil, fmt.Errorf("evaluate seed: %w", err)
+	}
+	return &Signer{ring: ring, generator: generator}, nil
+}
+
+// Index returns the index of the seed of the Signer.
+func (s *Signer) Index() int {
+	return s.generator.ownIndex
+}
+
+// Epoch returns the key epoch of the seed of the Signer.
+func (s *Signer) Epoch() uint64 {
+	return s.generator.epoch
+}
+
+// PreSignatures returns the number of pre-signatures that can be derived from the seed.
+func (s *Signer) PreSignatures() int {
+	return len(s.ring.Roots)
+}
+
+// PreSignature derives the pre-signature with the given index for signerSet.
+func (s *Signer) PreSignature(index int, signerSet []int) (*fhksbbsplus.LivePreSignature, error) {
+	if index < 0 || index >= len(s.ring.Roots) {
+		return nil, fmt.Errorf("pre-signature index %d out of range [0, %d)", index, len(s.ring.Roots))
+	}
+	tuple := s.generator.GenBBSPlusTuple(s.ring.Roots[index], signerSet)
+	if tuple == nil {
+		return nil, errors.New("invalid signer set: must contain own index and, for tau = n, all parties")
+	}
+	return tuple.LivePreSignature(), nil
+}
+
+// PartialSignature computes the partial signature of messages with the pre-signature of the given index for
+// signerSet. The pre-signature is consumed in store before the partial signature is computed. If the seed is of
+// another epoch than keyShare, fhksbbsplus.ErrEpochMismatch is returned before anything is derived from it.
+func (s *Signer) PartialSignature(
+	store fhksbbsplus.PreSignatureStore,
+	keyShare *fhksbbsplus.PartySecretKey,
+	index int,
+	signerSet []int,
+	messages []*bls12381.Fr,
+	pk *fhksbbsplus.PublicKey) (*fhksbbsplus.PartialThresholdSignature, error) {
+	if err := keyShare.CheckEpoch(s.Epoch()); err != nil {
+		return nil, err
+	}
+	preSignature, err := s.PreSignature(index, signerSet)
+	if err != nil {
+		return nil, err
+	}
+	return fhksbbsplus.NewPartialThresholdSignature().NewOnce(store, index, signerSet, messages, pk, keyShare, preSignature)
+}
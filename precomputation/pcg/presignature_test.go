@@ -0,0 +1,69 @@
+package pcg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	fhksbbsplus "github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
+	"github.com/perun-network/bbs-plus-threshold-wallet/test"
+)
+
+func TestSignerTau2N3(t *testing.T) {
+	pcg, err := NewPCG(128, 10, 3, 2, 2, 4) // Small lpn parameters for testing.
+	require.NoError(t, err)
+	sk, seeds, err := pcg.SeedGenWithSk()
+	require.NoError(t, err)
+	randPolys, err := pcg.PickRandomPolynomials()
+	require.NoError(t, err)
+	ring, err := pcg.GetRing(false)
+	require.NoError(t, err)
+
+	signerSet := []int{0, 2}
+	signers := make([]*Signer, len(signerSet))
+	keyShares := make([]*fhksbbsplus.PartySecretKey, len(signerSet))
+	stores := make([]*fhksbbsplus.MemoryPreSignatureStore, len(signerSet))
+	for i, index := range signerSet {
+		signers[i], err = NewSigner(pcg, seeds[index], randPolys, ring)
+		require.NoError(t, err)
+		assert.Equal(t, index, signers[i].Index())
+		keyShares[i] = seedKeyShare(seeds[index])
+		stores[i] = fhksbbsplus.NewMemoryPreSignatureStore()
+	}
+
+	pk := fhksbbsplus.GeneratePublicKey(test.SeedKeys, sk, test.MessageCount)
+	messages := helper.GetRandomMessagesFromSeed(test.SeedMessages, 2, test.MessageCount)
+	for iK, message := range messages {
+		partialSignatures := make([]*fhksbbsplus.PartialThresholdSignature, len(signers))
+		for i, signer := range signers {
+			partialSignatures[i], err = signer.PartialSignature(stores[i], keyShares[i], iK, signerSet, message, pk)
+			require.NoError(t, err)
+		}
+		signature := fhksbbsplus.NewThresholdSignature().FromPartialSignatures(partialSignatures)
+		assert.True(t, signature.Verify(message, pk))
+	}
+
+	_, err = signers[0].PartialSignature(stores[0], keyShares[0], 0, signerSet, messages[1], pk)
+	assert.ErrorIs(t, err, fhksbbsplus.ErrPreSignatureConsumed)
+
+	// After a refresh of the key share, the seed of the old epoch must not be used anymore.
+	refreshed := seedKeyShare(seeds[signerSet[0]])
+	refreshed.Epoch++
+	_, err = signers[0].PartialSignature(stores[0], refreshed, 2, signerSet, messages[0], pk)
+	assert.ErrorIs(t, err, fhksbbsplus.ErrEpochMismatch)
+	_, err = signers[0].PreSignature(0, []int{1, 2})
+	assert.Error(t, err, "own index must be in the signer set")
+	_, err = signers[0].PreSignature(signers[0].PreSignatures(), signerSet)
+	assert.Error(t, err)
+}
+
+// seedKeyShare returns the secret key share of seed as PartySecretKey of the epoch of the seed.
+func seedKeyShare(seed *Seed) *fhksbbsplus.PartySecretKey {
+	return &fhksbbsplus.PartySecretKey{
+		SKeyShare: fhksbbsplus.SecretKey{Fr: seed.GetSki()},
+		Index:     seed.GetIndex() + 1,
+		Epoch:     seed.GetEpoch(),
+	}
+}
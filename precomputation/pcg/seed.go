@@ -1,9 +1,12 @@
 package pcg
 
 import (
+	"encoding/binary"
 	"fmt"
 	bls12381 "github.com/kilic/bls12-381"
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
 	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation/dspf"
+	"math"
 	"math/big"
 )
 
@@ -19,6 +22,8 @@ type seedCoefficients struct {
 	sEpsilon [][]*bls12381.Fr // Coefficients for s_i
 }
 
+// DSPFKeyPair holds the two keys of a DSPF. In a Seed of party i, the pair at counterparty j instead holds the keys of
+// party i only: Key0 is its key of the correlation embedded for (i, j) and Key1 its key of the one for (j, i).
 type DSPFKeyPair struct {
 	Key0 dspf.Key
 	Key1 dspf.Key
@@ -28,12 +33,46 @@ type DSPFKeyPair struct {
 // It allows to derive ECDSA tuples from the EvalAll function of the PCG.
 type Seed struct {
 	index        int
+	epoch        uint64 // Key epoch of ski.
 	ski          *bls12381.Fr
 	exponents    seedExponents
 	coefficients seedCoefficients
-	U            [][][]*DSPFKeyPair   // U[i][j][r]
-	C            [][][][]*DSPFKeyPair // C[i][j][r][s]
-	V            [][][][]*DSPFKeyPair // V[i][j][r][s]
+	U            [][]*DSPFKeyPair   // U[j][r], nil for j = index.
+	C            [][][]*DSPFKeyPair // C[j][r][s], nil for j = index.
+	V            [][][]*DSPFKeyPair // V[j][r][s], nil for j = index.
+}
+
+// ownVOLEKeys extracts the DSPF keys of party i from the keys U[i][j][r] generated for all pairs of parties.
+func ownVOLEKeys(U [][][]*DSPFKeyPair, i int) [][]*DSPFKeyPair {
+	own := make([][]*DSPFKeyPair, len(U))
+	for j := range U {
+		if j == i {
+			continue
+		}
+		own[j] = make([]*DSPFKeyPair, len(U[i][j]))
+		for r := range own[j] {
+			own[j][r] = &DSPFKeyPair{Key0: U[i][j][r].Key0, Key1: U[j][i][r].Key1}
+		}
+	}
+	return own
+}
+
+// ownOLEKeys extracts the DSPF keys of party i from the keys C[i][j][r][s] generated for all pairs of parties.
+func ownOLEKeys(C [][][][]*DSPFKeyPair, i int) [][][]*DSPFKeyPair {
+	own := make([][][]*DSPFKeyPair, len(C))
+	for j := range C {
+		if j == i {
+			continue
+		}
+		own[j] = make([][]*DSPFKeyPair, len(C[i][j]))
+		for r := range own[j] {
+			own[j][r] = make([]*DSPFKeyPair, len(C[i][j][r]))
+			for s := range own[j][r] {
+				own[j][r][s] = &DSPFKeyPair{Key0: C[i][j][r][s].Key0, Key1: C[j][i][r][s].Key1}
+			}
+		}
+	}
+	return own
 }
 
 func (s *Seed) GetSki() *bls12381.Fr {
@@ -44,12 +83,452 @@ func (s *Seed) GetIndex() int {
 	return s.index
 }
 
+// GetEpoch returns the key epoch of the secret key share in the seed. Tuples generated from the seed carry the same
+// epoch and are incompatible with secret key shares of other epochs.
+func (s *Seed) GetEpoch() uint64 {
+	return s.epoch
+}
+
+// Zeroize overwrites the secret key share and the coefficients of the seed with zeros. The seed must not be used
+// afterwards. The DSPF keys are not overwritten.
+func (s *Seed) Zeroize() {
+	s.ski.Zero()
+	for _, m := range [][][]*bls12381.Fr{s.coefficients.aBeta, s.coefficients.eGamma, s.coefficients.sEpsilon} {
+		for _, row := range m {
+			for _, fr := range row {
+				fr.Zero()
+			}
+		}
+	}
+}
+
+// Encoding of a Seed, all integers are little-endian:
+//
+//	magic "PCGS" | version (uint8) | kind (uint8) | index (uint32) | epoch (uint64) | ski (32 bytes)
+//	aOmega | eEta | sPhi       exponent matrices: rows (uint32), per row: count (uint32) | count * uint64
+//	aBeta | eGamma | sEpsilon  coefficient matrices: rows (uint32), per row: count (uint32) | count * 32 bytes
+//	U | C | V                  nested DSPF key pairs: length (uint32) per dimension, empty for the own index, per
+//	                           pair: present (uint8) and, if present, the lengths (uint32) and bytes of
+//	                           Key0.SerializeKeys and Key1.SerializeKeys
+//
+// The kind is 0 for BBS+ seeds and 1 for BBS seeds, which omit sPhi, sEpsilon and C.
+const (
+	seedMagic   = "PCGS"
+	seedVersion = 2
+
+	seedKindBBSPlus = 0
+	seedKindBBS     = 1
+)
+
+// SeedSize reports the sizes in bytes of the parts of an encoded Seed.
+type SeedSize struct {
+	Header       int // Magic, version, index, epoch and secret key share.
+	Exponents    int
+	Coefficients int
+	U            int // DSPF keys of the VOLE correlation (a * sk).
+	C            int // DSPF keys of the OLE correlation (a * s), 0 for BBS seeds.
+	V            int // DSPF keys of the OLE correlation (a * e).
+	Total        int
+}
+
+// Serialize converts a Seed into its versioned binary encoding. The encoding contains the secret key share of the
+// party and must be stored encrypted, e.g., in a keystore.
 func (s *Seed) Serialize() ([]byte, error) {
-	return nil, fmt.Errorf("not implemented")
+	data, _, err := s.encode()
+	return data, err
+}
+
+// Size returns the sizes of the parts of the encoding of the Seed.
+func (s *Seed) Size() (SeedSize, error) {
+	_, size, err := s.encode()
+	return size, err
 }
 
+func (s *Seed) encode() ([]byte, SeedSize, error) {
+	var size SeedSize
+	if s.index < 0 || uint64(s.index) > math.MaxUint32 {
+		return nil, size, fmt.Errorf("seed index %d out of range", s.index)
+	}
+
+	w := &seedWriter{}
+	w.buf = append(w.buf, seedMagic...)
+	w.buf = append(w.buf, seedVersion)
+	bbs := s.isBBS()
+	if bbs {
+		w.buf = append(w.buf, seedKindBBS)
+	} else {
+		w.buf = append(w.buf, seedKindBBSPlus)
+	}
+	w.uint32(s.index)
+	w.buf = binary.LittleEndian.AppendUint64(w.buf, s.epoch)
+	w.buf = append(w.buf, s.ski.ToBytes()...)
+	size.Header = w.mark()
+
+	w.exponentMatrix(s.exponents.aOmega)
+	w.exponentMatrix(s.exponents.eEta)
+	if !bbs {
+		w.exponentMatrix(s.exponents.sPhi)
+	}
+	size.Exponents = w.mark()
+
+	w.frMatrix(s.coefficients.aBeta)
+	w.frMatrix(s.coefficients.eGamma)
+	if !bbs {
+		w.frMatrix(s.coefficients.sEpsilon)
+	}
+	size.Coefficients = w.mark()
+
+	w.keyPairMatrix(s.U)
+	size.U = w.mark()
+	if !bbs {
+		w.keyPairTensor(s.C)
+	}
+	size.C = w.mark()
+	w.keyPairTensor(s.V)
+	size.V = w.mark()
+
+	if w.err != nil {
+		return nil, SeedSize{}, w.err
+	}
+	size.Total = len(w.buf)
+	return w.buf, size, nil
+}
+
+// Deserialize converts the binary encoding of a Seed into s. s is only changed if data holds a well-formed seed.
 func (s *Seed) Deserialize(data []byte) error {
-	return fmt.Errorf("not implemented")
+	r := &seedReader{data: data}
+	if string(r.next(len(seedMagic))) != seedMagic {
+		return fmt.Errorf("%w: not a PCG seed", helper.ErrInvalidEncoding)
+	}
+	switch version := r.uint8(); {
+	case r.err != nil:
+	case version != seedVersion:
+		return fmt.Errorf("%w: unsupported seed version %d", helper.ErrInvalidEncoding, version)
+	}
+
+	var bbs bool
+	switch kind := r.uint8(); {
+	case r.err != nil:
+	case kind == seedKindBBS:
+		bbs = true
+	case kind != seedKindBBSPlus:
+		return fmt.Errorf("%w: unknown seed kind %d", helper.ErrInvalidEncoding, kind)
+	}
+
+	seed := Seed{
+		index: r.uint32(),
+		epoch: r.uint64(),
+		ski:   r.fr(),
+	}
+	seed.exponents.aOmega = r.exponentMatrix()
+	seed.exponents.eEta = r.exponentMatrix()
+	if !bbs {
+		seed.exponents.sPhi = r.exponentMatrix()
+	}
+	seed.coefficients.aBeta = r.frMatrix()
+	seed.coefficients.eGamma = r.frMatrix()
+	if !bbs {
+		seed.coefficients.sEpsilon = r.frMatrix()
+	}
+	seed.U = r.keyPairMatrix()
+	if !bbs {
+		seed.C = r.keyPairTensor()
+	}
+	seed.V = r.keyPairTensor()
+
+	if r.err == nil && len(r.data) != 0 {
+		r.err = fmt.Errorf("%w: %d trailing bytes", helper.ErrInvalidEncoding, len(r.data))
+	}
+	if r.err == nil {
+		r.err = seed.checkDimensions()
+	}
+	if r.err != nil {
+		return fmt.Errorf("decode seed: %w", r.err)
+	}
+	*s = seed
+	return nil
+}
+
+// isBBS reports whether s is a seed of the BBS variant of the PCG, which holds no s exponents, s coefficients and C keys.
+func (s *Seed) isBBS() bool {
+	return s.C == nil
+}
+
+// checkDimensions checks that the parts of a decoded seed fit the number of parties n, the own index and the lpn
+// parameters c and t, which are taken from the DSPF keys and the exponents of the seed.
+func (s *Seed) checkDimensions() error {
+	bbs := s.isBBS()
+	n := len(s.U)
+	if n < 2 || (!bbs && len(s.C) != n) || len(s.V) != n {
+		return fmt.Errorf("%w: DSPF keys for %d, %d and %d parties", helper.ErrInvalidEncoding, len(s.U), len(s.C), len(s.V))
+	}
+	if s.index >= n {
+		return fmt.Errorf("%w: index %d of %d parties", helper.ErrInvalidEncoding, s.index, n)
+	}
+
+	c := len(s.exponents.aOmega)
+	if c == 0 || len(s.exponents.aOmega[0]) == 0 {
+		return fmt.Errorf("%w: empty exponents", helper.ErrInvalidEncoding)
+	}
+	t := len(s.exponents.aOmega[0])
+	exponents := [][][]*big.Int{s.exponents.aOmega, s.exponents.eEta}
+	coefficients := [][][]*bls12381.Fr{s.coefficients.aBeta, s.coefficients.eGamma}
+	if !bbs {
+		exponents = append(exponents, s.exponents.sPhi)
+		coefficients = append(coefficients, s.coefficients.sEpsilon)
+	}
+	for _, m := range exponents {
+		if !hasShape(m, c, t) {
+			return fmt.Errorf("%w: exponents are not %d x %d", helper.ErrInvalidEncoding, c, t)
+		}
+	}
+	for _, m := range coefficients {
+		if !hasShape(m, c, t) {
+			return fmt.Errorf("%w: coefficients are not %d x %d", helper.ErrInvalidEncoding, c, t)
+		}
+	}
+
+	for j := 0; j < n; j++ {
+		if j == s.index {
+			if s.U[j] != nil || (!bbs && s.C[j] != nil) || s.V[j] != nil {
+				return fmt.Errorf("%w: DSPF keys for the own index", helper.ErrInvalidEncoding)
+			}
+			continue
+		}
+		if !hasKeyPairs([][]*DSPFKeyPair{s.U[j]}, 1, c, t) ||
+			(!bbs && !hasKeyPairs(s.C[j], c, c, t*t)) || !hasKeyPairs(s.V[j], c, c, t*t) {
+			return fmt.Errorf("%w: DSPF keys of party %d do not match c = %d and t = %d", helper.ErrInvalidEncoding, j, c, t)
+		}
+	}
+	return nil
+}
+
+// hasShape reports whether m has rows rows of cols elements.
+func hasShape[T any](m [][]T, rows, cols int) bool {
+	if len(m) != rows {
+		return false
+	}
+	for _, row := range m {
+		if len(row) != cols {
+			return false
+		}
+	}
+	return true
+}
+
+// hasKeyPairs reports whether m has rows rows of cols key pairs whose keys consist of points DPF keys each.
+func hasKeyPairs(m [][]*DSPFKeyPair, rows, cols, points int) bool {
+	if !hasShape(m, rows, cols) {
+		return false
+	}
+	for _, row := range m {
+		for _, pair := range row {
+			if pair == nil || len(pair.Key0.DPFKeys) != points || len(pair.Key1.DPFKeys) != points {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// seedWriter appends the parts of a Seed to buf and keeps the first error.
+type seedWriter struct {
+	buf  []byte
+	last int // Length of buf at the last mark.
+	err  error
+}
+
+// mark returns the number of bytes written since the last mark.
+func (w *seedWriter) mark() int {
+	n := len(w.buf) - w.last
+	w.last = len(w.buf)
+	return n
+}
+
+func (w *seedWriter) uint32(v int) {
+	w.buf = binary.LittleEndian.AppendUint32(w.buf, uint32(v))
+}
+
+func (w *seedWriter) bytes(b []byte) {
+	w.uint32(len(b))
+	w.buf = append(w.buf, b...)
+}
+
+func (w *seedWriter) exponentMatrix(m [][]*big.Int) {
+	w.uint32(len(m))
+	for _, row := range m {
+		w.uint32(len(row))
+		for _, exponent := range row {
+			if exponent.Sign() < 0 || !exponent.IsUint64() {
+				w.err = fmt.Errorf("exponent %v out of range", exponent)
+				return
+			}
+			w.buf = binary.LittleEndian.AppendUint64(w.buf, exponent.Uint64())
+		}
+	}
+}
+
+func (w *seedWriter) frMatrix(m [][]*bls12381.Fr) {
+	w.uint32(len(m))
+	for _, row := range m {
+		w.uint32(len(row))
+		for _, fr := range row {
+			w.buf = append(w.buf, fr.ToBytes()...)
+		}
+	}
+}
+
+func (w *seedWriter) keyPairTensor(t [][][]*DSPFKeyPair) {
+	w.uint32(len(t))
+	for _, m := range t {
+		w.keyPairMatrix(m)
+	}
+}
+
+func (w *seedWriter) keyPairMatrix(m [][]*DSPFKeyPair) {
+	w.uint32(len(m))
+	for _, row := range m {
+		w.uint32(len(row))
+		for _, pair := range row {
+			if pair == nil {
+				w.buf = append(w.buf, 0)
+				continue
+			}
+			w.buf = append(w.buf, 1)
+			for _, key := range []*dspf.Key{&pair.Key0, &pair.Key1} {
+				data, err := key.SerializeKeys()
+				if err != nil && w.err == nil {
+					w.err = err
+				}
+				w.bytes(data)
+			}
+		}
+	}
+}
+
+// seedReader consumes the parts of a Seed from data and keeps the first error. After an error, all reads return
+// zero values.
+type seedReader struct {
+	data []byte
+	err  error
+}
+
+func (r *seedReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.data) {
+		r.err = fmt.Errorf("%w: unexpected end of data", helper.ErrInvalidEncoding)
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *seedReader) uint8() uint8 {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *seedReader) uint32() int {
+	if b := r.next(4); b != nil {
+		return int(binary.LittleEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (r *seedReader) uint64() uint64 {
+	if b := r.next(8); b != nil {
+		return binary.LittleEndian.Uint64(b)
+	}
+	return 0
+}
+
+// count reads a length and checks that the remaining data can hold as many elements of at least elementSize bytes.
+func (r *seedReader) count(elementSize int) int {
+	n := r.uint32()
+	if r.err == nil && n > len(r.data)/elementSize {
+		r.err = fmt.Errorf("%w: length %d exceeds data", helper.ErrInvalidEncoding, n)
+	}
+	if r.err != nil {
+		return 0
+	}
+	return n
+}
+
+func (r *seedReader) fr() *bls12381.Fr {
+	b := r.next(helper.LenBytesFr)
+	if b == nil {
+		return bls12381.NewFr()
+	}
+	fr, err := helper.FrFromCanonicalBytes(b)
+	if err != nil {
+		r.err = err
+		return bls12381.NewFr()
+	}
+	return fr
+}
+
+func (r *seedReader) exponentMatrix() [][]*big.Int {
+	m := make([][]*big.Int, r.count(4))
+	for i := range m {
+		m[i] = make([]*big.Int, r.count(8))
+		for j := range m[i] {
+			m[i][j] = new(big.Int).SetUint64(r.uint64())
+		}
+	}
+	return m
+}
+
+func (r *seedReader) frMatrix() [][]*bls12381.Fr {
+	m := make([][]*bls12381.Fr, r.count(4))
+	for i := range m {
+		m[i] = make([]*bls12381.Fr, r.count(helper.LenBytesFr))
+		for j := range m[i] {
+			m[i][j] = r.fr()
+		}
+	}
+	return m
+}
+
+func (r *seedReader) keyPairTensor() [][][]*DSPFKeyPair {
+	t := make([][][]*DSPFKeyPair, r.count(4))
+	for i := range t {
+		if m := r.keyPairMatrix(); len(m) > 0 {
+			t[i] = m
+		}
+	}
+	return t
+}
+
+func (r *seedReader) keyPairMatrix() [][]*DSPFKeyPair {
+	m := make([][]*DSPFKeyPair, r.count(4))
+	for i := range m {
+		// Empty rows are the nil rows of the own index.
+		if n := r.count(1); n > 0 {
+			m[i] = make([]*DSPFKeyPair, n)
+		}
+		for j := range m[i] {
+			switch present := r.uint8(); {
+			case r.err != nil || present == 0:
+			case present == 1:
+				pair := &DSPFKeyPair{}
+				for _, key := range []*dspf.Key{&pair.Key0, &pair.Key1} {
+					data := r.next(r.uint32())
+					if r.err == nil {
+						r.err = key.DeserializeKeys(data)
+					}
+				}
+				m[i][j] = pair
+			default:
+				r.err = fmt.Errorf("%w: invalid key pair flag %d", helper.ErrInvalidEncoding, present)
+			}
+		}
+	}
+	return m
 }
 
 type oleSeed struct {
@@ -0,0 +1,159 @@
+package pcg
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
+	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation/dpf/optreedpf"
+)
+
+func TestSeedEncodingRoundTrip(t *testing.T) {
+	for _, params := range []struct{ N, n, tau, c, t int }{
+		{10, 2, 2, 2, 4},
+		{10, 3, 2, 2, 4},
+		{12, 4, 3, 2, 8},
+		{14, 2, 2, 4, 16},
+	} {
+		t.Run(fmt.Sprintf("N=%d,n=%d,c=%d,t=%d", params.N, params.n, params.c, params.t), func(t *testing.T) {
+			pcg, err := NewPCG(128, params.N, params.n, params.tau, params.c, params.t)
+			require.NoError(t, err)
+			pcg.SetEpoch(2)
+			_, seeds, err := pcg.SeedGenWithSk()
+			require.NoError(t, err)
+
+			for _, seed := range seeds {
+				data, err := seed.Serialize()
+				require.NoError(t, err)
+				size, err := seed.Size()
+				require.NoError(t, err)
+				assert.Equal(t, len(data), size.Total)
+				assert.Equal(t, size.Total, size.Header+size.Exponents+size.Coefficients+size.U+size.C+size.V)
+				t.Logf("seed %d: %+v", seed.GetIndex(), size)
+
+				decoded := &Seed{}
+				require.NoError(t, decoded.Deserialize(data))
+				assertSeedsEqual(t, seed, decoded)
+			}
+		})
+	}
+}
+
+func TestSeedEncodingRoundTripBBS(t *testing.T) {
+	pcg, err := NewPCG(128, 10, 3, 2, 2, 4) // Small lpn parameters for testing.
+	require.NoError(t, err)
+	_, seeds, err := pcg.SeedGenWithSkBBS()
+	require.NoError(t, err)
+
+	for _, seed := range seeds {
+		data, err := seed.Serialize()
+		require.NoError(t, err)
+		size, err := seed.Size()
+		require.NoError(t, err)
+		assert.Zero(t, size.C)
+
+		decoded := &Seed{}
+		require.NoError(t, decoded.Deserialize(data))
+		assert.True(t, decoded.isBBS())
+		assertSeedsEqual(t, seed, decoded)
+
+		// A BBS seed must not be read as a BBS+ seed.
+		data[len(seedMagic)+1] = seedKindBBSPlus
+		assert.ErrorIs(t, (&Seed{}).Deserialize(data), helper.ErrInvalidEncoding)
+	}
+}
+
+// assertSeedsEqual compares the exponents by value, as zero big.Ints differ in their internal representation.
+func assertSeedsEqual(t *testing.T, expected, actual *Seed) {
+	for k, m := range [][][]*big.Int{expected.exponents.aOmega, expected.exponents.eEta, expected.exponents.sPhi} {
+		other := [][][]*big.Int{actual.exponents.aOmega, actual.exponents.eEta, actual.exponents.sPhi}[k]
+		require.Len(t, other, len(m))
+		for i := range m {
+			require.Len(t, other[i], len(m[i]))
+			for j := range m[i] {
+				assert.Zero(t, m[i][j].Cmp(other[i][j]), "exponent %d[%d][%d]", k, i, j)
+			}
+		}
+	}
+
+	withoutExponents := func(s *Seed) Seed {
+		c := *s
+		c.exponents = seedExponents{}
+		return c
+	}
+	assert.True(t, reflect.DeepEqual(withoutExponents(expected), withoutExponents(actual)), "decoded seed differs")
+}
+
+func TestSeedEncodingMalformed(t *testing.T) {
+	pcg, err := NewPCG(128, 10, 2, 2, 2, 4) // Small lpn parameters for testing.
+	require.NoError(t, err)
+	seeds, err := pcg.TrustedSeedGen()
+	require.NoError(t, err)
+	data, err := seeds[0].Serialize()
+	require.NoError(t, err)
+
+	index := append([]byte{}, data...)
+	binary.LittleEndian.PutUint32(index[len(seedMagic)+2:], 2)
+	shape := *seeds[0]
+	shape.coefficients.aBeta = shape.coefficients.aBeta[:1]
+	shapeData, err := shape.Serialize()
+	require.NoError(t, err)
+	ownKeys := *seeds[0]
+	ownKeys.U = [][]*DSPFKeyPair{seeds[0].U[1], seeds[0].U[1]}
+	ownKeysData, err := ownKeys.Serialize()
+	require.NoError(t, err)
+
+	for name, malformed := range map[string][]byte{
+		"empty":     nil,
+		"magic":     append([]byte("XXXX"), data[4:]...),
+		"version":   append(append([]byte(seedMagic), seedVersion+1), data[5:]...),
+		"truncated": data[:len(data)-1],
+		"trailing":  append(append([]byte{}, data...), 0),
+		"kind":      append(append([]byte(seedMagic), seedVersion, 2), data[6:]...),
+		"index":     index,
+		"shape":     shapeData,
+		"own keys":  ownKeysData,
+	} {
+		seed := &Seed{}
+		err := seed.Deserialize(malformed)
+		assert.ErrorIs(t, err, helper.ErrInvalidEncoding, name)
+		assert.Nil(t, seed.ski, "%s: seed must not be changed", name)
+	}
+}
+
+func TestSeedHoldsOwnKeysOnly(t *testing.T) {
+	pcg, err := NewPCG(128, 10, 3, 2, 2, 4) // Small lpn parameters for testing.
+	require.NoError(t, err)
+	seeds, err := pcg.TrustedSeedGen()
+	require.NoError(t, err)
+
+	for i, seed := range seeds {
+		require.Len(t, seed.U, 3)
+		require.Len(t, seed.C, 3)
+		require.Len(t, seed.V, 3)
+		assert.Nil(t, seed.U[i])
+		assert.Nil(t, seed.C[i])
+		assert.Nil(t, seed.V[i])
+
+		for j := range seeds {
+			if j == i {
+				continue
+			}
+			// Key0 must be the key of the first party of the DSPF and Key1 of the second one.
+			for _, pair := range append(seed.U[j], append(seed.C[j][0], seed.V[j][1]...)...) {
+				for _, key := range pair.Key0.DPFKeys {
+					assert.Equal(t, uint8(0), key.(*optreedpf.Key).ID)
+				}
+				for _, key := range pair.Key1.DPFKeys {
+					assert.Equal(t, uint8(1), key.(*optreedpf.Key).ID)
+				}
+			}
+		}
+	}
+}
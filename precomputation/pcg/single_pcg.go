@@ -106,7 +106,7 @@ func (p *PCG) evalSingleOle(seed *oleSeed, rand []*poly.Polynomial, div *poly.Po
 	if err != nil {
 		return nil, nil, err
 	}
-	oprand, err := outerProductPoly(rand, rand)
+	oprand, err := outerProductPoly(rand, rand, div)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -0,0 +1,29 @@
+package pcg
+
+// Transport connects a party of the distributed seed generation to the other parties, which are indexed 1, ..., n as
+// in the dkg package: the party of the seed with index i is party i+1. The direct transports of dkg.MemoryNetwork
+// connect parties in the same process.
+//
+// Messages between two parties must be delivered reliably and in the order they were sent, and must be private and
+// authenticated. Send must not wait until the receiver reads the message.
+type Transport interface {
+	// Send sends payload to party to.
+	Send(to int, payload []byte) error
+	// Receive returns the next message of party from. It blocks until the message arrived or the transport gives up
+	// waiting, e.g., after a timeout.
+	Receive(from int) ([]byte, error)
+}
+
+// peerConn is the connection to a single other party over a Transport.
+type peerConn struct {
+	transport Transport
+	peer      int
+}
+
+func (c *peerConn) Send(payload []byte) error {
+	return c.transport.Send(c.peer, payload)
+}
+
+func (c *peerConn) Receive() ([]byte, error) {
+	return c.transport.Receive(c.peer)
+}
@@ -3,6 +3,7 @@ package pcg
 import (
 	"bytes"
 	"encoding/gob"
+	"io"
 
 	bls12381 "github.com/kilic/bls12-381"
 
@@ -13,6 +14,8 @@ import (
 // BBSPlusTupleGenerator holds the polynomials from which pre-computed BBS+ signatures can be derived.
 // It is used for the n-out-of-n scheme.
 type BBSPlusTupleGenerator struct {
+	epoch      uint64 // Key epoch of the seed the generator is evaluated from.
+	ringDegree int    // 2^N, the number of tuples; 0 if the generator was not evaluated by the PCG.
 	skShare    *bls12381.Fr
 	aPoly      *poly.Polynomial
 	ePoly      *poly.Polynomial
@@ -47,14 +50,19 @@ func (t *BBSPlusTupleGenerator) GenBBSPlusTuple(root *bls12381.Fr) *BBSPlusTuple
 	delta1iElement := t.delta1Poly.Evaluate(root)
 	delta2iElement := t.delta0Poly.Evaluate(root)
 
-	return NewBBSPlusTuple(t.skShare, aiElement, eiElement, siElement, alphaiElement, deltaiElement, delta1iElement, delta2iElement)
+	tuple := NewBBSPlusTuple(t.skShare, aiElement, eiElement, siElement, alphaiElement, deltaiElement, delta1iElement, delta2iElement)
+	tuple.Epoch = t.epoch
+	return tuple
 }
 
 // BBSPlusTupleGenerator holds the polynomials from which pre-computed BBS+ signatures can be derived.
 // It is used for the tau-out-of-n scheme.
 type SeparateBBSPlusTupleGenerator struct {
-	ownIndex   int // signer index of the participant
-	n          int // number of participants
+	ownIndex   int    // signer index of the participant
+	n          int    // number of participants
+	tau        int    // threshold, the secret key shares are additive if tau == n
+	epoch      uint64 // Key epoch of the seed the generator is evaluated from.
+	ringDegree int    // 2^N, the number of tuples; 0 if the generator was not evaluated by the PCG.
 	usk        *poly.Polynomial
 	uk         *poly.Polynomial
 	uv         *poly.Polynomial
@@ -155,101 +163,94 @@ func (t *SeparateBBSPlusTupleGenerator) GenBBSPlusTupleNoLagrange(root *bls12381
 	deltaiPoly := poly.Add(delta0i, delta1i)
 	deltaiElement := deltaiPoly.Evaluate(root)
 
-	return NewBBSPlusTuple(t.skShare, aiElement, eiElement, siElement, alphaiElement, deltaiElement, deltaiElement, deltaiElement)
+	tuple := NewBBSPlusTuple(t.skShare, aiElement, eiElement, siElement, alphaiElement, deltaiElement, deltaiElement, deltaiElement)
+	tuple.Epoch = t.epoch
+	return tuple
 }
 
 // GenBBSPlusTuple returns a BBSPlusTuple from a SeparateBBSPlusTupleGenerator for a given root.
 // signerSet is the set of signers that are participating. It must contain ownIndex.
+// The returned SkShare is the plain secret key share, the Lagrange coefficients of signerSet are already applied to
+// the share of delta.
 func (t *SeparateBBSPlusTupleGenerator) GenBBSPlusTuple(root *bls12381.Fr, signerSet []int) *BBSPlusTuple {
-	// Check if ownIndex is in signerSet
-	ownIndexInSignerSet := false
-	for _, signer := range signerSet {
-		if signer == t.ownIndex {
-			ownIndexInSignerSet = true
-			break
-		}
-	}
-	if !ownIndexInSignerSet {
+	lagrangeCoeff, ownPosition, ok := t.lagrangeCoefficients(signerSet)
+	if !ok {
 		return nil
 	}
 
-	// Calculate a_i
+	// Calculate a_i, e_i and s_i
 	aiElement := t.aPoly.Evaluate(root)
-
-	// Calculate e_i
 	eiElement := t.ePoly.Evaluate(root)
-
-	// Calculate s_i
 	siElement := t.sPoly.Evaluate(root)
 
-	// Calculate delta_0i based on the signer set
-
-	lagrangeCoeff := helper.Get0LagrangeCoefficientSetFr(signerSet)
-
-	deltaShareAll := bls12381.NewFr().Zero()
-	delta0ShareOwn := bls12381.NewFr().Zero()
-
-	deltaShareFwd := bls12381.NewFr().Zero()
-
-	indI := 0
-
-	for indJ, signer := range signerSet {
+	// Calculate delta_0i = L_i * (a_i*sk_i + sum_j c_ji) + sum_j L_j * c_ij, where c_ij is the share of a_i*sk_j
+	delta0Own := t.usk.Evaluate(root)
+	delta0Fwd := bls12381.NewFr().Zero()
+	for k, signer := range signerSet {
 		if signer != t.ownIndex {
-			// cij * Lj, i = t.ownIndex
-			deltaShareFwd.Mul(t.delta0Poly[signer][forwardDirection].Evaluate(root), lagrangeCoeff[indJ])
-			// cji * Li, i = t.ownIndex
-			delta0ShareOwn.Add(delta0ShareOwn, t.delta0Poly[signer][backwardDirection].Evaluate(root))
+			delta0Own.Add(delta0Own, t.delta0Poly[signer][backwardDirection].Evaluate(root))
 
-		} else {
-			indI = indJ
+			fwd := t.delta0Poly[signer][forwardDirection].Evaluate(root)
+			fwd.Mul(fwd, lagrangeCoeff[k])
+			delta0Fwd.Add(delta0Fwd, fwd)
 		}
-
 	}
+	delta0Own.Mul(delta0Own, lagrangeCoeff[ownPosition])
+	delta0i := bls12381.NewFr()
+	delta0i.Add(delta0Own, delta0Fwd)
 
-	// continue with ownIndex calculation,
-	// second term with Li coefficient
-
-	delta0ShareOwn.Mul(lagrangeCoeff[signerSet[indI]], delta0ShareOwn)
-	deltaShareAll.Add(delta0ShareOwn, deltaShareFwd)
-
-	// delta0 calculation finished
-
-	delta0i := poly.NewEmpty() //TODO: for all i <= N
-
+	// Calculate alpha_i = a_i*s_i + sum_j (a_i*s_j + a_j*s_i)
+	alphaiElement := t.uk.Evaluate(root)
 	for _, signer := range signerSet {
 		if signer != t.ownIndex {
-			delta0i.Add(t.delta0Poly[signer][forwardDirection])
-			delta0i.Add(t.delta0Poly[signer][backwardDirection])
+			alphaiElement.Add(alphaiElement, t.alphaPoly[signer].Evaluate(root))
 		}
 	}
-	delta0i.Add(t.usk)
 
-	// Calculate alpha_i based on the signer set
-	alphai := poly.NewEmpty()
+	// Calculate delta_1i = a_i*e_i + sum_j (a_i*e_j + a_j*e_i)
+	delta1i := t.uv.Evaluate(root)
 	for _, signer := range signerSet {
 		if signer != t.ownIndex {
-			alphai.Add(t.alphaPoly[signer])
+			delta1i.Add(delta1i, t.delta1Poly[signer].Evaluate(root))
 		}
 	}
-	alphai.Add(t.uk)
-	alphaiElement := alphai.Evaluate(root)
 
-	// Calculate delta_1i based on the signer set
-	delta1i := poly.NewEmpty()
-	for _, signer := range signerSet {
-		if signer != t.ownIndex {
-			delta1i.Add(t.delta1Poly[signer])
-		}
-	}
-	delta1i.Add(t.uv)
+	deltaiElement := bls12381.NewFr()
+	deltaiElement.Add(delta0i, delta1i)
 
-	deltaiPolyLagr := poly.NewEmpty()
-	deltaiPolyLagr.Add(delta1i)
-	deltaiLagrElement := deltaiPolyLagr.Evaluate(root)
-
-	deltaiLagrElement.Add(deltaiLagrElement, deltaShareAll)
+	tuple := NewBBSPlusTuple(t.skShare, aiElement, eiElement, siElement, alphaiElement, deltaiElement, delta1i, delta0i)
+	tuple.Epoch = t.epoch
+	return tuple
+}
 
-	return NewBBSPlusTuple(t.skShare, aiElement, eiElement, siElement, alphaiElement, deltaiLagrElement, deltaiLagrElement, deltaiLagrElement)
+// lagrangeCoefficients returns the Lagrange coefficients of signerSet together with the position of ownIndex in it.
+// ok is false if signerSet does not contain ownIndex or, if tau == n, not all parties.
+func (t *SeparateBBSPlusTupleGenerator) lagrangeCoefficients(signerSet []int) (lagrangeCoeff []*bls12381.Fr, ownPosition int, ok bool) {
+	// Shamir shares are evaluated at index + 1.
+	shamirIndices := make([]int, len(signerSet))
+	ownPosition = -1
+	for k, signer := range signerSet {
+		shamirIndices[k] = signer + 1
+		if signer == t.ownIndex {
+			ownPosition = k
+		}
+	}
+	if ownPosition < 0 {
+		return nil, -1, false
+	}
+	if t.tau == t.n {
+		// Additive sharing, all parties must sign.
+		if len(signerSet) != t.tau {
+			return nil, -1, false
+		}
+		lagrangeCoeff = make([]*bls12381.Fr, len(signerSet))
+		for k := range lagrangeCoeff {
+			lagrangeCoeff[k] = bls12381.NewFr().One()
+		}
+	} else {
+		lagrangeCoeff = helper.Get0LagrangeCoefficientSetFr(shamirIndices)
+	}
+	return lagrangeCoeff, ownPosition, true
 }
 
 // BBSPlusTuple is a share of a pre-computed BBS+ signature generated by the EvalCombined function of the PCG.
@@ -262,6 +263,7 @@ type BBSPlusTuple struct {
 	DeltaShare  *bls12381.Fr
 	DeltaShare1 *bls12381.Fr
 	DeltaShare2 *bls12381.Fr
+	Epoch       uint64 // Key epoch of the seed the tuple is generated from.
 }
 
 // EmptyTuple returns an empty BBSPlusTuple.
@@ -289,6 +291,10 @@ func NewBBSPlusTuple(SkShare, AShare, EShare, SShare, AlphaShare, DeltaShare *bl
 	return tuple
 }
 
+func (t *BBSPlusTuple) GetEpoch() uint64 {
+	return t.Epoch
+}
+
 // Serialize converts a BBSPlusTuple into a byte slice.
 func (t *BBSPlusTuple) Serialize() ([]byte, error) {
 	var b bytes.Buffer
@@ -311,6 +317,10 @@ func (t *BBSPlusTuple) Serialize() ([]byte, error) {
 		return nil, err
 	}
 
+	if err := encoder.Encode(t.Epoch); err != nil {
+		return nil, err
+	}
+
 	return b.Bytes(), nil
 }
 
@@ -341,5 +351,11 @@ func (t *BBSPlusTuple) Deserialize(data []byte) error {
 	}
 	t.SShare.FromBytes(sShareBytes)
 
+	// Tuples serialized before the epoch was added belong to epoch 0.
+	t.Epoch = 0
+	if err := decoder.Decode(&t.Epoch); err != nil && err != io.EOF {
+		return err
+	}
+
 	return nil
 }
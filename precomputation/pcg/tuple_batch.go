@@ -0,0 +1,147 @@
+package pcg
+
+import (
+	"errors"
+
+	bls12381 "github.com/kilic/bls12-381"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation/pcg/poly"
+)
+
+// The roots of Ring.Roots are the odd powers psi^(2i+1) of the 2^(N+1)-th root of unity psi, i.e., exactly the
+// points at which the forward transform of poly.NegacyclicRing evaluates a polynomial. Hence, all 2^N tuples are
+// derived with a single transform per polynomial in O(N * 2^N), instead of O(2^N * 2^N) for evaluating each root with
+// Horner's rule.
+
+// GenAllBBSPlusTuples returns the BBSPlusTuples of all roots of the ring, in the order of Ring.Roots, i.e., the i-th
+// tuple equals GenBBSPlusTuple(ring.Roots[i]).
+func (t *BBSPlusTupleGenerator) GenAllBBSPlusTuples() ([]*BBSPlusTuple, error) {
+	ring, err := newTupleRing(t.ringDegree, t.aPoly, t.ePoly, t.sPoly, t.alphaPoly, t.delta0Poly, t.delta1Poly)
+	if err != nil {
+		return nil, err
+	}
+
+	var evals [6][]*bls12381.Fr
+	for k, p := range []*poly.Polynomial{t.aPoly, t.ePoly, t.sPoly, t.alphaPoly, t.delta0Poly, t.delta1Poly} {
+		if evals[k], err = evaluateAtRoots(ring, ring.Reduce(p)); err != nil {
+			return nil, err
+		}
+	}
+	a, e, s, alpha, delta0, delta1 := evals[0], evals[1], evals[2], evals[3], evals[4], evals[5]
+
+	tuples := make([]*BBSPlusTuple, ring.Degree())
+	for i := range tuples {
+		delta := bls12381.NewFr()
+		delta.Add(delta0[i], delta1[i])
+		tuples[i] = &BBSPlusTuple{
+			SkShare:     bls12381.NewFr().Set(t.skShare),
+			AShare:      a[i],
+			EShare:      e[i],
+			SShare:      s[i],
+			AlphaShare:  alpha[i],
+			DeltaShare:  delta,
+			DeltaShare1: delta1[i],
+			DeltaShare2: delta0[i],
+			Epoch:       t.epoch,
+		}
+	}
+	return tuples, nil
+}
+
+// GenAllBBSPlusTuples returns the BBSPlusTuples of all roots of the ring for signerSet, in the order of Ring.Roots,
+// i.e., the i-th tuple equals GenBBSPlusTuple(ring.Roots[i], signerSet).
+// signerSet is the set of signers that are participating. It must contain ownIndex and, if tau == n, all parties.
+func (t *SeparateBBSPlusTupleGenerator) GenAllBBSPlusTuples(signerSet []int) ([]*BBSPlusTuple, error) {
+	lagrangeCoeff, ownPosition, ok := t.lagrangeCoefficients(signerSet)
+	if !ok {
+		return nil, errors.New("invalid signer set: must contain own index and, for tau = n, all parties")
+	}
+	polys := []*poly.Polynomial{t.usk, t.uk, t.uv, t.aPoly, t.ePoly, t.sPoly}
+	for _, signer := range signerSet {
+		if signer != t.ownIndex {
+			polys = append(polys, t.alphaPoly[signer], t.delta1Poly[signer])
+			polys = append(polys, t.delta0Poly[signer]...)
+		}
+	}
+	ring, err := newTupleRing(t.ringDegree, polys...)
+	if err != nil {
+		return nil, err
+	}
+
+	// The shares of the signer set are linear combinations of the polynomials, hence they are combined before the
+	// transform, as in GenBBSPlusTuple after the evaluation.
+	delta0Own := ring.Reduce(t.usk)
+	delta0 := poly.NewDense(ring.Degree())
+	alpha := ring.Reduce(t.uk)
+	delta1 := ring.Reduce(t.uv)
+	for k, signer := range signerSet {
+		if signer != t.ownIndex {
+			delta0Own.Add(ring.Reduce(t.delta0Poly[signer][backwardDirection]))
+
+			fwd := ring.Reduce(t.delta0Poly[signer][forwardDirection])
+			fwd.MulByConstant(lagrangeCoeff[k])
+			delta0.Add(fwd)
+
+			alpha.Add(ring.Reduce(t.alphaPoly[signer]))
+			delta1.Add(ring.Reduce(t.delta1Poly[signer]))
+		}
+	}
+	delta0Own.MulByConstant(lagrangeCoeff[ownPosition])
+	delta0.Add(delta0Own)
+
+	var evals [6][]*bls12381.Fr
+	for k, p := range []*poly.Dense{ring.Reduce(t.aPoly), ring.Reduce(t.ePoly), ring.Reduce(t.sPoly), alpha, delta0, delta1} {
+		if evals[k], err = evaluateAtRoots(ring, p); err != nil {
+			return nil, err
+		}
+	}
+	a, e, s, alphas, delta0s, delta1s := evals[0], evals[1], evals[2], evals[3], evals[4], evals[5]
+
+	tuples := make([]*BBSPlusTuple, ring.Degree())
+	for i := range tuples {
+		delta := bls12381.NewFr()
+		delta.Add(delta0s[i], delta1s[i])
+		tuples[i] = &BBSPlusTuple{
+			SkShare:     bls12381.NewFr().Set(t.skShare),
+			AShare:      a[i],
+			EShare:      e[i],
+			SShare:      s[i],
+			AlphaShare:  alphas[i],
+			DeltaShare:  delta,
+			DeltaShare1: delta1s[i],
+			DeltaShare2: delta0s[i],
+			Epoch:       t.epoch,
+		}
+	}
+	return tuples, nil
+}
+
+// newTupleRing returns the ring of degree 2^N of a tuple generator. If the generator was not evaluated by the PCG,
+// ringDegree is 0 and the degree is the smallest power of two that exceeds the degrees of the polynomials.
+func newTupleRing(ringDegree int, polys ...*poly.Polynomial) (*poly.NegacyclicRing, error) {
+	if ringDegree == 0 {
+		ringDegree = 1
+		for _, p := range polys {
+			if deg, err := p.Degree(); err == nil {
+				for ringDegree <= deg {
+					ringDegree <<= 1
+				}
+			}
+		}
+	}
+	return poly.NewNegacyclicRing(ringDegree)
+}
+
+// evaluateAtRoots returns the evaluations of the element p of the ring at all roots in the order of Ring.Roots.
+// p is transformed in place.
+func evaluateAtRoots(ring *poly.NegacyclicRing, p *poly.Dense) ([]*bls12381.Fr, error) {
+	if err := ring.Forward(p); err != nil {
+		return nil, err
+	}
+	evals := make([]*bls12381.Fr, p.Len())
+	for i := range evals {
+		evals[i] = bls12381.NewFr().Set(&p.Coefficients[i])
+		evals[i].FromRed()
+	}
+	return evals, nil
+}
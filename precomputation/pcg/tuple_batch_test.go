@@ -0,0 +1,74 @@
+package pcg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenAllBBSPlusTuplesCombined(t *testing.T) {
+	pcg, err := NewPCG(128, 6, 2, 2, 2, 2) // Small lpn parameters for testing.
+	require.NoError(t, err)
+	pcg.SetEpoch(3)
+	seeds, err := pcg.TrustedSeedGen()
+	require.NoError(t, err)
+	randPolys, err := pcg.PickRandomPolynomials()
+	require.NoError(t, err)
+	ring, err := pcg.GetRing(false)
+	require.NoError(t, err)
+
+	generator, err := pcg.EvalCombined(seeds[1], randPolys, ring.Div)
+	require.NoError(t, err)
+	tuples, err := generator.GenAllBBSPlusTuples()
+	require.NoError(t, err)
+	require.Len(t, tuples, len(ring.Roots))
+	for i, root := range ring.Roots {
+		assertTuplesEqual(t, generator.GenBBSPlusTuple(root), tuples[i], i)
+	}
+
+	// Generators that were not evaluated by the PCG derive the ring from the polynomials.
+	generator = NewBBSPlusTupleGenerator(generator.skShare, generator.aPoly, generator.ePoly, generator.sPoly, generator.alphaPoly, generator.delta0Poly, generator.delta1Poly)
+	tuples, err = generator.GenAllBBSPlusTuples()
+	require.NoError(t, err)
+	require.Len(t, tuples, len(ring.Roots))
+	assertTuplesEqual(t, generator.GenBBSPlusTuple(ring.Roots[5]), tuples[5], 5)
+}
+
+func TestGenAllBBSPlusTuplesSeparate(t *testing.T) {
+	pcg, err := NewPCG(128, 6, 3, 2, 2, 2) // Small lpn parameters for testing.
+	require.NoError(t, err)
+	pcg.SetEpoch(3)
+	seeds, err := pcg.TrustedSeedGen()
+	require.NoError(t, err)
+	randPolys, err := pcg.PickRandomPolynomials()
+	require.NoError(t, err)
+	ring, err := pcg.GetRing(false)
+	require.NoError(t, err)
+
+	generator, err := pcg.EvalSeparate(seeds[2], randPolys, ring.Div)
+	require.NoError(t, err)
+	for _, signerSet := range [][]int{{0, 2}, {2, 1}, {0, 1, 2}} {
+		tuples, err := generator.GenAllBBSPlusTuples(signerSet)
+		require.NoError(t, err)
+		require.Len(t, tuples, len(ring.Roots))
+		for i, root := range ring.Roots {
+			assertTuplesEqual(t, generator.GenBBSPlusTuple(root, signerSet), tuples[i], i)
+		}
+	}
+
+	_, err = generator.GenAllBBSPlusTuples([]int{0, 1})
+	assert.Error(t, err)
+}
+
+func assertTuplesEqual(t *testing.T, expected, actual *BBSPlusTuple, index int) {
+	assert.True(t, expected.SkShare.Equal(actual.SkShare), "tuple %d: sk share", index)
+	assert.True(t, expected.AShare.Equal(actual.AShare), "tuple %d: a share", index)
+	assert.True(t, expected.EShare.Equal(actual.EShare), "tuple %d: e share", index)
+	assert.True(t, expected.SShare.Equal(actual.SShare), "tuple %d: s share", index)
+	assert.True(t, expected.AlphaShare.Equal(actual.AlphaShare), "tuple %d: alpha share", index)
+	assert.True(t, expected.DeltaShare.Equal(actual.DeltaShare), "tuple %d: delta share", index)
+	assert.True(t, expected.DeltaShare1.Equal(actual.DeltaShare1), "tuple %d: delta share 1", index)
+	assert.True(t, expected.DeltaShare2.Equal(actual.DeltaShare2), "tuple %d: delta share 2", index)
+	assert.Equal(t, expected.Epoch, actual.Epoch, "tuple %d: epoch", index)
+}
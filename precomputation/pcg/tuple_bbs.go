@@ -0,0 +1,205 @@
+package pcg
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	bls12381 "github.com/kilic/bls12-381"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
+	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation/pcg/poly"
+)
+
+// BBSTupleGenerator holds the polynomials from which pre-computed BBS signatures can be derived.
+// It is used for the n-out-of-n scheme.
+type BBSTupleGenerator struct {
+	epoch     uint64 // Key epoch of the seed the generator is evaluated from.
+	skShare   *bls12381.Fr
+	aPoly     *poly.Polynomial
+	ePoly     *poly.Polynomial
+	deltaPoly *poly.Polynomial
+}
+
+// NewBBSTupleGenerator returns a new BBSTupleGenerator for an n-out-of-n scheme.
+func NewBBSTupleGenerator(SkShare *bls12381.Fr, APoly, EPoly, Delta0Poly, Delta1Poly *poly.Polynomial) *BBSTupleGenerator {
+	return &BBSTupleGenerator{
+		skShare:   SkShare,
+		aPoly:     APoly,
+		ePoly:     EPoly,
+		deltaPoly: poly.Add(Delta0Poly, Delta1Poly),
+	}
+}
+
+// GenBBSTuple returns a BBSTuple from a BBSTupleGenerator for a given root.
+func (t *BBSTupleGenerator) GenBBSTuple(root *bls12381.Fr) *BBSTuple {
+	aiElement := t.aPoly.Evaluate(root)
+	eiElement := t.ePoly.Evaluate(root)
+	deltaiElement := t.deltaPoly.Evaluate(root)
+
+	tuple := NewBBSTuple(t.skShare, aiElement, eiElement, deltaiElement)
+	tuple.Epoch = t.epoch
+	return tuple
+}
+
+// SeparateBBSTupleGenerator holds the polynomials from which pre-computed BBS signatures can be derived.
+// It is used for the tau-out-of-n scheme.
+type SeparateBBSTupleGenerator struct {
+	ownIndex   int    // signer index of the participant
+	epoch      uint64 // Key epoch of the seed the generator is evaluated from.
+	tau        int    // threshold, the secret key is shared additively if tau equals the number of participants
+	usk        *poly.Polynomial
+	uv         *poly.Polynomial
+	skShare    *bls12381.Fr
+	aPoly      *poly.Polynomial
+	ePoly      *poly.Polynomial
+	delta0Poly [][]*poly.Polynomial
+	delta1Poly []*poly.Polynomial
+}
+
+// NewSeparateBBSTupleGenerator returns a new SeparateBBSTupleGenerator for a tau-out-of-n scheme.
+func NewSeparateBBSTupleGenerator(ownIndex, tau int, usk, uv *poly.Polynomial, SkShare *bls12381.Fr, APoly, EPoly *poly.Polynomial, Delta0Poly [][]*poly.Polynomial, Delta1Poly []*poly.Polynomial) *SeparateBBSTupleGenerator {
+	return &SeparateBBSTupleGenerator{
+		ownIndex:   ownIndex,
+		tau:        tau,
+		usk:        usk,
+		uv:         uv,
+		skShare:    SkShare,
+		aPoly:      APoly,
+		ePoly:      EPoly,
+		delta0Poly: Delta0Poly,
+		delta1Poly: Delta1Poly,
+	}
+}
+
+func (t *SeparateBBSTupleGenerator) OwnIndex() int {
+	return t.ownIndex
+}
+
+// GenBBSTuple returns a BBSTuple from a SeparateBBSTupleGenerator for a given root.
+// signerSet is the set of signers that are participating. It must contain ownIndex.
+// The returned SkShare is the plain secret key share, the Lagrange coefficients of signerSet are already applied to
+// the share of delta.
+func (t *SeparateBBSTupleGenerator) GenBBSTuple(root *bls12381.Fr, signerSet []int) *BBSTuple {
+	// Shamir shares are evaluated at index + 1.
+	shamirIndices := make([]int, len(signerSet))
+	ownPosition := -1
+	for k, signer := range signerSet {
+		shamirIndices[k] = signer + 1
+		if signer == t.ownIndex {
+			ownPosition = k
+		}
+	}
+	if ownPosition < 0 {
+		return nil
+	}
+	var lagrangeCoeff []*bls12381.Fr
+	if t.tau == len(t.delta1Poly) {
+		// Additive sharing, all parties must sign.
+		if len(signerSet) != t.tau {
+			return nil
+		}
+		lagrangeCoeff = make([]*bls12381.Fr, len(signerSet))
+		for k := range lagrangeCoeff {
+			lagrangeCoeff[k] = bls12381.NewFr().One()
+		}
+	} else {
+		lagrangeCoeff = helper.Get0LagrangeCoefficientSetFr(shamirIndices)
+	}
+
+	// Calculate a_i and e_i
+	aiElement := t.aPoly.Evaluate(root)
+	eiElement := t.ePoly.Evaluate(root)
+
+	// Calculate delta_0i = L_i * (a_i*sk_i + sum_j c_ji) + sum_j L_j * c_ij, where c_ij is the share of a_i*sk_j
+	delta0Own := t.usk.Evaluate(root)
+	delta0Fwd := bls12381.NewFr().Zero()
+	for k, signer := range signerSet {
+		if signer != t.ownIndex {
+			delta0Own.Add(delta0Own, t.delta0Poly[signer][backwardDirection].Evaluate(root))
+
+			fwd := t.delta0Poly[signer][forwardDirection].Evaluate(root)
+			fwd.Mul(fwd, lagrangeCoeff[k])
+			delta0Fwd.Add(delta0Fwd, fwd)
+		}
+	}
+	delta0Own.Mul(delta0Own, lagrangeCoeff[ownPosition])
+
+	// Calculate delta_1i = a_i*e_i + sum_j (a_i*e_j + a_j*e_i)
+	delta1i := t.uv.Evaluate(root)
+	for _, signer := range signerSet {
+		if signer != t.ownIndex {
+			delta1i.Add(delta1i, t.delta1Poly[signer].Evaluate(root))
+		}
+	}
+
+	deltaiElement := bls12381.NewFr()
+	deltaiElement.Add(delta0Own, delta0Fwd)
+	deltaiElement.Add(deltaiElement, delta1i)
+
+	tuple := NewBBSTuple(t.skShare, aiElement, eiElement, deltaiElement)
+	tuple.Epoch = t.epoch
+	return tuple
+}
+
+// BBSTuple is a share of a pre-computed BBS signature generated by the EvalCombinedBBS or EvalSeparateBBS function
+// of the PCG. Compared to BBSPlusTuple it has no share of s and alpha.
+type BBSTuple struct {
+	SkShare    *bls12381.Fr
+	AShare     *bls12381.Fr
+	EShare     *bls12381.Fr
+	DeltaShare *bls12381.Fr
+	Epoch      uint64 // Key epoch of the seed the tuple is generated from.
+}
+
+// NewBBSTuple returns a BBSTuple holding copies of the given shares.
+func NewBBSTuple(SkShare, AShare, EShare, DeltaShare *bls12381.Fr) *BBSTuple {
+	return &BBSTuple{
+		SkShare:    bls12381.NewFr().Set(SkShare),
+		AShare:     bls12381.NewFr().Set(AShare),
+		EShare:     bls12381.NewFr().Set(EShare),
+		DeltaShare: bls12381.NewFr().Set(DeltaShare),
+	}
+}
+
+func (t *BBSTuple) GetEpoch() uint64 {
+	return t.Epoch
+}
+
+// Serialize converts a BBSTuple into a byte slice.
+func (t *BBSTuple) Serialize() ([]byte, error) {
+	var b bytes.Buffer
+	encoder := gob.NewEncoder(&b)
+
+	for _, share := range []*bls12381.Fr{t.SkShare, t.AShare, t.EShare, t.DeltaShare} {
+		if err := encoder.Encode(share.ToBytes()); err != nil {
+			return nil, err
+		}
+	}
+	if err := encoder.Encode(t.Epoch); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// Deserialize converts a byte slice into a BBSTuple.
+func (t *BBSTuple) Deserialize(data []byte) error {
+	b := bytes.NewBuffer(data)
+	decoder := gob.NewDecoder(b)
+
+	shares := make([]*bls12381.Fr, 4)
+	for i := range shares {
+		var shareBytes []byte
+		if err := decoder.Decode(&shareBytes); err != nil {
+			return err
+		}
+		shares[i] = bls12381.NewFr().FromBytes(shareBytes)
+	}
+	t.SkShare, t.AShare, t.EShare, t.DeltaShare = shares[0], shares[1], shares[2], shares[3]
+
+	if err := decoder.Decode(&t.Epoch); err != nil {
+		return err
+	}
+
+	return nil
+}
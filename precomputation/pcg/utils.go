@@ -2,6 +2,7 @@ package pcg
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"math/big"
 	"math/rand"
@@ -130,64 +131,53 @@ func outerProductFr(a, b []*bls12381.Fr) []*bls12381.Fr {
 	return result
 }
 
-// outerProductPoly calculates the outer product of two slices of *poly.Polynomial.
-// The function is implemented using a worker pool to handle large polynomials.
-func outerProductPoly(a, b []*poly.Polynomial) ([]*poly.Polynomial, error) {
-	numCores := runtime.NumCPU()
-	tasks := make(chan polyTask, numCores)
-	results := make(chan polyResult, len(a)*len(b))
-	errs := make(chan error, 1)
-
-	// Worker function for polynomial multiplication
-	var wg sync.WaitGroup
-	worker := func() {
-		defer wg.Done()
-		for task := range tasks {
-			prod, err := poly.Mul(task.aPoly, task.bPoly)
-			if err != nil {
-				errs <- err
-				return
-			}
-			results <- polyResult{task.aIndex, task.bIndex, prod}
-		}
+// outerProductPoly calculates the outer product of two slices of *poly.Polynomial in the ring defined by div.
+// The products are returned in the evaluation form of the ring (see poly.NegacyclicRing), in which they are used by
+// evalFinalShare2D, s.t. only the factors have to be transformed.
+func outerProductPoly(a, b []*poly.Polynomial, div *poly.Polynomial) ([]*poly.Dense, error) {
+	ring, err := poly.NewNegacyclicRingFromDivisor(div)
+	if err != nil {
+		return nil, err
 	}
-
-	// Start workers
-	for i := 0; i < numCores; i++ {
-		wg.Add(1)
-		go worker()
+	aEvals, err := evaluationForm(ring, a)
+	if err != nil {
+		return nil, err
+	}
+	bEvals, err := evaluationForm(ring, b)
+	if err != nil {
+		return nil, err
 	}
 
-	// Distribute tasks
-	go func() {
-		for i, aPoly := range a {
-			for j, bPoly := range b {
-				tasks <- polyTask{i, j, aPoly, bPoly}
+	res := make([]*poly.Dense, len(a)*len(b))
+	for i, aEval := range aEvals {
+		for j, bEval := range bEvals {
+			prod := aEval.DeepCopy()
+			if err := prod.MulPointwise(bEval); err != nil {
+				return nil, err
 			}
+			res[i*len(b)+j] = prod
 		}
-		close(tasks)
-	}()
-
-	// Wait for workers to complete and close results channel
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
-
-	// Collect results
-	res := make([]*poly.Polynomial, len(a)*len(b))
-	for result := range results {
-		i, j := result.aIndex, result.bIndex
-		res[i*len(b)+j] = result.product
 	}
+	return res, nil
+}
 
-	// Check for errors
-	select {
-	case err := <-errs:
+// evaluationForm reduces the polynomials in the ring and transforms them into its evaluation form in parallel.
+func evaluationForm(ring *poly.NegacyclicRing, polys []*poly.Polynomial) ([]*poly.Dense, error) {
+	res := make([]*poly.Dense, len(polys))
+	errs := make([]error, len(polys))
+	var wg sync.WaitGroup
+	for i, p := range polys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res[i] = ring.Reduce(p)
+			errs[i] = ring.Forward(res[i])
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
 		return nil, err
-	default:
 	}
-
 	return res, nil
 }
 
@@ -201,36 +191,6 @@ func scalarMulFr(scalar *bls12381.Fr, vector []*bls12381.Fr) []*bls12381.Fr {
 	return result
 }
 
-// init3DSliceBigInt initializes a 3D slice of *big.Int
-func init3DSliceBigInt(n, m, p int) [][][]*big.Int {
-	slice := make([][][]*big.Int, n)
-	for i := range slice {
-		slice[i] = make([][]*big.Int, m)
-		for j := range slice[i] {
-			slice[i][j] = make([]*big.Int, p)
-			for k := range slice[i][j] {
-				slice[i][j][k] = new(big.Int)
-			}
-		}
-	}
-	return slice
-}
-
-// init3DSliceFr initializes a 3D slice of *bls12381.Fr
-func init3DSliceFr(n, m, p int) [][][]*bls12381.Fr {
-	slice := make([][][]*bls12381.Fr, n)
-	for i := range slice {
-		slice[i] = make([][]*bls12381.Fr, m)
-		for j := range slice[i] {
-			slice[i][j] = make([]*bls12381.Fr, p)
-			for k := range slice[i][j] {
-				slice[i][j][k] = bls12381.NewFr()
-			}
-		}
-	}
-	return slice
-}
-
 // init3DSliceDspfKey initializes a 3D slice of *DSPFKeyPair
 func init3DSliceDspfKey(n, m, p int) [][][]*DSPFKeyPair {
 	slice := make([][][]*DSPFKeyPair, n)
@@ -307,39 +267,43 @@ type Ring struct {
 	Roots []*bls12381.Fr
 }
 
-// evalFinalShareTask represents a task for the eval2D function.
+// evalFinalShareTask represents a task for the evalFinalShare and evalFinalShare2D functions.
 type evalFinalShareTask struct {
-	j, k        int
-	oprand      *poly.Polynomial
-	wPoly       *poly.Polynomial
-	div         *poly.Polynomial
-	isLastIndex bool
+	oprand *poly.Dense // Random polynomial in evaluation form
+	wPoly  *poly.Polynomial
 }
 
-// evalFinalShareResult represents the result of the eval2D function.
+// evalFinalShareResult represents the result of the evalFinalShare and evalFinalShare2D functions.
 type evalFinalShareResult struct {
-	poly *poly.Polynomial
-	err  error
+	product *poly.Dense // Product in evaluation form
+	err     error
 }
 
-// polyTask represents a task for the polynomial multiplication.
-type polyTask struct {
-	aIndex int
-	bIndex int
-	aPoly  *poly.Polynomial
-	bPoly  *poly.Polynomial
-}
-
-// polyResult represents the result of the polynomial multiplication.
-type polyResult struct {
-	aIndex  int
-	bIndex  int
-	product *poly.Polynomial
+// product calculates the product of the task in the evaluation form of the ring.
+func (task evalFinalShareTask) product(ring *poly.NegacyclicRing) evalFinalShareResult {
+	prod := ring.Reduce(task.wPoly)
+	if err := ring.Forward(prod); err != nil {
+		return evalFinalShareResult{nil, err}
+	}
+	if err := prod.MulPointwise(task.oprand); err != nil {
+		return evalFinalShareResult{nil, err}
+	}
+	return evalFinalShareResult{prod, nil}
 }
 
 // evalFinalShare evaluates the final share of the PCG for the given polynomial.
 // This function effectively calculates the inner product between the given polynomial and the random polynomials in div.
+// The products are summed up in evaluation form, s.t. only a single inverse NTT is needed.
 func (p *PCG) evalFinalShare(u, rand []*poly.Polynomial, div *poly.Polynomial) (*poly.Polynomial, error) {
+	ring, err := poly.NewNegacyclicRingFromDivisor(div)
+	if err != nil {
+		return nil, err
+	}
+	randEvals, err := evaluationForm(ring, rand[:p.c])
+	if err != nil {
+		return nil, err
+	}
+
 	numCores := runtime.NumCPU()
 	tasks := make(chan evalFinalShareTask, numCores)
 	results := make(chan evalFinalShareResult, p.c)
@@ -348,14 +312,7 @@ func (p *PCG) evalFinalShare(u, rand []*poly.Polynomial, div *poly.Polynomial) (
 	worker := func() {
 		defer wg.Done()
 		for task := range tasks {
-			prod, err := poly.Mul(task.oprand, task.wPoly)
-			if err != nil {
-				results <- evalFinalShareResult{nil, err}
-				return
-			}
-
-			remainder, err := prod.Mod(div)
-			results <- evalFinalShareResult{remainder, err}
+			results <- task.product(ring)
 		}
 	}
 
@@ -366,7 +323,7 @@ func (p *PCG) evalFinalShare(u, rand []*poly.Polynomial, div *poly.Polynomial) (
 
 	go func() {
 		for r := 0; r < p.c; r++ {
-			tasks <- evalFinalShareTask{0, 0, rand[r], u[r], div, false} // Indices and isLastIndex are not used here
+			tasks <- evalFinalShareTask{randEvals[r], u[r]}
 		}
 		close(tasks)
 	}()
@@ -376,21 +333,33 @@ func (p *PCG) evalFinalShare(u, rand []*poly.Polynomial, div *poly.Polynomial) (
 		close(results)
 	}()
 
-	ai := poly.NewEmpty()
-	for i := 0; i < p.c; i++ {
-		result := <-results
+	ai := poly.NewDense(ring.Degree())
+	for result := range results {
 		if result.err != nil {
-			return nil, result.err
+			err = result.err
+			continue
 		}
-		ai.Add(result.poly)
+		ai.Add(result.product)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return ai, nil
+	if err := ring.Inverse(ai); err != nil {
+		return nil, err
+	}
+	return ai.ToPolynomial(), nil
 }
 
 // evalFinalShare2D evaluates the final share of the PCG for the given polynomial.
-// This function effectively calculates the inner product between the given polynomial and the random polynomials in div.
-func (p *PCG) evalFinalShare2D(w [][]*poly.Polynomial, oprand []*poly.Polynomial, div *poly.Polynomial) (*poly.Polynomial, error) {
+// This function effectively calculates the inner product between the given polynomial and the outer product of the
+// random polynomials in div, where oprand is the outer product in evaluation form as returned by outerProductPoly.
+func (p *PCG) evalFinalShare2D(w [][]*poly.Polynomial, oprand []*poly.Dense, div *poly.Polynomial) (*poly.Polynomial, error) {
+	ring, err := poly.NewNegacyclicRingFromDivisor(div)
+	if err != nil {
+		return nil, err
+	}
+
 	numCores := runtime.NumCPU()
 	tasks := make(chan evalFinalShareTask, numCores)
 	results := make(chan evalFinalShareResult, numCores)
@@ -399,19 +368,7 @@ func (p *PCG) evalFinalShare2D(w [][]*poly.Polynomial, oprand []*poly.Polynomial
 	worker := func() {
 		defer wg.Done()
 		for task := range tasks {
-			var result evalFinalShareResult
-			if task.isLastIndex {
-				remainder, err := task.wPoly.Mod(task.div)
-				result = evalFinalShareResult{remainder, err}
-			} else {
-				prod, err := poly.Mul(task.oprand, task.wPoly)
-				if err != nil {
-					results <- evalFinalShareResult{nil, err}
-					return
-				}
-				result = evalFinalShareResult{prod, err}
-			}
-			results <- result
+			results <- task.product(ring)
 		}
 	}
 
@@ -423,10 +380,7 @@ func (p *PCG) evalFinalShare2D(w [][]*poly.Polynomial, oprand []*poly.Polynomial
 	go func() {
 		for j := 0; j < p.c; j++ {
 			for k := 0; k < p.c; k++ {
-				currentIndex := j*p.c + k
-				isLastIndex := currentIndex == p.c*p.c-1
-				task := evalFinalShareTask{j, k, oprand[currentIndex], w[j][k], div, isLastIndex}
-				tasks <- task
+				tasks <- evalFinalShareTask{oprand[j*p.c+k], w[j][k]}
 			}
 		}
 		close(tasks)
@@ -437,39 +391,39 @@ func (p *PCG) evalFinalShare2D(w [][]*poly.Polynomial, oprand []*poly.Polynomial
 		close(results)
 	}()
 
-	alphai := poly.NewEmpty()
-	for range w {
-		for range w[0] {
-			result := <-results
-			if result.err != nil {
-				return nil, result.err
-			}
-			alphai.Add(result.poly)
+	alphai := poly.NewDense(ring.Degree())
+	for result := range results {
+		if result.err != nil {
+			err = result.err
+			continue
 		}
+		alphai.Add(result.product)
 	}
-
-	alphai, err := alphai.Mod(div)
 	if err != nil {
 		return nil, err
 	}
-	return alphai, nil
+
+	if err := ring.Inverse(alphai); err != nil {
+		return nil, err
+	}
+	return alphai.ToPolynomial(), nil
 }
 
 // evalVOLEwithSeed evaluates the VOLE correlation with the given seed.
-func (p *PCG) evalVOLEwithSeed(u []*poly.Polynomial, seedSk *bls12381.Fr, seedDSPFKeys [][][]*DSPFKeyPair, seedIndex int) ([]*poly.Polynomial, error) {
+func (p *PCG) evalVOLEwithSeed(u []*poly.Polynomial, seedSk *bls12381.Fr, seedDSPFKeys [][]*DSPFKeyPair, seedIndex int) ([]*poly.Polynomial, error) {
 	utilde := make([]*poly.Polynomial, p.c)
 	for r := 0; r < p.c; r++ {
 		ur := u[r].DeepCopy()    // We need unmodified u[r] later on, so we copy it
 		ur.MulByConstant(seedSk) // u[r] * sk[i]
 		for j := 0; j < p.n; j++ {
 			if seedIndex != j {
-				eval0, err := p.dspfN.FullEvalFastAggregated(seedDSPFKeys[seedIndex][j][r].Key0)
+				eval0, err := p.dspfN.FullEvalFastAggregated(seedDSPFKeys[j][r].Key0)
 				if err != nil {
 					return nil, err
 				}
 				ur.Add(poly.NewFromFr(eval0))
 
-				eval1, err := p.dspfN.FullEvalFastAggregated(seedDSPFKeys[j][seedIndex][r].Key1)
+				eval1, err := p.dspfN.FullEvalFastAggregated(seedDSPFKeys[j][r].Key1)
 				if err != nil {
 					return nil, err
 				}
@@ -482,7 +436,7 @@ func (p *PCG) evalVOLEwithSeed(u []*poly.Polynomial, seedSk *bls12381.Fr, seedDS
 }
 
 // evalOLEwithSeed evaluates the OLE correlation with the given seed.
-func (p *PCG) evalOLEwithSeed(u, v []*poly.Polynomial, seedDSPFKeys [][][][]*DSPFKeyPair, seedIndex int) ([][]*poly.Polynomial, error) {
+func (p *PCG) evalOLEwithSeed(u, v []*poly.Polynomial, seedDSPFKeys [][][]*DSPFKeyPair, seedIndex int) ([][]*poly.Polynomial, error) {
 	w := make([][]*poly.Polynomial, p.c)
 	for r := 0; r < p.c; r++ {
 		w[r] = make([]*poly.Polynomial, p.c)
@@ -494,13 +448,13 @@ func (p *PCG) evalOLEwithSeed(u, v []*poly.Polynomial, seedDSPFKeys [][][][]*DSP
 			}
 			for j := 0; j < p.n; j++ {
 				if seedIndex != j { // Ony cross terms
-					eval0, err := p.dspf2N.FullEvalFastAggregated(seedDSPFKeys[seedIndex][j][r][s].Key0)
+					eval0, err := p.dspf2N.FullEvalFastAggregated(seedDSPFKeys[j][r][s].Key0)
 					if err != nil {
 						return nil, err
 					}
 					w[r][s].Add(poly.NewFromFr(eval0)) // N
 
-					eval1, err := p.dspf2N.FullEvalFastAggregated(seedDSPFKeys[j][seedIndex][r][s].Key1)
+					eval1, err := p.dspf2N.FullEvalFastAggregated(seedDSPFKeys[j][r][s].Key1)
 					if err != nil {
 						return nil, err
 					}
@@ -514,7 +468,7 @@ func (p *PCG) evalOLEwithSeed(u, v []*poly.Polynomial, seedDSPFKeys [][][][]*DSP
 
 // evalVOLEwithSeed evaluates the VOLE correlation with the given seed.
 // Poly out is structured as: [j][direction][r], where j is the counter-parties index, direction is 0 for forward and 1 for backward and where r is in c.
-func (p *PCG) evalVOLEwithSeedSeparate(seedDSPFKeys [][][]*DSPFKeyPair, seedIndex int) ([][][]*poly.Polynomial, error) {
+func (p *PCG) evalVOLEwithSeedSeparate(seedDSPFKeys [][]*DSPFKeyPair, seedIndex int) ([][][]*poly.Polynomial, error) {
 	utilde := make([][][]*poly.Polynomial, p.n)
 	for j := 0; j < p.n; j++ {
 		if seedIndex != j {
@@ -522,13 +476,13 @@ func (p *PCG) evalVOLEwithSeedSeparate(seedDSPFKeys [][][]*DSPFKeyPair, seedInde
 			utilde[j][forwardDirection] = make([]*poly.Polynomial, p.c)
 			utilde[j][backwardDirection] = make([]*poly.Polynomial, p.c)
 			for r := 0; r < p.c; r++ {
-				eval0, err := p.dspfN.FullEvalFastAggregated(seedDSPFKeys[seedIndex][j][r].Key0)
+				eval0, err := p.dspfN.FullEvalFastAggregated(seedDSPFKeys[j][r].Key0)
 				if err != nil {
 					return nil, err
 				}
 				utilde[j][forwardDirection][r] = poly.NewFromFr(eval0)
 
-				eval1, err := p.dspfN.FullEvalFastAggregated(seedDSPFKeys[j][seedIndex][r].Key1)
+				eval1, err := p.dspfN.FullEvalFastAggregated(seedDSPFKeys[j][r].Key1)
 				if err != nil {
 					return nil, err
 				}
@@ -541,7 +495,7 @@ func (p *PCG) evalVOLEwithSeedSeparate(seedDSPFKeys [][][]*DSPFKeyPair, seedInde
 
 // evalOLEwithSeed evaluates the OLE correlation with the given seed.
 // Poly out is structured as: [j][r][s], where j is the counter-parties index and r and s are in c.
-func (p *PCG) evalOLEwithSeedSeparate(u, v []*poly.Polynomial, seedDSPFKeys [][][][]*DSPFKeyPair, seedIndex int) ([][][]*poly.Polynomial, [][]*poly.Polynomial, error) {
+func (p *PCG) evalOLEwithSeedSeparate(u, v []*poly.Polynomial, seedDSPFKeys [][][]*DSPFKeyPair, seedIndex int) ([][][]*poly.Polynomial, [][]*poly.Polynomial, error) {
 	w := make([][][]*poly.Polynomial, p.n)
 	uv := make([][]*poly.Polynomial, p.c)
 	for j := 0; j < p.n; j++ {
@@ -551,13 +505,13 @@ func (p *PCG) evalOLEwithSeedSeparate(u, v []*poly.Polynomial, seedDSPFKeys [][]
 				w[j][r] = make([]*poly.Polynomial, p.c)
 				uv[r] = make([]*poly.Polynomial, p.c)
 				for s := 0; s < p.c; s++ {
-					eval0, err := p.dspf2N.FullEvalFastAggregated(seedDSPFKeys[seedIndex][j][r][s].Key0)
+					eval0, err := p.dspf2N.FullEvalFastAggregated(seedDSPFKeys[j][r][s].Key0)
 					if err != nil {
 						return nil, nil, err
 					}
 					w[j][r][s] = poly.NewFromFr(eval0)
 
-					eval1, err := p.dspf2N.FullEvalFastAggregated(seedDSPFKeys[j][seedIndex][r][s].Key1)
+					eval1, err := p.dspf2N.FullEvalFastAggregated(seedDSPFKeys[j][r][s].Key1)
 					if err != nil {
 						return nil, nil, err
 					}
@@ -630,32 +584,46 @@ func (p *PCG) embedOLECorrelations(omega, o [][][]*big.Int, beta, b [][][]*bls12
 
 // sampleExponents samples values later used as poly exponents by picking p.n*p.c random t-vectors from N.
 func (p *PCG) sampleExponents() [][][]*big.Int {
-	exp := init3DSliceBigInt(p.n, p.c, p.t)
+	exp := make([][][]*big.Int, p.n)
 	for i := 0; i < p.n; i++ {
-		for j := 0; j < p.c; j++ {
-			vec := p.sampleTUniqueExponents()
-			sort.Slice(vec, func(i, j int) bool {
-				return vec[i].Cmp(vec[j]) < 0
-			})
-			exp[i][j] = vec
-		}
+		exp[i] = p.sampleOwnExponents()
+	}
+	return exp
+}
+
+// sampleOwnExponents samples the exponents of a single party by picking p.c random t-vectors from N.
+func (p *PCG) sampleOwnExponents() [][]*big.Int {
+	exp := make([][]*big.Int, p.c)
+	for j := 0; j < p.c; j++ {
+		vec := p.sampleTUniqueExponents()
+		sort.Slice(vec, func(i, j int) bool {
+			return vec[i].Cmp(vec[j]) < 0
+		})
+		exp[j] = vec
 	}
 	return exp
 }
 
 // sampleCoefficients samples values later used as poly coefficients by picking p.n*p.c random t-vectors from Fq.
 func (p *PCG) sampleCoefficients() [][][]*bls12381.Fr {
-	exp := init3DSliceFr(p.n, p.c, p.t)
+	exp := make([][][]*bls12381.Fr, p.n)
 	for i := 0; i < p.n; i++ {
-		for j := 0; j < p.c; j++ {
-			vec := make([]*bls12381.Fr, p.t)
-			for t := range vec {
-				randElement, _ := bls12381.NewFr().Rand(p.rng)
-				vec[t] = bls12381.NewFr()
-				vec[t].Set(randElement)
-			}
-			exp[i][j] = vec
+		exp[i] = p.sampleOwnCoefficients()
+	}
+	return exp
+}
+
+// sampleOwnCoefficients samples the coefficients of a single party by picking p.c random t-vectors from Fq.
+func (p *PCG) sampleOwnCoefficients() [][]*bls12381.Fr {
+	exp := make([][]*bls12381.Fr, p.c)
+	for j := 0; j < p.c; j++ {
+		vec := make([]*bls12381.Fr, p.t)
+		for t := range vec {
+			randElement, _ := bls12381.NewFr().Rand(p.rng)
+			vec[t] = bls12381.NewFr()
+			vec[t].Set(randElement)
 		}
+		exp[j] = vec
 	}
 	return exp
 }
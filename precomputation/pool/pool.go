@@ -0,0 +1,393 @@
+// Package pool keeps a supply of pre-signatures for the online phase of threshold signing.
+//
+// A Pool hands out the pre-signatures of one party for every registered key. Pre-signatures are drawn from batches of
+// ring roots of an evaluated PCG seed. Once fewer than the low watermark of pre-signatures remain for a key, the Pool
+// asks the Replenisher of the key for a new batch in the background, so that signing does not wait for the expensive
+// PCG evaluation.
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	bls12381 "github.com/kilic/bls12-381"
+
+	fhksbbsplus "github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
+	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation/pcg"
+)
+
+var (
+	// ErrUnknownKey is returned for keys that are not registered.
+	ErrUnknownKey = errors.New("unknown key")
+	// ErrUnavailable is returned if a requested pre-signature was already taken or discarded.
+	ErrUnavailable = errors.New("pre-signature not available")
+	// ErrClosed is returned after the Pool was closed.
+	ErrClosed = errors.New("pool closed")
+)
+
+// TupleGenerator derives the tuple of a party at a root of the ring. It is implemented by
+// *pcg.SeparateBBSPlusTupleGenerator.
+type TupleGenerator interface {
+	GenBBSPlusTuple(root *bls12381.Fr, signerSet []int) *pcg.BBSPlusTuple
+}
+
+// Batch is a range of pre-signatures of one seed epoch. The pre-signature at Roots[i] has index FirstIndex + i.
+type Batch struct {
+	Epoch      uint64
+	Generator  TupleGenerator
+	FirstIndex int
+	Roots      []*bls12381.Fr
+}
+
+// Replenisher produces the batch following the pre-signature at index last of epoch, which is either a further range
+// of roots of the same seed or the first range of a seed of a later epoch. last is -1 if the key has no batch of epoch
+// yet, i.e., for the first batch and after DiscardStale dropped the pre-signatures before epoch. The batch must then
+// belong to epoch or a later one.
+type Replenisher func(ctx context.Context, keyID string, epoch uint64, last int) (*Batch, error)
+
+// EventKind is the type of an Event.
+type EventKind int
+
+const (
+	// EventLowWatermark is emitted when the remaining pre-signatures of a key fall below the low watermark.
+	EventLowWatermark EventKind = iota
+	// EventReplenished is emitted after a batch was added.
+	EventReplenished
+	// EventReplenishFailed is emitted if the Replenisher returned an error, which is stored in Event.Err.
+	EventReplenishFailed
+)
+
+// Event notifies about the state of the pre-signatures of a key.
+type Event struct {
+	Kind      EventKind
+	KeyID     string
+	Remaining int   // Pre-signatures left after the event.
+	Err       error // Set for EventReplenishFailed.
+}
+
+// PreSignature is a pre-signature taken from the Pool. Epoch and Index identify it across all parties and are
+// the arguments for fhks_bbs_plus.PreSignatureStore.
+type PreSignature struct {
+	Epoch uint64
+	Index int
+	Live  *fhksbbsplus.LivePreSignature
+}
+
+// Config configures a key of the Pool.
+type Config struct {
+	LowWatermark int         // Replenishment starts once fewer pre-signatures remain.
+	Replenish    Replenisher // Produces new batches.
+}
+
+// Pool manages the pre-signatures of one party.
+type Pool struct {
+	mu      sync.Mutex
+	changed *sync.Cond // Broadcast whenever batches are added or a replenishment ends.
+	keys    map[string]*keyPool
+	onEvent func(Event)
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	closed  bool
+}
+
+type keyPool struct {
+	config       Config
+	batches      []*batch
+	minEpoch     uint64 // Batches of earlier epochs are rejected, see DiscardStale.
+	lastEpoch    uint64
+	lastIndex    int
+	replenishing bool
+	err          error // Error of the last replenishment, cleared by the next successful one.
+}
+
+type batch struct {
+	Batch
+	used      []bool
+	remaining int
+}
+
+// New creates a Pool. onEvent is called for every Event and may be nil. It is called without holding locks of the
+// Pool but must not block for long.
+func New(onEvent func(Event)) *Pool {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pool{
+		keys:    make(map[string]*keyPool),
+		onEvent: onEvent,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	p.changed = sync.NewCond(&p.mu)
+	return p
+}
+
+// Register adds a key and starts filling its pre-signatures.
+func (p *Pool) Register(keyID string, config Config) error {
+	if config.Replenish == nil {
+		return errors.New("replenisher must not be nil")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return ErrClosed
+	}
+	if _, ok := p.keys[keyID]; ok {
+		return fmt.Errorf("key %q already registered", keyID)
+	}
+	p.keys[keyID] = &keyPool{config: config, lastIndex: -1}
+	p.startReplenish(keyID)
+	return nil
+}
+
+// Remaining returns the number of pre-signatures left for keyID.
+func (p *Pool) Remaining(keyID string) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	kp, ok := p.keys[keyID]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownKey, keyID)
+	}
+	return kp.remaining(), nil
+}
+
+// Take hands out the first unused pre-signature of keyID for the signers in signerSet. If none is left, it waits for
+// a replenishment until ctx is done or the replenishment fails.
+func (p *Pool) Take(ctx context.Context, keyID string, signerSet []int) (*PreSignature, error) {
+	stop := context.AfterFunc(ctx, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.changed.Broadcast()
+	})
+	defer stop()
+
+	p.mu.Lock()
+	for {
+		if p.closed {
+			p.mu.Unlock()
+			return nil, ErrClosed
+		}
+		kp, ok := p.keys[keyID]
+		if !ok {
+			p.mu.Unlock()
+			return nil, fmt.Errorf("%w: %q", ErrUnknownKey, keyID)
+		}
+		for _, b := range kp.batches {
+			for i, used := range b.used {
+				if !used {
+					return p.take(keyID, kp, b, i, signerSet)
+				}
+			}
+		}
+		if !kp.replenishing && kp.err != nil {
+			err := kp.err
+			p.startReplenish(keyID) // Retry for later calls.
+			p.mu.Unlock()
+			return nil, fmt.Errorf("no pre-signatures left: %w", err)
+		}
+		p.startReplenish(keyID)
+		if err := ctx.Err(); err != nil {
+			p.mu.Unlock()
+			return nil, err
+		}
+		p.changed.Wait()
+	}
+}
+
+// TakeIndex hands out the pre-signature with the given epoch and index, e.g. as requested by the coordinator of a
+// signing session. It returns ErrUnavailable if the pre-signature was already taken, discarded or not yet produced.
+func (p *Pool) TakeIndex(keyID string, epoch uint64, index int, signerSet []int) (*PreSignature, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, ErrClosed
+	}
+	kp, ok := p.keys[keyID]
+	if !ok {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, keyID)
+	}
+	for _, b := range kp.batches {
+		i := index - b.FirstIndex
+		if b.Epoch == epoch && i >= 0 && i < len(b.Roots) && !b.used[i] {
+			return p.take(keyID, kp, b, i, signerSet)
+		}
+	}
+	p.mu.Unlock()
+	return nil, fmt.Errorf("%w: index %d of epoch %d", ErrUnavailable, index, epoch)
+}
+
+// take marks the pre-signature as used, releases the lock and derives the pre-signature.
+func (p *Pool) take(keyID string, kp *keyPool, b *batch, i int, signerSet []int) (*PreSignature, error) {
+	b.used[i] = true
+	b.remaining--
+	kp.dropUsedBatches()
+	remaining := kp.remaining()
+	low := remaining < kp.config.LowWatermark && !kp.replenishing
+	if low {
+		p.startReplenish(keyID)
+	}
+	p.mu.Unlock()
+
+	if low {
+		p.emit(Event{Kind: EventLowWatermark, KeyID: keyID, Remaining: remaining})
+	}
+
+	tuple := b.Generator.GenBBSPlusTuple(b.Roots[i], signerSet)
+	if tuple == nil {
+		return nil, errors.New("own index not in signer set")
+	}
+	return &PreSignature{
+		Epoch: b.Epoch,
+		Index: b.FirstIndex + i,
+		Live:  tuple.LivePreSignature(),
+	}, nil
+}
+
+// DiscardStale drops the pre-signatures of keyID from epochs before epoch, e.g. after the key shares were refreshed.
+// Batches of earlier epochs are rejected afterwards and the Replenisher is asked for the first batch of epoch.
+func (p *Pool) DiscardStale(keyID string, epoch uint64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	kp, ok := p.keys[keyID]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownKey, keyID)
+	}
+	batches := kp.batches[:0]
+	for _, b := range kp.batches {
+		if b.Epoch >= epoch {
+			batches = append(batches, b)
+		}
+	}
+	kp.batches = batches
+	kp.minEpoch = max(kp.minEpoch, epoch)
+	if kp.lastEpoch < kp.minEpoch {
+		kp.lastEpoch, kp.lastIndex = kp.minEpoch, -1
+	}
+	if kp.remaining() < kp.config.LowWatermark {
+		p.startReplenish(keyID)
+	}
+	return nil
+}
+
+// Close stops all replenishments and waits for them to return.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	p.closed = true
+	p.changed.Broadcast()
+	p.mu.Unlock()
+
+	p.cancel()
+	p.wg.Wait()
+}
+
+// startReplenish starts a replenishment of keyID unless one is running. p.mu must be held.
+func (p *Pool) startReplenish(keyID string) {
+	kp := p.keys[keyID]
+	if kp.replenishing || p.closed {
+		return
+	}
+	kp.replenishing = true
+	p.wg.Add(1)
+	go p.replenish(keyID, kp)
+}
+
+// replenish requests batches until the low watermark is reached again.
+func (p *Pool) replenish(keyID string, kp *keyPool) {
+	defer p.wg.Done()
+
+	p.mu.Lock()
+	for {
+		epoch, last := kp.lastEpoch, kp.lastIndex
+		p.mu.Unlock()
+
+		next, err := kp.config.Replenish(p.ctx, keyID, epoch, last)
+
+		p.mu.Lock()
+		if err == nil && next != nil && next.Epoch < kp.minEpoch && (kp.lastEpoch != epoch || kp.lastIndex != last) {
+			// DiscardStale dropped the epoch of the batch while it was produced, request the first batch of the new
+			// epoch instead.
+			continue
+		}
+		if err == nil {
+			err = checkBatch(next, kp.lastEpoch, kp.lastIndex, kp.minEpoch)
+		}
+		if err != nil {
+			kp.err = err
+			kp.replenishing = false
+			remaining := kp.remaining()
+			p.changed.Broadcast()
+			p.mu.Unlock()
+			if p.ctx.Err() == nil {
+				p.emit(Event{Kind: EventReplenishFailed, KeyID: keyID, Remaining: remaining, Err: err})
+			}
+			return
+		}
+
+		kp.err = nil
+		kp.batches = append(kp.batches, &batch{Batch: *next, used: make([]bool, len(next.Roots)), remaining: len(next.Roots)})
+		kp.lastEpoch, kp.lastIndex = next.Epoch, next.FirstIndex+len(next.Roots)-1
+		remaining := kp.remaining()
+		p.changed.Broadcast()
+		done := remaining >= kp.config.LowWatermark
+		if done {
+			kp.replenishing = false
+		}
+		p.mu.Unlock()
+
+		p.emit(Event{Kind: EventReplenished, KeyID: keyID, Remaining: remaining})
+
+		p.mu.Lock()
+		if done || p.closed {
+			kp.replenishing = false
+			p.mu.Unlock()
+			return
+		}
+	}
+}
+
+// checkBatch checks that next follows the pre-signature at index last of epoch and is not of an epoch before
+// minEpoch.
+func checkBatch(next *Batch, epoch uint64, last int, minEpoch uint64) error {
+	switch {
+	case next == nil || len(next.Roots) == 0:
+		return errors.New("empty batch")
+	case next.Generator == nil:
+		return errors.New("batch without generator")
+	case next.FirstIndex < 0:
+		return errors.New("negative batch index")
+	case next.Epoch < minEpoch:
+		return fmt.Errorf("batch of epoch %d, but pre-signatures before epoch %d were discarded", next.Epoch, minEpoch)
+	case last >= 0 && next.Epoch < epoch:
+		return fmt.Errorf("batch of epoch %d after epoch %d", next.Epoch, epoch)
+	case last >= 0 && next.Epoch == epoch && next.FirstIndex <= last:
+		return fmt.Errorf("batch starting at %d overlaps pre-signatures up to %d", next.FirstIndex, last)
+	}
+	return nil
+}
+
+func (p *Pool) emit(event Event) {
+	if p.onEvent != nil {
+		p.onEvent(event)
+	}
+}
+
+func (kp *keyPool) remaining() int {
+	remaining := 0
+	for _, b := range kp.batches {
+		remaining += b.remaining
+	}
+	return remaining
+}
+
+func (kp *keyPool) dropUsedBatches() {
+	batches := kp.batches[:0]
+	for _, b := range kp.batches {
+		if b.remaining > 0 {
+			batches = append(batches, b)
+		}
+	}
+	kp.batches = batches
+}
@@ -0,0 +1,235 @@
+package pool_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation/pcg"
+	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation/pool"
+)
+
+// rootGenerator returns tuples whose shares are the root, which identifies the pre-signature in the tests.
+type rootGenerator struct{ epoch uint64 }
+
+func (g rootGenerator) GenBBSPlusTuple(root *bls12381.Fr, _ []int) *pcg.BBSPlusTuple {
+	return &pcg.BBSPlusTuple{AShare: root, EShare: root, SShare: root, AlphaShare: root, DeltaShare: root, Epoch: g.epoch}
+}
+
+// countingReplenisher hands out batches of size roots and moves to the next epoch every epochSize pre-signatures.
+func countingReplenisher(size, epochSize int, calls *int, mu *sync.Mutex) pool.Replenisher {
+	return func(_ context.Context, _ string, epoch uint64, last int) (*pool.Batch, error) {
+		mu.Lock()
+		*calls++
+		mu.Unlock()
+
+		first := last + 1
+		if last >= 0 && first == epochSize {
+			epoch, first = epoch+1, 0
+		}
+		roots := make([]*bls12381.Fr, size)
+		for i := range roots {
+			roots[i] = bls12381.NewFr().FromBytes([]byte{byte(epoch), byte(first + i)})
+		}
+		return &pool.Batch{Epoch: epoch, Generator: rootGenerator{epoch}, FirstIndex: first, Roots: roots}, nil
+	}
+}
+
+func TestPoolReplenishes(t *testing.T) {
+	var mu sync.Mutex
+	var calls int
+	var events []pool.Event
+	p := pool.New(func(event pool.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	})
+	defer p.Close()
+
+	require.NoError(t, p.Register("key", pool.Config{LowWatermark: 3, Replenish: countingReplenisher(2, 4, &calls, &mu)}))
+	_, err := p.Take(context.Background(), "unknown", nil)
+	assert.ErrorIs(t, err, pool.ErrUnknownKey)
+
+	seen := make(map[[2]uint64]bool)
+	for i := 0; i < 10; i++ {
+		preSignature, err := p.Take(context.Background(), "key", nil)
+		require.NoError(t, err)
+		id := [2]uint64{preSignature.Epoch, uint64(preSignature.Index)}
+		assert.False(t, seen[id], "pre-signature handed out twice")
+		seen[id] = true
+		assert.Equal(t, preSignature.Epoch, preSignature.Live.Epoch)
+	}
+	assert.True(t, seen[[2]uint64{1, 3}], "pre-signatures must continue with the next epoch")
+
+	assert.Eventually(t, func() bool {
+		remaining, err := p.Remaining("key")
+		return err == nil && remaining >= 3
+	}, time.Second, time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	kinds := make(map[pool.EventKind]bool)
+	for _, event := range events {
+		kinds[event.Kind] = true
+	}
+	assert.True(t, kinds[pool.EventLowWatermark])
+	assert.True(t, kinds[pool.EventReplenished])
+}
+
+func TestPoolTakeIndex(t *testing.T) {
+	var mu sync.Mutex
+	var calls int
+	p := pool.New(nil)
+	defer p.Close()
+	require.NoError(t, p.Register("key", pool.Config{LowWatermark: 4, Replenish: countingReplenisher(4, 100, &calls, &mu)}))
+
+	require.Eventually(t, func() bool {
+		remaining, _ := p.Remaining("key")
+		return remaining >= 4
+	}, time.Second, time.Millisecond)
+
+	preSignature, err := p.TakeIndex("key", 0, 2, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, preSignature.Index)
+	_, err = p.TakeIndex("key", 0, 2, nil)
+	assert.ErrorIs(t, err, pool.ErrUnavailable)
+
+	preSignature, err = p.Take(context.Background(), "key", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, preSignature.Index)
+
+	require.NoError(t, p.DiscardStale("key", 1))
+	_, err = p.TakeIndex("key", 0, 1, nil)
+	assert.ErrorIs(t, err, pool.ErrUnavailable)
+}
+
+func TestPoolDiscardStale(t *testing.T) {
+	var mu sync.Mutex
+	var calls int
+	p := pool.New(nil)
+	defer p.Close()
+	require.NoError(t, p.Register("key", pool.Config{LowWatermark: 1, Replenish: countingReplenisher(4, 100, &calls, &mu)}))
+
+	for i := 0; i < 4; i++ {
+		preSignature, err := p.Take(context.Background(), "key", nil)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(0), preSignature.Epoch)
+		assert.Equal(t, i, preSignature.Index)
+	}
+
+	// The key shares were reshared, pre-signatures of epoch 0 must not be handed out anymore.
+	require.NoError(t, p.DiscardStale("key", 1))
+	for i := 0; i < 6; i++ {
+		preSignature, err := p.Take(context.Background(), "key", nil)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), preSignature.Epoch)
+		assert.Equal(t, i, preSignature.Index)
+	}
+}
+
+func TestPoolRejectsStaleBatch(t *testing.T) {
+	p := pool.New(nil)
+	defer p.Close()
+	require.NoError(t, p.Register("key", pool.Config{
+		LowWatermark: 1,
+		Replenish: func(_ context.Context, _ string, _ uint64, last int) (*pool.Batch, error) {
+			// Ignores the requested epoch and keeps handing out pre-signatures of epoch 0.
+			roots := []*bls12381.Fr{bls12381.NewFr().One(), bls12381.NewFr().One()}
+			return &pool.Batch{Epoch: 0, Generator: rootGenerator{0}, FirstIndex: last + 1, Roots: roots}, nil
+		},
+	}))
+	_, err := p.Take(context.Background(), "key", nil)
+	require.NoError(t, err)
+
+	require.NoError(t, p.DiscardStale("key", 1))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = p.Take(ctx, "key", nil)
+	assert.ErrorContains(t, err, "discarded")
+}
+
+func TestPoolReplenishFailure(t *testing.T) {
+	errSeed := errors.New("no seed")
+	p := pool.New(nil)
+	defer p.Close()
+	require.NoError(t, p.Register("key", pool.Config{
+		LowWatermark: 1,
+		Replenish: func(context.Context, string, uint64, int) (*pool.Batch, error) {
+			return nil, errSeed
+		},
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := p.Take(ctx, "key", nil)
+	assert.ErrorIs(t, err, errSeed)
+}
+
+func TestPCGReplenisher(t *testing.T) {
+	generator, err := pcg.NewPCG(128, 10, 2, 2, 2, 4) // Small lpn parameters for testing.
+	require.NoError(t, err)
+	ring, err := generator.GetRing(false)
+	require.NoError(t, err)
+	_, seeds, err := generator.SeedGenWithSk()
+	require.NoError(t, err)
+	randPolys, err := generator.PickRandomPolynomials()
+	require.NoError(t, err)
+	generator.SetEpoch(1)
+	_, reshared, err := generator.SeedGenWithSk()
+	require.NoError(t, err)
+
+	signerSet := []int{0, 1}
+	pools := make([]*pool.Pool, len(signerSet))
+	for i, party := range signerSet {
+		seed, next := seeds[party], reshared[party]
+		source := func(_ context.Context, previous *pcg.Seed) (*pcg.Seed, error) {
+			switch previous {
+			case nil:
+				return seed, nil
+			case seed:
+				return next, nil
+			}
+			return nil, errors.New("no further seeds")
+		}
+		pools[i] = pool.New(nil)
+		defer pools[i].Close()
+		require.NoError(t, pools[i].Register("key", pool.Config{
+			LowWatermark: 2,
+			Replenish:    pool.PCGReplenisher(generator, ring, randPolys, source, 4),
+		}))
+	}
+
+	takeAll := func(epoch uint64, count int) {
+		for iK := 0; iK < count; iK++ {
+			a := bls12381.NewFr().Zero()
+			s := bls12381.NewFr().Zero()
+			alpha := bls12381.NewFr().Zero()
+			for _, p := range pools {
+				preSignature, err := p.Take(context.Background(), "key", signerSet)
+				require.NoError(t, err)
+				assert.Equal(t, epoch, preSignature.Epoch, "parties must agree on the epoch")
+				assert.Equal(t, iK, preSignature.Index, "parties must agree on the index")
+				a.Add(a, preSignature.Live.AShare)
+				s.Add(s, preSignature.Live.SShare)
+				alpha.Add(alpha, preSignature.Live.AlphaShare)
+			}
+
+			as := bls12381.NewFr()
+			as.Mul(a, s)
+			assert.True(t, alpha.Equal(as), "alpha must be a * s")
+		}
+	}
+
+	takeAll(0, 6)
+	// After the resharing, the replenishers must continue with the seeds of epoch 1.
+	for _, p := range pools {
+		require.NoError(t, p.DiscardStale("key", 1))
+	}
+	takeAll(1, 2)
+}
@@ -0,0 +1,82 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation/pcg"
+	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation/pcg/poly"
+)
+
+// SeedSource returns the PCG seed of the party that follows previous, which is nil for the first seed. A seed of a
+// later key epoch starts the indices of the pre-signatures at 0 again, further seeds of the same epoch continue them.
+type SeedSource func(ctx context.Context, previous *pcg.Seed) (*pcg.Seed, error)
+
+// PCGReplenisher returns a Replenisher that evaluates the seeds of the party with p and hands out the roots of ring
+// in batches of batchSize. When all roots of a seed are used, it continues with the next seed of seeds.
+// All parties must use the same ring, random polynomials, batch size and sequence of seeds, so that a pre-signature
+// has the same epoch and index at every party. After Pool.DiscardStale, it skips the seeds of seeds before the new
+// epoch. The Replenisher must only be used for one key.
+func PCGReplenisher(p *pcg.PCG, ring *pcg.Ring, randPolys []*poly.Polynomial, seeds SeedSource, batchSize int) Replenisher {
+	var (
+		mu        sync.Mutex
+		seed      *pcg.Seed
+		generator *pcg.SeparateBBSPlusTupleGenerator
+		offset    int // Index of the pre-signature at the first root of seed.
+		nextRoot  int
+	)
+
+	return func(ctx context.Context, keyID string, epoch uint64, last int) (*Batch, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if batchSize <= 0 {
+			return nil, errors.New("batch size must be positive")
+		}
+		if generator != nil && last < 0 && seed.GetEpoch() < epoch {
+			// The pool discarded the pre-signatures of the seed, continue with a seed of epoch.
+			generator = nil
+		}
+		if generator != nil && (epoch != seed.GetEpoch() || last != offset+nextRoot-1) {
+			return nil, fmt.Errorf("replenisher of key %q is out of sync", keyID)
+		}
+
+		if generator == nil || nextRoot == len(ring.Roots) {
+			next, err := seeds(ctx, seed)
+			if err != nil {
+				return nil, fmt.Errorf("get seed: %w", err)
+			}
+			if seed != nil && next.GetEpoch() < seed.GetEpoch() {
+				return nil, fmt.Errorf("seed of epoch %d follows seed of epoch %d", next.GetEpoch(), seed.GetEpoch())
+			}
+			for next.GetEpoch() < epoch {
+				if next, err = seeds(ctx, next); err != nil {
+					return nil, fmt.Errorf("get seed: %w", err)
+				}
+			}
+			nextGenerator, err := p.EvalSeparate(next, randPolys, ring.Div)
+			if err != nil {
+				return nil, fmt.Errorf("evaluate seed: %w", err)
+			}
+
+			if seed != nil && next.GetEpoch() == seed.GetEpoch() {
+				offset += len(ring.Roots)
+			} else {
+				offset = 0
+			}
+			seed, generator, nextRoot = next, nextGenerator, 0
+		}
+
+		end := min(nextRoot+batchSize, len(ring.Roots))
+		batch := &Batch{
+			Epoch:      seed.GetEpoch(),
+			Generator:  generator,
+			FirstIndex: offset + nextRoot,
+			Roots:      ring.Roots[nextRoot:end],
+		}
+		nextRoot = end
+		return batch, nil
+	}
+}
@@ -9,9 +9,14 @@ import (
 	fhksbbsplus "github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
 	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
 	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation/pcg"
+	"github.com/perun-network/bbs-plus-threshold-wallet/precomputation/pcg/poly"
 	"github.com/perun-network/bbs-plus-threshold-wallet/test"
 )
 
+// mockRingExponent is the exponent N of the ring of degree 2^N of the PCG based mocks, whereas n always denotes the
+// number of parties.
+const mockRingExponent = 10
+
 type PCFPCGOutput struct {
 	Sk       *bls12381.Fr
 	SkShares []*bls12381.Fr
@@ -47,25 +52,18 @@ func GeneratePPPrecomputationMock(seedArray [16]uint8, t, k, n int) (*bls12381.F
 
 }
 
-func GeneratePPPrecomputationTauOutOfN(seedArray [16]uint8, tau, K, N int) (*bls12381.Fr, []*pcg.Seed, [][]*fhksbbsplus.LivePreSignatureSk) {
+func GeneratePPPrecomputationTauOutOfN(seedArray [16]uint8, tau, K, n int) (*bls12381.Fr, []*pcg.Seed, [][]*fhksbbsplus.LivePreSignatureSk) {
 	signerSet := test.IndicesSignersTestTauOutOfN
 
 	var sk *bls12381.Fr
 	livePreSignatures := make([][]*fhksbbsplus.LivePreSignatureSk, K)
 
-	sk, skSeeds, output := GeneratePCFPCGOutputTauOutOfN(seedArray, tau, K, N, signerSet)
+	sk, skSeeds, output := GeneratePCFPCGOutputTauOutOfN(seedArray, tau, K, n, signerSet)
 
 	for j := 0; j < K; j++ {
 		livePreSignaturesPerMsg := make([]*fhksbbsplus.LivePreSignatureSk, tau)
 		for i := 0; i < tau; i++ {
-			livePreSignaturesPerMsg[i] = &fhksbbsplus.LivePreSignatureSk{
-				SkShare:    output[j][i].SkShare,
-				AShare:     output[j][i].AShare,
-				EShare:     output[j][i].EShare,
-				SShare:     output[j][i].SShare,
-				DeltaShare: output[j][i].DeltaShare,
-				AlphaShare: output[j][i].AlphaShare,
-			}
+			livePreSignaturesPerMsg[i] = output[j][i].LivePreSignatureSk()
 		}
 		livePreSignatures[j] = livePreSignaturesPerMsg
 	}
@@ -84,14 +82,7 @@ func GeneratePPPrecomputationNOutOfN(seedArray [16]uint8, tau, K, n int) (*bls12
 	for j := 0; j < K; j++ {
 		livePreSignaturesPerMsg := make([]*fhksbbsplus.LivePreSignatureSk, tau)
 		for i := 0; i < tau; i++ {
-			livePreSignaturesPerMsg[i] = &fhksbbsplus.LivePreSignatureSk{
-				SkShare:    output[j][i].SkShare,
-				AShare:     output[j][i].AShare,
-				EShare:     output[j][i].EShare,
-				SShare:     output[j][i].SShare,
-				DeltaShare: output[j][i].DeltaShare,
-				AlphaShare: output[j][i].AlphaShare,
-			}
+			livePreSignaturesPerMsg[i] = output[j][i].LivePreSignatureSk()
 		}
 		livePreSignatures[j] = livePreSignaturesPerMsg
 	}
@@ -99,6 +90,33 @@ func GeneratePPPrecomputationNOutOfN(seedArray [16]uint8, tau, K, n int) (*bls12
 	return secretKey, skSeeds, livePreSignatures
 }
 
+func GeneratePPPrecomputationBBSTauOutOfN(tau, K, n int) (*bls12381.Fr, []*pcg.Seed, [][]*fhksbbsplus.LiveBBSPreSignature) {
+	signerSet := test.IndicesSignersTestTauOutOfN
+
+	sk, skSeeds, output := GeneratePCFPCGOutputBBSTauOutOfN(tau, K, n, signerSet)
+	return sk, skSeeds, liveBBSPreSignaturesFromTuples(output)
+}
+
+func GeneratePPPrecomputationBBSNOutOfN(tau, K, n int) (*bls12381.Fr, []*pcg.Seed, [][]*fhksbbsplus.LiveBBSPreSignature) {
+	if tau != n {
+		panic("threshold must be n")
+	}
+
+	sk, skSeeds, output := GeneratePCFPCGOutputBBSNOutOfN(tau, K, n)
+	return sk, skSeeds, liveBBSPreSignaturesFromTuples(output)
+}
+
+func liveBBSPreSignaturesFromTuples(tuples [][]*pcg.BBSTuple) [][]*fhksbbsplus.LiveBBSPreSignature {
+	livePreSignatures := make([][]*fhksbbsplus.LiveBBSPreSignature, len(tuples))
+	for j, tuplesPerMsg := range tuples {
+		livePreSignatures[j] = make([]*fhksbbsplus.LiveBBSPreSignature, len(tuplesPerMsg))
+		for i, tuple := range tuplesPerMsg {
+			livePreSignatures[j][i] = tuple.LiveBBSPreSignature()
+		}
+	}
+	return livePreSignatures
+}
+
 func GeneratePCFPCGOutputMockedFromSecretKey(sk *bls12381.Fr, t int, k int, n int) PCFPCGOutput {
 	skShares := helper.ShamirSharedSecretKey(sk, t, n)
 
@@ -126,26 +144,12 @@ func GeneratePCFPCGOutputMocked(seedArray [16]uint8, t int, k int, n int) PCFPCG
 	return PCFPCGOutput{sk, skShares, aShares, eShares, sShares, aeTerms, asTerms, askTerms}
 }
 
-func GeneratePCFPCGOutputTauOutOfN(seedArray [16]uint8, tau int, k int, N int, signerSet []int) (*bls12381.Fr, []*pcg.Seed, [][]*pcg.BBSPlusTuple) {
-	c, t := 2, 4
-
-	pcgenerator, err := pcg.NewPCG(128, 10, N, tau, c, t)
-	if err != nil {
-		panic(err)
-	}
-
-	ring, err := pcgenerator.GetRing(false)
-	if err != nil {
-		panic(err)
-	}
+func GeneratePCFPCGOutputTauOutOfN(seedArray [16]uint8, tau int, k int, n int, signerSet []int) (*bls12381.Fr, []*pcg.Seed, [][]*pcg.BBSPlusTuple) {
+	pcgenerator, ring, randPolys := newMockPCG(n, tau)
 	sk, seeds, err := pcgenerator.SeedGenWithSk()
 	if err != nil {
 		panic(err)
 	}
-	randPolys, err := pcgenerator.PickRandomPolynomials()
-	if err != nil {
-		panic(err)
-	}
 
 	root := ring.Roots[10]
 
@@ -169,30 +173,15 @@ func GeneratePCFPCGOutputTauOutOfN(seedArray [16]uint8, tau int, k int, N int, s
 }
 
 func GeneratePCFPCGOutputNOutOfN(seedArray [16]uint8, tau int, k int, n int) (*bls12381.Fr, []*pcg.Seed, [][]*pcg.BBSPlusTuple) {
-	c, t := 2, 4
-	N := 10
-
 	if tau != n {
 		panic("threshold must be n")
 	}
 
-	pcgenerator, err := pcg.NewPCG(128, N, n, tau, c, t)
-	if err != nil {
-		panic(err)
-	}
-
-	ring, err := pcgenerator.GetRing(false)
-	if err != nil {
-		panic(err)
-	}
+	pcgenerator, ring, randPolys := newMockPCG(n, tau)
 	sk, seeds, err := pcgenerator.SeedGenWithSk()
 	if err != nil {
 		panic(err)
 	}
-	randPolys, err := pcgenerator.PickRandomPolynomials()
-	if err != nil {
-		panic(err)
-	}
 	tupleArray := make([][]*pcg.BBSPlusTuple, k)
 
 	root := ring.Roots[10]
@@ -214,6 +203,26 @@ func GeneratePCFPCGOutputNOutOfN(seedArray [16]uint8, tau int, k int, n int) (*b
 	return sk, seeds, tupleArray
 }
 
+// newMockPCG sets up a PCG for n parties and threshold tau with the ring exponent mockRingExponent and small lpn
+// parameters, along with its ring and random polynomials.
+func newMockPCG(n, tau int) (*pcg.PCG, *pcg.Ring, []*poly.Polynomial) {
+	c, t := 2, 4
+
+	pcgenerator, err := pcg.NewPCG(128, mockRingExponent, n, tau, c, t)
+	if err != nil {
+		panic(err)
+	}
+	ring, err := pcgenerator.GetRing(false)
+	if err != nil {
+		panic(err)
+	}
+	randPolys, err := pcgenerator.PickRandomPolynomials()
+	if err != nil {
+		panic(err)
+	}
+	return pcgenerator, ring, randPolys
+}
+
 func CreatePPPrecomputation(
 	k int,
 	n int,
@@ -381,3 +390,62 @@ func CreatePPPrecomputationFromVOLEEvaluationWithPubKey(
 
 	return precomputations
 }
+
+// GeneratePCFPCGOutputBBSTauOutOfN evaluates BBS seeds of n parties for the parties in signerSet and derives k tuples
+// per party, each from a different root of the ring.
+func GeneratePCFPCGOutputBBSTauOutOfN(tau int, k int, n int, signerSet []int) (*bls12381.Fr, []*pcg.Seed, [][]*pcg.BBSTuple) {
+	pcgenerator, ring, randPolys := newMockPCG(n, tau)
+	sk, seeds, err := pcgenerator.SeedGenWithSkBBS()
+	if err != nil {
+		panic(err)
+	}
+
+	sharesGens := make([]*pcg.SeparateBBSTupleGenerator, len(signerSet))
+	for i, signer := range signerSet {
+		sharesGens[i], err = pcgenerator.EvalSeparateBBS(seeds[signer], randPolys, ring.Div)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	tupleArray := make([][]*pcg.BBSTuple, k)
+	for j := 0; j < k; j++ {
+		tupleArray[j] = make([]*pcg.BBSTuple, len(signerSet))
+		for i := range signerSet {
+			tupleArray[j][i] = sharesGens[i].GenBBSTuple(ring.Roots[j], signerSet)
+		}
+	}
+
+	return sk, seeds, tupleArray
+}
+
+// GeneratePCFPCGOutputBBSNOutOfN evaluates BBS seeds for all n parties and derives k tuples per party, each from a
+// different root of the ring.
+func GeneratePCFPCGOutputBBSNOutOfN(tau int, k int, n int) (*bls12381.Fr, []*pcg.Seed, [][]*pcg.BBSTuple) {
+	if tau != n {
+		panic("threshold must be n")
+	}
+
+	pcgenerator, ring, randPolys := newMockPCG(n, tau)
+	sk, seeds, err := pcgenerator.SeedGenWithSkBBS()
+	if err != nil {
+		panic(err)
+	}
+
+	sharesGens := make([]*pcg.BBSTupleGenerator, n)
+	for i := 0; i < n; i++ {
+		sharesGens[i], err = pcgenerator.EvalCombinedBBS(seeds[i], randPolys, ring.Div)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	tupleArray := make([][]*pcg.BBSTuple, k)
+	for j := 0; j < k; j++ {
+		tupleArray[j] = make([]*pcg.BBSTuple, n)
+		for i := 0; i < n; i++ {
+			tupleArray[j][i] = sharesGens[i].GenBBSTuple(ring.Roots[j])
+		}
+	}
+	return sk, seeds, tupleArray
+}
@@ -3,6 +3,7 @@ package zkp
 import (
 	bls12381 "github.com/kilic/bls12-381"
 	"github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
 )
 
 type CommitmentBuilder struct {
@@ -35,9 +36,10 @@ func (cb *CommitmentBuilder) Add(base *bls12381.PointG1, scalar *bls12381.Fr) {
 	cb.scalars = append(cb.scalars, scalar)
 }
 
-// Build performs multi-scalar multiplication on the accumulated bases and scalars
+// Build performs multi-scalar multiplication on the accumulated bases and scalars. The scalars are secret, hence the
+// multiplication runs in constant time.
 func (cb *CommitmentBuilder) Build() *bls12381.PointG1 {
-	return MultiScalarMulVarTimeG1(cb.bases, cb.scalars)
+	return helper.MultiScalarMulG1(cb.bases, cb.scalars)
 }
 
 func newVC1Signature(aPrime *bls12381.PointG1, h0 *bls12381.PointG1,
@@ -143,9 +145,10 @@ func (pc *ProverCommittingG1) Commit(base *bls12381.PointG1) {
 	pc.blindingFactors = append(pc.blindingFactors, r)
 }
 
-// Finish computes the final commitment by performing multi-scalar multiplication
+// Finish computes the final commitment by performing a constant-time multi-scalar multiplication with the secret
+// blinding factors
 func (pc *ProverCommittingG1) Finish() *ProverCommittedG1 {
-	commitment := MultiScalarMulVarTimeG1(pc.bases, pc.blindingFactors)
+	commitment := helper.MultiScalarMulG1(pc.bases, pc.blindingFactors)
 
 	return &ProverCommittedG1{
 		Bases:           pc.bases,
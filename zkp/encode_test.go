@@ -128,7 +128,7 @@ func TestThresholdSignatureSerializeDeserialize(t *testing.T) {
 	bytes, err := signature.ToBytes()
 	assert.NoError(t, err, "serialization should not return an error")
 	assert.NotNil(t, bytes, "serialized signature should not be nil")
-	assert.Equal(t, len(bytes), 48+32+32, "serialized signature should have correct length")
+	assert.Equal(t, len(bytes), 11+48+32+32, "serialized signature should have envelope header and payload")
 
 	deserializedSig, err := fhks_bbs_plus.ThresholdSignatureFromBytes(bytes)
 	assert.NoError(t, err, "deserialization should not return an error")
@@ -0,0 +1,3 @@
+package zkp
+
+var ProofGenIETFWithRandomScalars = proofGenIETF
@@ -0,0 +1,120 @@
+package zkp_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/stretchr/testify/require"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
+	"github.com/perun-network/bbs-plus-threshold-wallet/test"
+	"github.com/perun-network/bbs-plus-threshold-wallet/zkp"
+	zkptest "github.com/perun-network/bbs-plus-threshold-wallet/zkp/test"
+)
+
+// The fuzz targets check that the parsers never panic and only accept the canonical encoding, i.e., that every
+// accepted input is re-encoded to itself. FuzzVerifyBBSProof checks that verification never panics and rejects proofs for another number of messages than
+// the public key.
+
+func FuzzParseSignatureProof(f *testing.F) {
+	g1 := bls12381.NewG1()
+	pok := zkp.PoKOfSignatureProof{
+		APrime:   g1.One(),
+		ABar:     g1.One(),
+		D:        g1.One(),
+		ProofVC1: createSampleProofG1(),
+		ProofVC2: createSampleProofG1(),
+	}
+	data, err := pok.ToBytesCompressedForm()
+	require.NoError(f, err)
+	f.Add(data)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		pok, err := zkp.ParseSignatureProof(data)
+		if err != nil {
+			require.ErrorIs(t, err, helper.ErrInvalidEncoding)
+			return
+		}
+		encoded, err := pok.ToBytesCompressedForm()
+		require.NoError(t, err)
+		require.True(t, bytes.Equal(data, encoded))
+	})
+}
+
+func FuzzParsePoKPayload(f *testing.F) {
+	for _, payload := range []*zkp.PokPayload{zkp.NewPoKPayload(5, []int{0, 2}), zkp.NewPoKPayload(16, []int{15})} {
+		data, err := payload.ToBytes()
+		require.NoError(f, err)
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		payload, err := zkp.ParsePoKPayload(data)
+		if err != nil {
+			require.ErrorIs(t, err, helper.ErrInvalidEncoding)
+			return
+		}
+		encoded, err := payload.ToBytes()
+		require.NoError(t, err)
+		require.True(t, bytes.Equal(data[:payload.LenInBytes()], encoded))
+	})
+}
+
+func FuzzIETFProofFromBytes(f *testing.F) {
+	msgs := test.Messages[:3]
+	sk := fhks_bbs_plus.SecretKey{Fr: fhks_bbs_plus.GenerateRandomFr()}
+	pk := sk.GetPublicKey(len(msgs))
+	messages, err := fhks_bbs_plus.BLS12381SHA256.MessagesToScalars(msgs)
+	require.NoError(f, err)
+	signature, err := sk.SignIETF(pk, nil, messages)
+	require.NoError(f, err)
+	data, err := zkp.ProofGenIETF(pk, signature, nil, nil, msgs, []int{1})
+	require.NoError(f, err)
+	f.Add(data)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		proof, err := zkp.IETFProofFromBytes(data)
+		if err != nil {
+			require.ErrorIs(t, err, helper.ErrInvalidEncoding)
+			return
+		}
+		require.True(t, bytes.Equal(data, proof.ToBytes()))
+	})
+}
+
+func FuzzVerifyBBSProof(f *testing.F) {
+	msgs := test.Messages[:5]
+	kp := zkptest.CreateTestKeyPair(f, len(msgs))
+	request := zkptest.CreateProofReqNoNonce(f, kp, msgs, test.Revealed)
+	sigBytes, err := request.Signature.ToBytes()
+	require.NoError(f, err)
+	pubKeyBytes := kp.PublicKey.Serialize()
+	nonce := []byte("nonce")
+	proof, err := zkp.CreateProofBBS(msgs, sigBytes, nonce, pubKeyBytes, test.Revealed)
+	require.NoError(f, err)
+	f.Add(proof, uint8(len(test.Revealed)))
+
+	// The same proof claiming more messages than the public key, all of them revealed.
+	signatureProof := proof[zkp.NewPoKPayload(len(msgs), test.Revealed).LenInBytes():]
+	allRevealed := make([]int, 16)
+	for i := range allRevealed {
+		allRevealed[i] = i
+	}
+	payload, err := zkp.NewPoKPayload(len(allRevealed), allRevealed).ToBytes()
+	require.NoError(f, err)
+	f.Add(append(payload, signatureProof...), uint8(len(allRevealed)))
+
+	f.Fuzz(func(t *testing.T, proof []byte, revealedCount uint8) {
+		revealedMessages := make([][]byte, revealedCount)
+		for i := range revealedMessages {
+			revealedMessages[i] = test.Messages[i%len(test.Messages)]
+		}
+		err := zkp.VerifyBBSProof(revealedMessages, proof, nonce, pubKeyBytes)
+		if len(proof) >= 2 && binary.BigEndian.Uint16(proof) != uint16(len(msgs)) {
+			require.ErrorIs(t, err, helper.ErrInvalidEncoding, "proof for another number of messages than the public key")
+		}
+	})
+}
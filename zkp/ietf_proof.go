@@ -0,0 +1,367 @@
+package zkp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
+)
+
+// IETFProof is a proof of knowledge of an IETF BBS signature as defined by ProofGen of the IETF BBS draft.
+type IETFProof struct {
+	ABar      *bls12381.PointG1
+	BBar      *bls12381.PointG1
+	D         *bls12381.PointG1
+	EHat      *bls12381.Fr
+	R1Hat     *bls12381.Fr
+	R3Hat     *bls12381.Fr
+	MHat      []*bls12381.Fr // Responses for the undisclosed messages, in ascending order of their indices.
+	Challenge *bls12381.Fr
+}
+
+// RandomScalars returns count uniformly random scalars.
+func RandomScalars(count int) ([]*bls12381.Fr, error) {
+	scalars := make([]*bls12381.Fr, count)
+	for i := range scalars {
+		scalars[i] = fhks_bbs_plus.GenerateRandomFr()
+	}
+	return scalars, nil
+}
+
+// ProofGenIETF implements ProofGen of the IETF BBS draft. It proves knowledge of signature on messages and header
+// under pk, disclosing only the messages at disclosedIndexes. ph is the presentation header bound to the proof.
+func ProofGenIETF(
+	pk *fhks_bbs_plus.PublicKey,
+	signature *fhks_bbs_plus.IETFSignature,
+	header, ph []byte,
+	messages [][]byte,
+	disclosedIndexes []int,
+) ([]byte, error) {
+	proof, err := proofGenIETF(pk, signature, header, ph, messages, disclosedIndexes, RandomScalars)
+	if err != nil {
+		return nil, err
+	}
+	return proof.ToBytes(), nil
+}
+
+func proofGenIETF(
+	pk *fhks_bbs_plus.PublicKey,
+	signature *fhks_bbs_plus.IETFSignature,
+	header, ph []byte,
+	messages [][]byte,
+	disclosedIndexes []int,
+	randomScalars func(count int) ([]*bls12381.Fr, error),
+) (*IETFProof, error) {
+	cs, err := pk.IETFCiphersuite()
+	if err != nil {
+		return nil, err
+	}
+
+	disclosedIndexes, err = sortedIndexes(disclosedIndexes, len(messages))
+	if err != nil {
+		return nil, err
+	}
+	undisclosedIndexes := complementIndexes(disclosedIndexes, len(messages))
+
+	messageScalars, err := cs.MessagesToScalars(messages)
+	if err != nil {
+		return nil, err
+	}
+
+	domain, basis, err := pk.IETFBasis(header, messageScalars)
+	if err != nil {
+		return nil, err
+	}
+
+	scalars, err := randomScalars(5 + len(undisclosedIndexes))
+	if err != nil {
+		return nil, fmt.Errorf("generate random scalars: %w", err)
+	}
+	r1, r2, eTilde, r1Tilde, r3Tilde, mTilde := scalars[0], scalars[1], scalars[2], scalars[3], scalars[4], scalars[5:]
+
+	// ProofInit.
+	g1 := bls12381.NewG1()
+	d := g1.New()
+	g1.MulScalar(d, basis, r2)
+
+	r1r2 := bls12381.NewFr()
+	r1r2.Mul(r1, r2)
+	aBar := g1.New()
+	g1.MulScalar(aBar, signature.CapitalA, r1r2)
+
+	bBar := g1.New()
+	g1.MulScalar(bBar, d, r1)
+	tmp := g1.New()
+	g1.MulScalar(tmp, aBar, signature.E)
+	g1.Sub(bBar, bBar, tmp)
+
+	// The blinding scalars are secret, hence the constant-time multi-scalar multiplication is used.
+	t1 := helper.MultiScalarMulG1([]*bls12381.PointG1{aBar, d}, []*bls12381.Fr{eTilde, r1Tilde})
+
+	t2Bases := []*bls12381.PointG1{d}
+	for _, j := range undisclosedIndexes {
+		t2Bases = append(t2Bases, pk.H[j])
+	}
+	t2 := helper.MultiScalarMulG1(t2Bases, append([]*bls12381.Fr{r3Tilde}, mTilde...))
+
+	challenge, err := ietfChallenge(cs, aBar, bBar, d, t1, t2, domain, disclosedIndexes, messageScalars, ph)
+	if err != nil {
+		return nil, err
+	}
+
+	// ProofFinalize.
+	r3 := bls12381.NewFr()
+	r3.Inverse(r2)
+
+	eHat := bls12381.NewFr()
+	eHat.Mul(signature.E, challenge)
+	eHat.Add(eHat, eTilde)
+
+	r1Hat := bls12381.NewFr()
+	r1Hat.Mul(r1, challenge)
+	r1Hat.Sub(r1Tilde, r1Hat)
+
+	r3Hat := bls12381.NewFr()
+	r3Hat.Mul(r3, challenge)
+	r3Hat.Sub(r3Tilde, r3Hat)
+
+	mHat := make([]*bls12381.Fr, len(undisclosedIndexes))
+	for i, j := range undisclosedIndexes {
+		mHat[i] = bls12381.NewFr()
+		mHat[i].Mul(messageScalars[j], challenge)
+		mHat[i].Add(mHat[i], mTilde[i])
+	}
+
+	return &IETFProof{
+		ABar:      aBar,
+		BBar:      bBar,
+		D:         d,
+		EHat:      eHat,
+		R1Hat:     r1Hat,
+		R3Hat:     r3Hat,
+		MHat:      mHat,
+		Challenge: challenge,
+	}, nil
+}
+
+// ProofVerifyIETF implements ProofVerify of the IETF BBS draft. disclosedMessages are the messages at
+// disclosedIndexes, in the same order.
+func ProofVerifyIETF(
+	pk *fhks_bbs_plus.PublicKey,
+	proofBytes []byte,
+	header, ph []byte,
+	disclosedMessages [][]byte,
+	disclosedIndexes []int,
+) error {
+	cs, err := pk.IETFCiphersuite()
+	if err != nil {
+		return err
+	}
+
+	proof, err := IETFProofFromBytes(proofBytes)
+	if err != nil {
+		return err
+	}
+
+	if len(disclosedMessages) != len(disclosedIndexes) {
+		return errors.New("number of disclosed messages and indexes must match")
+	}
+	messageCount := len(disclosedIndexes) + len(proof.MHat)
+	if messageCount != len(pk.H) {
+		return fmt.Errorf("proof is for %d messages, public key for %d", messageCount, len(pk.H))
+	}
+
+	// Sort the disclosed messages along with their indexes.
+	order := make([]int, len(disclosedIndexes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return disclosedIndexes[order[a]] < disclosedIndexes[order[b]] })
+	sortedDisclosedIndexes := make([]int, len(order))
+	sortedDisclosedMessages := make([][]byte, len(order))
+	for i, o := range order {
+		sortedDisclosedIndexes[i] = disclosedIndexes[o]
+		sortedDisclosedMessages[i] = disclosedMessages[o]
+	}
+	if _, err := sortedIndexes(sortedDisclosedIndexes, messageCount); err != nil {
+		return err
+	}
+	undisclosedIndexes := complementIndexes(sortedDisclosedIndexes, messageCount)
+
+	disclosedScalars, err := cs.MessagesToScalars(sortedDisclosedMessages)
+	if err != nil {
+		return err
+	}
+	messageScalars := make([]*bls12381.Fr, messageCount)
+	for i, index := range sortedDisclosedIndexes {
+		messageScalars[index] = disclosedScalars[i]
+	}
+
+	domain, err := cs.CalculateDomain(pk, header)
+	if err != nil {
+		return fmt.Errorf("calculate domain: %w", err)
+	}
+	p1, err := cs.P1()
+	if err != nil {
+		return fmt.Errorf("create base point: %w", err)
+	}
+
+	// ProofVerifyInit.
+	t1 := helper.MultiScalarMulVarTimeG1(
+		[]*bls12381.PointG1{proof.BBar, proof.ABar, proof.D},
+		[]*bls12381.Fr{proof.Challenge, proof.EHat, proof.R1Hat},
+	)
+
+	bvBases := []*bls12381.PointG1{pk.H0}
+	bvScalars := []*bls12381.Fr{domain}
+	for i, index := range sortedDisclosedIndexes {
+		bvBases = append(bvBases, pk.H[index])
+		bvScalars = append(bvScalars, disclosedScalars[i])
+	}
+	bv := helper.MultiScalarMulVarTimeG1(bvBases, bvScalars)
+	g1 := bls12381.NewG1()
+	g1.Add(bv, bv, p1)
+
+	t2Bases := []*bls12381.PointG1{bv, proof.D}
+	for _, j := range undisclosedIndexes {
+		t2Bases = append(t2Bases, pk.H[j])
+	}
+	t2 := helper.MultiScalarMulVarTimeG1(t2Bases, append([]*bls12381.Fr{proof.Challenge, proof.R3Hat}, proof.MHat...))
+
+	challenge, err := ietfChallenge(cs, proof.ABar, proof.BBar, proof.D, t1, t2, domain, sortedDisclosedIndexes, messageScalars, ph)
+	if err != nil {
+		return err
+	}
+	if !challenge.Equal(proof.Challenge) {
+		return errors.New("proof verification failed: challenge mismatch")
+	}
+
+	// Check e(Abar, W) == e(Bbar, g_2).
+	g2 := bls12381.NewG2()
+	engine := bls12381.NewEngine()
+	engine.AddPair(proof.ABar, pk.W)
+	engine.AddPairInv(proof.BBar, g2.One())
+	if !engine.Check() {
+		return errors.New("proof verification failed: pairing check")
+	}
+	return nil
+}
+
+// ietfChallenge implements ProofChallengeCalculate of the IETF BBS draft. messageScalars holds the scalars of all
+// messages, only those at disclosedIndexes are read.
+func ietfChallenge(
+	cs *fhks_bbs_plus.Ciphersuite,
+	aBar, bBar, d, t1, t2 *bls12381.PointG1,
+	domain *bls12381.Fr,
+	disclosedIndexes []int,
+	messageScalars []*bls12381.Fr,
+	ph []byte,
+) (*bls12381.Fr, error) {
+	g1 := bls12381.NewG1()
+
+	cOcts := binary.BigEndian.AppendUint64(nil, uint64(len(disclosedIndexes)))
+	for _, index := range disclosedIndexes {
+		cOcts = binary.BigEndian.AppendUint64(cOcts, uint64(index))
+		cOcts = append(cOcts, messageScalars[index].ToBytes()...)
+	}
+	for _, point := range []*bls12381.PointG1{aBar, bBar, d, t1, t2} {
+		cOcts = append(cOcts, g1.ToCompressed(point)...)
+	}
+	cOcts = append(cOcts, domain.ToBytes()...)
+	cOcts = binary.BigEndian.AppendUint64(cOcts, uint64(len(ph)))
+	cOcts = append(cOcts, ph...)
+
+	return cs.HashToScalar(cOcts, cs.HashToScalarDST())
+}
+
+// ToBytes implements proof_to_octets of the IETF BBS draft.
+func (p *IETFProof) ToBytes() []byte {
+	g1 := bls12381.NewG1()
+
+	bytes := make([]byte, 0, 3*helper.LenBytesG1Compressed+(4+len(p.MHat))*helper.LenBytesFr)
+	bytes = append(bytes, g1.ToCompressed(p.ABar)...)
+	bytes = append(bytes, g1.ToCompressed(p.BBar)...)
+	bytes = append(bytes, g1.ToCompressed(p.D)...)
+	bytes = append(bytes, p.EHat.ToBytes()...)
+	bytes = append(bytes, p.R1Hat.ToBytes()...)
+	bytes = append(bytes, p.R3Hat.ToBytes()...)
+	for _, m := range p.MHat {
+		bytes = append(bytes, m.ToBytes()...)
+	}
+	return append(bytes, p.Challenge.ToBytes()...)
+}
+
+// IETFProofFromBytes implements octets_to_proof of the IETF BBS draft.
+func IETFProofFromBytes(data []byte) (*IETFProof, error) {
+	pointsLen := 3 * helper.LenBytesG1Compressed
+	if len(data) < pointsLen+4*helper.LenBytesFr || (len(data)-pointsLen)%helper.LenBytesFr != 0 {
+		return nil, fmt.Errorf("%w: invalid serialized proof length %d", helper.ErrInvalidEncoding, len(data))
+	}
+
+	g1 := bls12381.NewG1()
+	points := make([]*bls12381.PointG1, 3)
+	for i := range points {
+		point, err := g1.FromCompressed(data[i*helper.LenBytesG1Compressed : (i+1)*helper.LenBytesG1Compressed])
+		if err != nil {
+			return nil, fmt.Errorf("%w: deserialize G1 point %d: %w", helper.ErrInvalidEncoding, i, err)
+		}
+		if g1.IsZero(point) {
+			return nil, fmt.Errorf("%w: G1 point %d is the identity", helper.ErrInvalidEncoding, i)
+		}
+		points[i] = point
+	}
+
+	scalars := make([]*bls12381.Fr, (len(data)-pointsLen)/helper.LenBytesFr)
+	for i := range scalars {
+		offset := pointsLen + i*helper.LenBytesFr
+		scalar, err := fhks_bbs_plus.ScalarFromBytes(data[offset : offset+helper.LenBytesFr])
+		if err != nil {
+			return nil, fmt.Errorf("deserialize scalar %d: %w", i, err)
+		}
+		scalars[i] = scalar
+	}
+
+	return &IETFProof{
+		ABar:      points[0],
+		BBar:      points[1],
+		D:         points[2],
+		EHat:      scalars[0],
+		R1Hat:     scalars[1],
+		R3Hat:     scalars[2],
+		MHat:      scalars[3 : len(scalars)-1],
+		Challenge: scalars[len(scalars)-1],
+	}, nil
+}
+
+// sortedIndexes returns a sorted copy of indexes, which must be distinct and smaller than count.
+func sortedIndexes(indexes []int, count int) ([]int, error) {
+	sorted := append([]int{}, indexes...)
+	sort.Ints(sorted)
+	for i, index := range sorted {
+		if index < 0 || index >= count {
+			return nil, fmt.Errorf("disclosed index %d is out of bounds", index)
+		}
+		if i > 0 && sorted[i-1] == index {
+			return nil, fmt.Errorf("disclosed index %d is given twice", index)
+		}
+	}
+	return sorted, nil
+}
+
+// complementIndexes returns the indexes smaller than count that are not in the sorted slice indexes.
+func complementIndexes(indexes []int, count int) []int {
+	complement := make([]int, 0, count-len(indexes))
+	next := 0
+	for i := 0; i < count; i++ {
+		if next < len(indexes) && indexes[next] == i {
+			next++
+			continue
+		}
+		complement = append(complement, i)
+	}
+	return complement
+}
@@ -0,0 +1,140 @@
+package zkp_test
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
+	"github.com/perun-network/bbs-plus-threshold-wallet/test"
+	"github.com/perun-network/bbs-plus-threshold-wallet/zkp"
+)
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	assert.NoError(t, err)
+	return b
+}
+
+// mockedRandomScalars implements mocked_calculate_random_scalars of the IETF BBS draft.
+func mockedRandomScalars(t *testing.T, cs *fhks_bbs_plus.Ciphersuite) func(count int) ([]*bls12381.Fr, error) {
+	return func(count int) ([]*bls12381.Fr, error) {
+		dst := append(cs.APIID(), []byte("MOCK_RANDOM_SCALARS_DST_")...)
+		v, err := cs.ExpandMessage(mustDecodeHex(t, test.MockedRandomScalarsSeed), dst, 48*count)
+		if err != nil {
+			return nil, err
+		}
+
+		scalars := make([]*bls12381.Fr, count)
+		for i := range scalars {
+			scalar := new(big.Int).SetBytes(v[i*48 : (i+1)*48])
+			scalar.Mod(scalar, bls12381.NewG1().Q())
+			scalars[i] = bls12381.NewFr().FromBytes(scalar.Bytes())
+		}
+		return scalars, nil
+	}
+}
+
+func TestIETFProofTestVectors(t *testing.T) {
+	for _, vector := range test.IETFVectors {
+		sk := bls12381.NewFr().FromBytes(mustDecodeHex(t, vector.SecretKey))
+		g2 := bls12381.NewG2()
+		w := g2.New()
+		g2.MulScalar(w, g2.One(), sk)
+		pk, err := fhks_bbs_plus.NewPublicKey(w, vector.CiphersuiteID, nil, 1)
+		assert.NoError(t, err)
+
+		signature, err := fhks_bbs_plus.IETFSignatureFromBytes(mustDecodeHex(t, vector.Signature))
+		assert.NoError(t, err)
+
+		cs, err := fhks_bbs_plus.CiphersuiteByID(vector.CiphersuiteID)
+		assert.NoError(t, err)
+		header := mustDecodeHex(t, vector.Header)
+		ph := mustDecodeHex(t, vector.PresentationHeader)
+		messages := [][]byte{mustDecodeHex(t, vector.Message)}
+
+		proof, err := zkp.ProofGenIETFWithRandomScalars(pk, signature, header, ph, messages, []int{0}, mockedRandomScalars(t, cs))
+		assert.NoError(t, err)
+		assert.Equal(t, vector.Proof, hex.EncodeToString(proof.ToBytes()), "%s: proof mismatch", vector.CiphersuiteID)
+
+		proofBytes := mustDecodeHex(t, vector.Proof)
+		assert.NoError(t, zkp.ProofVerifyIETF(pk, proofBytes, header, ph, messages, []int{0}))
+		assert.Error(t, zkp.ProofVerifyIETF(pk, proofBytes, header, nil, messages, []int{0}), "%s: proof verified with wrong presentation header", vector.CiphersuiteID)
+	}
+}
+
+func TestIETFMultiMessageProofTestVectors(t *testing.T) {
+	messages := make([][]byte, len(test.IETFMessages))
+	for i, message := range test.IETFMessages {
+		messages[i] = mustDecodeHex(t, message)
+	}
+	for i, vector := range test.IETFMultiMessageVectors {
+		keyVector := test.IETFVectors[i]
+		assert.Equal(t, keyVector.CiphersuiteID, vector.CiphersuiteID)
+
+		sk := bls12381.NewFr().FromBytes(mustDecodeHex(t, keyVector.SecretKey))
+		g2 := bls12381.NewG2()
+		w := g2.New()
+		g2.MulScalar(w, g2.One(), sk)
+		pk, err := fhks_bbs_plus.NewPublicKey(w, vector.CiphersuiteID, nil, len(messages))
+		assert.NoError(t, err)
+
+		signature, err := fhks_bbs_plus.IETFSignatureFromBytes(mustDecodeHex(t, vector.Signature))
+		assert.NoError(t, err)
+
+		cs, err := fhks_bbs_plus.CiphersuiteByID(vector.CiphersuiteID)
+		assert.NoError(t, err)
+		header := mustDecodeHex(t, keyVector.Header)
+		ph := mustDecodeHex(t, keyVector.PresentationHeader)
+
+		for _, proofVector := range vector.Proofs {
+			proof, err := zkp.ProofGenIETFWithRandomScalars(pk, signature, header, ph, messages, proofVector.Disclosed, mockedRandomScalars(t, cs))
+			assert.NoError(t, err)
+			assert.Equal(t, proofVector.Proof, hex.EncodeToString(proof.ToBytes()), "%s: proof mismatch for %v", vector.CiphersuiteID, proofVector.Disclosed)
+
+			disclosed := make([][]byte, len(proofVector.Disclosed))
+			for k, index := range proofVector.Disclosed {
+				disclosed[k] = messages[index]
+			}
+			proofBytes := mustDecodeHex(t, proofVector.Proof)
+			assert.NoError(t, zkp.ProofVerifyIETF(pk, proofBytes, header, ph, disclosed, proofVector.Disclosed))
+			assert.Error(t, zkp.ProofVerifyIETF(pk, proofBytes, nil, ph, disclosed, proofVector.Disclosed), "%s: proof verified with wrong header", vector.CiphersuiteID)
+		}
+	}
+}
+
+func TestIETFProofGenVerify(t *testing.T) {
+	msgs := test.Messages[:5]
+	header := []byte("header")
+	ph := []byte("presentation header")
+
+	sk := fhks_bbs_plus.SecretKey{Fr: fhks_bbs_plus.GenerateRandomFr()}
+	pk := sk.GetPublicKey(len(msgs))
+
+	messages, err := fhks_bbs_plus.BLS12381SHA256.MessagesToScalars(msgs)
+	assert.NoError(t, err)
+	signature, err := sk.SignIETF(pk, header, messages)
+	assert.NoError(t, err)
+
+	for _, disclosed := range [][]int{{}, {0, 2}, {4, 1}, {0, 1, 2, 3, 4}} {
+		proof, err := zkp.ProofGenIETF(pk, signature, header, ph, msgs, disclosed)
+		assert.NoError(t, err)
+
+		disclosedMessages := make([][]byte, len(disclosed))
+		for i, index := range disclosed {
+			disclosedMessages[i] = msgs[index]
+		}
+		assert.NoError(t, zkp.ProofVerifyIETF(pk, proof, header, ph, disclosedMessages, disclosed), "disclosed %v", disclosed)
+
+		if len(disclosed) > 0 {
+			disclosedMessages[0] = []byte("forged")
+			assert.Error(t, zkp.ProofVerifyIETF(pk, proof, header, ph, disclosedMessages, disclosed), "disclosed %v", disclosed)
+		}
+	}
+
+	_, err = zkp.ProofGenIETF(pk, signature, header, ph, msgs, []int{1, 1})
+	assert.Error(t, err)
+}
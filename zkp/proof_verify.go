@@ -16,10 +16,13 @@ type ProofG1 struct {
 func (proof *ProofG1) GetChallengeContribution(bases []*bls12381.PointG1, commitment *bls12381.PointG1, challenge *ProofChallenge) *bls12381.PointG1 {
 	points := append(bases, commitment)
 	scalars := append(proof.Responses, challenge.Fr)
-	return MultiScalarMulVarTimeG1(points, scalars)
+	return helper.MultiScalarMulVarTimeG1(points, scalars)
 }
 
 func (proof *ProofG1) Verify(bases []*bls12381.PointG1, commitment *bls12381.PointG1, challenge *ProofChallenge) error {
+	if len(proof.Responses) != len(bases) {
+		return fmt.Errorf("expected %d responses, got %d", len(bases), len(proof.Responses))
+	}
 
 	contribution := proof.GetChallengeContribution(bases, commitment, challenge)
 
@@ -80,15 +83,21 @@ func VerifyBBSProof(messagesBytes [][]byte, proof, nonce, pubKeyBytes []byte) er
 		return fmt.Errorf("failed to parse public key: %w", err)
 	}
 
+	// The challenge covers the bases of all hidden messages, so the proof must be for exactly the messages of pk.
+	if payload.messagesCount != pk.MessageCount() || len(payload.revealed) > pk.MessageCount() {
+		return fmt.Errorf("%w: proof for %d messages with %d revealed, public key for %d messages", helper.ErrInvalidEncoding,
+			payload.messagesCount, len(payload.revealed), pk.MessageCount())
+	}
+
 	msgSigmsg := FrToSigMessages(messagesBytes)
 
+	if len(payload.revealed) > len(msgSigmsg) {
+		return fmt.Errorf("payload revealed longer than signature messages")
+	}
 	revealedMessages := make(map[int]*SignatureMessage)
 	for i := range payload.revealed {
 		revealedMessages[payload.revealed[i]] = msgSigmsg[i]
 	}
-	if len(payload.revealed) > len(msgSigmsg) {
-		return fmt.Errorf("payload revealed longer than signature messages")
-	}
 
 	challengeBytes := signatureProof.GetBytesForChallenge(revealedMessages, pk)
 
@@ -136,7 +145,7 @@ func (proof *PoKOfSignatureProof) verifyVC2Proof(challenge *bls12381.Fr, pubKey
 	}
 
 	// Step 4: Compute pr = g1 * h1^-m1 * h2^-m2.... for all disclosed messages
-	posPr := MultiScalarMulVarTimeG1(basesDisclosed, exponents)
+	posPr := helper.MultiScalarMulVarTimeG1(basesDisclosed, exponents)
 
 	// Negate pr to compute pr^-1
 	g1 := bls12381.NewG1()
@@ -196,7 +205,7 @@ func (proof *PoKOfSignatureProof) Verify(proofChallenge *bls12381.Fr, vk *fhks_b
 
 func (sp *PoKOfSignatureProof) GetBytesForChallenge(revealedMessages map[int]*SignatureMessage,
 	pubKey *fhks_bbs_plus.PublicKey) []byte {
-	hiddenCount := pubKey.MessageCount() - len(revealedMessages)
+	hiddenCount := max(pubKey.MessageCount()-len(revealedMessages), 0)
 
 	bytesLen := (7 + hiddenCount) * helper.LenBytesG1Compressed
 	bytes := make([]byte, 0, bytesLen)
@@ -229,8 +238,8 @@ func (sp *PoKOfSignatureProof) GetBytesForChallenge(revealedMessages map[int]*Si
 }
 
 func ParseSignatureProof(sigProofBytes []byte) (*PoKOfSignatureProof, error) {
-	if len(sigProofBytes) < helper.LenBytesG1Compressed*3 {
-		return nil, errors.New("invalid size of signature proof")
+	if len(sigProofBytes) < helper.LenBytesG1Compressed*3+4 {
+		return nil, fmt.Errorf("%w: invalid size of signature proof", helper.ErrInvalidEncoding)
 	}
 
 	g1Points := make([]*bls12381.PointG1, 3)
@@ -241,7 +250,7 @@ func ParseSignatureProof(sigProofBytes []byte) (*PoKOfSignatureProof, error) {
 	for i := range g1Points {
 		g1Point, err := g1.FromCompressed(sigProofBytes[offset : offset+helper.LenBytesG1Compressed])
 		if err != nil {
-			return nil, fmt.Errorf("parse G1 point: %w", err)
+			return nil, fmt.Errorf("%w: parse G1 point: %w", helper.ErrInvalidEncoding, err)
 		}
 
 		g1Points[i] = g1Point
@@ -251,6 +260,10 @@ func ParseSignatureProof(sigProofBytes []byte) (*PoKOfSignatureProof, error) {
 	proof1BytesLen := int(uint32FromBytes(sigProofBytes[offset : offset+4]))
 	offset += 4
 
+	if proof1BytesLen > len(sigProofBytes)-offset {
+		return nil, fmt.Errorf("%w: invalid size of signature proof", helper.ErrInvalidEncoding)
+	}
+
 	proofVc1, err := ParseProofG1(sigProofBytes[offset : offset+proof1BytesLen])
 	if err != nil {
 		return nil, fmt.Errorf("parse G1 proof: %w", err)
@@ -280,27 +293,30 @@ func ParseProofNonce(proofNonceBytes []byte) *ProofNonce {
 
 func ParseProofG1(bytes []byte) (*ProofG1, error) {
 	if len(bytes) < helper.LenBytesG1Compressed+4 {
-		return nil, errors.New("invalid size of G1 signature proof")
+		return nil, fmt.Errorf("%w: invalid size of G1 signature proof", helper.ErrInvalidEncoding)
 	}
 
 	offset := 0
 
 	commitment, err := bls12381.NewG1().FromCompressed(bytes[:helper.LenBytesG1Compressed])
 	if err != nil {
-		return nil, fmt.Errorf("parse G1 point: %w", err)
+		return nil, fmt.Errorf("%w: parse G1 point: %w", helper.ErrInvalidEncoding, err)
 	}
 
 	offset += helper.LenBytesG1Compressed
 	length := int(uint32FromBytes(bytes[offset : offset+4]))
 	offset += 4
 
-	if len(bytes) < helper.LenBytesG1Compressed+4+length*helper.LenBytesFr {
-		return nil, errors.New("invalid size of G1 signature proof")
+	if length > (len(bytes)-offset)/helper.LenBytesFr || len(bytes) != offset+length*helper.LenBytesFr {
+		return nil, fmt.Errorf("%w: invalid size of G1 signature proof", helper.ErrInvalidEncoding)
 	}
 
 	responses := make([]*bls12381.Fr, length)
 	for i := 0; i < length; i++ {
-		responses[i] = bls12381.NewFr().FromBytes(bytes[offset : offset+helper.LenBytesFr])
+		responses[i], err = helper.FrFromCanonicalBytes(bytes[offset : offset+helper.LenBytesFr])
+		if err != nil {
+			return nil, fmt.Errorf("parse response %d: %w", i, err)
+		}
 		offset += helper.LenBytesFr
 	}
 
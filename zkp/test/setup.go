@@ -13,13 +13,13 @@ type KeyPairTest struct {
 	PublicKey fhks_bbs_plus.PublicKey
 }
 
-func CreateTestKeyPair(t *testing.T, msgCount int) KeyPairTest {
+func CreateTestKeyPair(t testing.TB, msgCount int) KeyPairTest {
 	sk := fhks_bbs_plus.SecretKey{Fr: fhks_bbs_plus.GenerateRandomFr()}
 	pk := *sk.GetPublicKey(msgCount)
 	return KeyPairTest{SecretKey: sk, PublicKey: pk}
 }
 
-func CreateProofReqNoNonce(t *testing.T, kp KeyPairTest, msgs [][]byte, revealed []int) zkp.CreateProofRequest {
+func CreateProofReqNoNonce(t testing.TB, kp KeyPairTest, msgs [][]byte, revealed []int) zkp.CreateProofRequest {
 	CheckRevealedIndices(t, msgs, revealed)
 
 	e := fhks_bbs_plus.GenerateRandomFr()
@@ -44,7 +44,7 @@ func CreateProofReqNoNonce(t *testing.T, kp KeyPairTest, msgs [][]byte, revealed
 	return proofNoChall
 }
 
-func CheckRevealedIndices(t *testing.T, msgs [][]byte, revealed []int) {
+func CheckRevealedIndices(t testing.TB, msgs [][]byte, revealed []int) {
 	maxIndex := -1
 	for _, index := range revealed {
 		if index > maxIndex {
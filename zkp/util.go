@@ -6,43 +6,22 @@ import (
 	"fmt"
 	bls12381 "github.com/kilic/bls12-381"
 	"github.com/perun-network/bbs-plus-threshold-wallet/fhks_bbs_plus"
+	"github.com/perun-network/bbs-plus-threshold-wallet/helper"
 )
 
-// MultiScalarMulVarTimeG1 performs multi-scalar multiplication on G1 points with corresponding scalars
-func MultiScalarMulVarTimeG1(bases []*bls12381.PointG1, scalars []*bls12381.Fr) *bls12381.PointG1 {
-	g1 := bls12381.NewG1()
-	result := g1.Zero()
-
-	for i := range bases {
-		temp := g1.New()
-		g1.MulScalar(temp, bases[i], scalars[i])
-		g1.Add(result, result, temp)
-	}
-
-	return result
-}
-
-// ComputeB computes B = h0^s + sum(h_i^m_i) for a given signature scalar s and messages
+// ComputeB computes B = g1 + h0^s + sum(h_i^m_i) for a given signature scalar s and messages.
+// The hidden messages are secrets of the prover, so the constant-time multi-scalar multiplication is used.
 func ComputeB(s *bls12381.Fr, messages []*SignatureMessage, key *fhks_bbs_plus.PublicKey) *bls12381.PointG1 {
-	const basesOffset = 2
-
-	// Create a commitment builder with an expected size (messages + offset)
-	cb := NewCommitmentBuilder(len(messages) + basesOffset)
-
-	// Add generator G1 (assumed to be g1.One() in this case)
-	g1 := bls12381.NewG1()
-	cb.Add(g1.One(), bls12381.NewFr().One()) // Add G^1
-
-	// Add h0^s
-	cb.Add(key.H0, s)
-
-	// Add h_i^m_i for each message
-	for i := 0; i < len(messages); i++ {
-		cb.Add(key.H[i], messages[i].value)
+	bases := append([]*bls12381.PointG1{key.H0}, key.H[:len(messages)]...)
+	scalars := make([]*bls12381.Fr, 0, len(messages)+1)
+	scalars = append(scalars, s)
+	for _, message := range messages {
+		scalars = append(scalars, message.value)
 	}
 
-	// Build the final commitment by performing multi-scalar multiplication
-	return cb.Build()
+	g1 := bls12381.NewG1()
+	b := helper.MultiScalarMulG1(bases, scalars)
+	return g1.Add(b, b, g1.One())
 }
 
 func IsPointZero(point *bls12381.PointG1) bool {
@@ -143,17 +122,23 @@ func uint32FromBytes(bytes []byte) uint32 {
 // nolint:gomnd
 func ParsePoKPayload(bytes []byte) (*PokPayload, error) {
 	if len(bytes) < 2 {
-		return nil, errors.New("invalid size of PoK payload len(bytes) < 2")
+		return nil, fmt.Errorf("%w: invalid size of PoK payload len(bytes) < 2", helper.ErrInvalidEncoding)
 	}
 
 	messagesCount := int(uint16FromBytes(bytes[0:2]))
 	offset := lenInBytes(messagesCount)
 
 	if len(bytes) < offset {
-		return nil, errors.New("invalid size of PoK payload < offset")
+		return nil, fmt.Errorf("%w: invalid size of PoK payload < offset", helper.ErrInvalidEncoding)
 	}
 
-	revealed := bitvectorToIndices(reverseBytes(bytes[2:offset]))
+	// Reverse a copy, the bitvector is part of the proof of the caller.
+	bitvector := append([]byte{}, bytes[2:offset]...)
+	revealed := bitvectorToIndices(reverseBytes(bitvector))
+	if len(revealed) > 0 && revealed[len(revealed)-1] >= messagesCount {
+		return nil, fmt.Errorf("%w: revealed index %d exceeds message count %d", helper.ErrInvalidEncoding,
+			revealed[len(revealed)-1], messagesCount)
+	}
 
 	return &PokPayload{
 		messagesCount: messagesCount,